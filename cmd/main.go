@@ -1,18 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"io/ioutil"
 	"os"
-	"os/signal"
 	"runtime/pprof"
 	"strconv"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/lifecycle"
 
 	"github.com/tagirmukail/tccbot-backend/internal/scheduler"
 
@@ -22,8 +22,7 @@ import (
 
 	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
 	"github.com/tagirmukail/tccbot-backend/internal/db"
-
-	"github.com/tagirmukail/tccbot-backend/internal/strategies/strategy"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
 
 	"github.com/sirupsen/logrus"
 
@@ -33,11 +32,14 @@ import (
 	"github.com/tagirmukail/tccbot-backend/internal/utils/logger"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/record"
 )
 
 const (
 	maxCandles = 100
 	PidEnvKey  = "TCCBOTBACKENDPID"
+	// defaultOrderProcBufferSize queue size of the order processor subscriber if it is not configured
+	defaultOrderProcBufferSize = 10000
 )
 
 //Application version
@@ -47,10 +49,6 @@ var (
 	GitHash   string
 )
 
-// TODO новая арбитражная стратегия - выставлять сразу и на покупку и на продажу при срабатывании сигналов,
-//  и при необходимости перевыставлять,
-//  учитывать при выставлении в какую сторону сработали сигналы, учитывать доступный балан и позицию
-
 //  atr signal/ strategy ( Awesome Oscillator + Accelerator Oscillator + Parabolic SAR)
 
 // TODO попробовать пакет кобра для запуска команд
@@ -66,6 +64,10 @@ func main() { // nolint:funlen
 		initSignals      bool
 		migrationOnly    bool
 		step             int
+		recordDir        string
+		replayPattern    string
+		replaySpeed      float64
+		replayFixtures   string
 	)
 
 	flag.StringVar(&prof, "prof", "", "file name for profiling")
@@ -81,6 +83,12 @@ func main() { // nolint:funlen
 	flag.BoolVar(&testMode, "test", false, "Use exchanges to test mode")
 	flag.StringVar(&logDir, "logdir", "", "logs save directory")
 	flag.BoolVar(&initSignals, "siginit", false, "initialization previous signals.By default disabled")
+	flag.StringVar(&recordDir, "record", "", "directory for recording raw bitmex websocket messages")
+	flag.StringVar(&replayPattern, "replay", "",
+		"glob pattern of recorded bitmex websocket files, replayed instead of connecting to bitmex websocket")
+	flag.Float64Var(&replaySpeed, "replayspeed", 1, "replay speed: 1 - original, 2 - twice as fast, 0 - without delays")
+	flag.StringVar(&replayFixtures, "replayfixtures", "",
+		"json file with instrument, margins, positions and candles served by offline bitmex api in replay mode")
 	flag.Parse()
 
 	err := killIfRun()
@@ -144,6 +152,12 @@ func main() { // nolint:funlen
 	}
 
 	var tradeThemes = cfg.GlobStrategies.GetThemes()
+	var orderProcThemes = []types.Theme{types.Order, types.Execution, types.Margin, types.Wallet, types.Instrument}
+	var privateThemes = append([]types.Theme{types.Position}, orderProcThemes...)
+	var wsThemes = append(privateThemes, tradeThemes...)
+	if cfg.ExchangesSettings.Bitmex.FormingCandles {
+		wsThemes = append(wsThemes, types.Trade)
+	}
 
 	bitmexKey, bitmexSecret := cfg.Accesses.Bitmex.Key, cfg.Accesses.Bitmex.Secret
 	if testMode {
@@ -151,41 +165,105 @@ func main() { // nolint:funlen
 		bitmexSecret = cfg.Accesses.Bitmex.Testnet.Secret
 	}
 
-	tradeAPI := tradeapi.NewTradeAPI(
-		bitmexKey,
-		bitmexSecret,
+	bitmexWS := ws.NewWS(
 		log,
 		testMode,
-		ws.NewWS(
-			log,
-			testMode,
-			cfg.ExchangesSettings.Bitmex.PingSec,
-			cfg.ExchangesSettings.Bitmex.TimeoutSec,
-			uint32(cfg.ExchangesSettings.Bitmex.RetrySec),
-			append([]types.Theme{types.Position}, tradeThemes...),
-			types.Symbol(cfg.ExchangesSettings.Bitmex.Symbol),
+		cfg.ExchangesSettings.Bitmex.PingSec,
+		cfg.ExchangesSettings.Bitmex.TimeoutSec,
+		uint32(cfg.ExchangesSettings.Bitmex.RetrySec),
+		wsThemes,
+		types.Symbol(cfg.ExchangesSettings.Bitmex.Symbol),
+		bitmexKey,
+		bitmexSecret,
+	)
+	if recordDir != "" {
+		recorder, err := record.NewRecorder(recordDir, "bitmex", record.DefaultMaxFileSize, record.DefaultRotateEvery)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bitmexWS.SetRecorder(recorder)
+	}
+	if replayPattern != "" {
+		replayer, err := record.NewReplayer(replayPattern, replaySpeed)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bitmexWS.SetReplayer(replayer)
+	}
+
+	var tradeAPI *tradeapi.TradeAPI
+	if replayPattern != "" {
+		var fixtures tradeapi.Fixtures
+		if replayFixtures != "" {
+			fixtures, err = tradeapi.LoadFixtures(replayFixtures)
+			if err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			log.Warnf("replay fixtures are not set, offline bitmex api has not instrument and balance")
+		}
+		tradeAPI = tradeapi.NewOfflineTradeAPI(fixtures, log, bitmexWS)
+	} else {
+		tradeAPI = tradeapi.NewTradeAPI(
 			bitmexKey,
 			bitmexSecret,
-		),
-	)
+			log,
+			testMode,
+			bitmexWS,
+		)
+	}
 
 	var bitmexSubscribers []*bitmextradedata.Subscriber
 
-	ordProc := orderproc.New(tradeAPI, configurator, log)
+	caches := candlecache.NewRegistry(maxCandles, log)
+	for _, binSize := range cfg.GlobStrategies.GetBinSizes() {
+		bin, err := models.ToBinSize(binSize)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cacheSize := maxCandles
+		if cfg.GlobStrategies.GetCfgByBinSize(binSize).WarmupCount() > cacheSize {
+			cacheSize = cfg.GlobStrategies.GetCfgByBinSize(binSize).WarmupCount()
+		}
+		key := candlecache.NewKey(types.Bitmex, types.Symbol(cfg.ExchangesSettings.Bitmex.Symbol), bin)
+		err = caches.Register(key, cacheSize)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	caches := candlecache.NewBinToCache(
-		cfg.GlobStrategies.GetBinSizes(), maxCandles, types.Symbol(cfg.ExchangesSettings.Bitmex.Symbol), log,
-	)
+	subsBufferSize := cfg.ExchangesSettings.Bitmex.BufferSize
+	subsPolicy := bitmextradedata.ToOverflowPolicy(cfg.ExchangesSettings.Bitmex.OverflowPolicy)
 
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
+	// live orders, margin, wallet and instrument state is built from deltas, so order processor messages
+	// are never dropped, its queue spills over the size instead of blocking other subscribers
+	orderProcBufferSize := cfg.ExchangesSettings.Bitmex.OrderProcBufferSize
+	if orderProcBufferSize <= 0 {
+		orderProcBufferSize = defaultOrderProcBufferSize
+	}
+	bitmexSubsForOrderProc := bitmextradedata.NewSubscriber(
+		"order_processor", orderProcThemes, orderProcBufferSize, bitmextradedata.Spill,
+	)
+	bitmexSubscribers = append(bitmexSubscribers, bitmexSubsForOrderProc)
+	ordProc := orderproc.New(tradeAPI, configurator, bitmexSubsForOrderProc, log)
 
-	bitmexSubsTradeForStrategies := bitmextradedata.NewSubscriber(tradeThemes)
+	bitmexSubsTradeForStrategies := bitmextradedata.NewSubscriber("strategies", tradeThemes, subsBufferSize, subsPolicy)
 	bitmexSubscribers = append(bitmexSubscribers, bitmexSubsTradeForStrategies)
 
+	var formingBuilder *candlecache.FormingBuilder
+	if cfg.ExchangesSettings.Bitmex.FormingCandles {
+		bitmexSubsForForming := bitmextradedata.NewSubscriber(
+			"forming_candles", []types.Theme{types.Trade}, subsBufferSize, subsPolicy,
+		)
+		bitmexSubscribers = append(bitmexSubscribers, bitmexSubsForForming)
+		formingBuilder = candlecache.NewFormingBuilder(types.Bitmex, caches, bitmexSubsForForming.GetMsgChan(), log)
+	}
+
 	var schedulr scheduler.Scheduler
 	if cfg.Scheduler.Position.Enable {
-		bitmexSubsForScheduler := bitmextradedata.NewSubscriber([]types.Theme{types.Position})
+		bitmexSubsForScheduler := bitmextradedata.NewSubscriber(
+			"position_scheduler", []types.Theme{types.Position}, subsBufferSize, subsPolicy,
+		)
 		bitmexSubscribers = append(bitmexSubscribers, bitmexSubsForScheduler)
 		schedulr = scheduler.NewPositionScheduler(
 			configurator, scheduler.LimitPositionPnls, tradeAPI, ordProc, bitmexSubsForScheduler, log,
@@ -194,15 +272,22 @@ func main() { // nolint:funlen
 
 	bitmexDataSender := bitmextradedata.New(tradeAPI.GetBitmex().GetWS().GetMessages(), log, bitmexSubscribers...)
 
-	bbRsi := strategy.NewBBRSIStrategy(configurator, tradeAPI, ordProc, dbManager, caches, log)
-
-	wg := &sync.WaitGroup{}
-	strategiesTypes := strategies.New(wg, configurator, tradeAPI, ordProc, bitmexDataSender, bitmexSubsTradeForStrategies,
-		schedulr, dbManager, log, initSignals, bbRsi, caches)
-	strategiesTypes.Start()
-	<-done
+	lc := lifecycle.New(log, time.Duration(cfg.ShutdownTimeoutSec)*time.Second)
+	strategiesTypes := strategies.New(configurator, tradeAPI, ordProc, bitmexDataSender, bitmexSubsTradeForStrategies,
+		schedulr, dbManager, log, initSignals, caches)
+	strategiesTypes.Start(lc)
+	if formingBuilder != nil {
+		lc.Go("forming_candles", formingBuilder.Start)
+	}
+	lc.OnStop("db", func(ctx context.Context) error {
+		return dbManager.Close()
+	})
 
-	dbManager.Close()
+	err = lc.Wait()
+	if err != nil {
+		log.Errorf("service tccbot stopped with error: %v", err)
+		return
+	}
 	log.Infof("service tccbot stopped")
 }
 
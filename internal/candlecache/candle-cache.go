@@ -2,6 +2,7 @@ package candlecache
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"sync"
 	"time"
@@ -16,166 +17,254 @@ import (
 )
 
 type Caches interface {
-	GetCache(size models.BinSize) Cache
+	GetCache(key Key) (Cache, error)
+	Load(key Key, load LoadFunc) error
 }
 
 type Cache interface {
 	StoreBatch(batch []bitmex.TradeBuck)
-	Store(candle data.BitmexIncomingData) error
+	Store(candle data.TradeBin) error
 	GetBucketed(from, to time.Time, count int) []bitmex.TradeBuck
 	Count() int
+	Validate() error
+	UpdateForming(trade data.Trade) error
+	GetForming() (FormingCandle, bool)
+	GetVolumeDeltas(count int) []VolumeDelta
 }
 
-type BinToCache struct {
-	sync.Mutex
-	caches map[string]Cache
-}
-
-func NewBinToCache(binSizes []string, maxCount int, symbol types.Symbol, log *logrus.Logger) *BinToCache {
-	binToCache := &BinToCache{
-		Mutex:  sync.Mutex{},
-		caches: make(map[string]Cache),
-	}
-	for _, bin := range binSizes {
-		binToCache.caches[bin] = NewCandleCache(maxCount, symbol, log)
-	}
-
-	return binToCache
-}
-
-func (bc *BinToCache) GetCache(size models.BinSize) Cache {
-	return bc.caches[size.String()]
-}
-
+// CandleCache keeps last candles ordered by timestamp, one candle per timestamp.
+// Candles are stored in a fixed-size ring buffer with parsed timestamps,
+// the oldest candle is overwritten when the buffer is full.
+// Candle of the bin in progress is kept separately until the bin is closed
 type CandleCache struct {
 	sync.Mutex
-	store    []bitmex.TradeBuck
+	ring     []candleEntry
+	start    int // index of the oldest candle in the ring
+	size     int
+	binSize  models.BinSize
 	maxCount int
 	symbol   types.Symbol
 	log      *logrus.Logger
+	forming  *forming
+	// deltas volume deltas of closed bins built from trades, ordered by timestamp
+	deltas     []VolumeDelta
+	firstTrade time.Time
 }
 
-func NewCandleCache(maxCount int, symbol types.Symbol, log *logrus.Logger) *CandleCache {
+type candleEntry struct {
+	ts     time.Time
+	candle bitmex.TradeBuck
+}
+
+func NewCandleCache(binSize models.BinSize, maxCount int, symbol types.Symbol, log *logrus.Logger) *CandleCache {
 	return &CandleCache{
 		Mutex:    sync.Mutex{},
-		store:    make([]bitmex.TradeBuck, 0, maxCount),
+		ring:     make([]candleEntry, maxCount),
+		binSize:  binSize,
 		maxCount: maxCount,
 		symbol:   symbol,
 		log:      log,
 	}
 }
 
+// StoreBatch stores candles, candle with already stored timestamp replaces the stored one
 func (c *CandleCache) StoreBatch(batch []bitmex.TradeBuck) {
 	c.Lock()
+	defer c.Unlock()
 	for _, elem := range batch {
 		if elem.Symbol != string(c.symbol) {
 			continue
 		}
-		c.store = append(c.store, elem)
-	}
-	c.sort()
-	if len(c.store) > c.maxCount {
-		c.store = c.store[len(c.store)-c.maxCount:]
+		err := c.upsert(elem)
+		if err != nil {
+			c.log.Warnf("candle not stored: %v", err)
+		}
 	}
-	c.Unlock()
 }
-func (c *CandleCache) Store(candle data.BitmexIncomingData) error {
+
+// Store stores candle received from websocket, a newer version of the candle replaces the stored one
+func (c *CandleCache) Store(candle data.TradeBin) error {
 	c.Lock()
 	defer c.Unlock()
 	if candle.Symbol != c.symbol {
 		return errors.New("candle not saved")
 	}
-	c.store = append(c.store, bitmex.TradeBuck{
-		Symbol:          string(candle.Symbol),
-		Timestamp:       candle.Timestamp,
-		HomeNotional:    candle.HomeNotional,
-		ForeignNotional: candle.ForeignNotional,
-		Open:            candle.Open,
-		High:            candle.High,
-		Low:             candle.Low,
-		Close:           candle.Close,
-		Trades:          candle.Trades,
-		Volume:          candle.Volume,
-		LastSize:        candle.LastSize,
-		Turnover:        candle.Turnover,
-		Vwap:            candle.Vwap,
-	})
-	c.sort()
-	if len(c.store) > c.maxCount {
-		c.store = c.store[len(c.store)-c.maxCount:]
-	}
-	return nil
+	return c.upsert(ToTradeBuck(candle))
 }
 
-func (c *CandleCache) GetBucketed(from, to time.Time, count int) []bitmex.TradeBuck { // nolint:gocognit
-	var result []bitmex.TradeBuck
+// GetBucketed returns candles with timestamps in [from, to], zero from or to is not limited.
+// count limits result by last candles, if nothing found by range the last count candles are returned
+func (c *CandleCache) GetBucketed(from, to time.Time, count int) []bitmex.TradeBuck {
 	if count > c.maxCount {
 		count = c.maxCount
 	}
-	fromToNotZero := !from.IsZero() && !to.IsZero()
-	fromZero := from.IsZero()
-	toZero := to.IsZero()
 
 	c.Lock()
 	defer c.Unlock()
 
-	c.sort()
-	for _, candle := range c.store {
-		candleTS, err := time.Parse(
-			tradeapi.TradeBucketedTimestampLayout,
-			candle.Timestamp,
-		)
-		if err != nil {
-			c.log.Errorf("candle timestamp fail: %v", err)
-			return nil
-		}
-		if fromToNotZero {
-			if (candleTS.After(from) && candleTS.Before(to)) || candleTS.Equal(from) || candleTS.Equal(to) {
-				result = append(result, candle)
-			}
-			continue
-		} else {
-			if (!fromZero && candleTS.After(from)) || (!fromZero && candleTS.Equal(from)) {
-				result = append(result, candle)
-				continue
-			} else if (!toZero && candleTS.Before(to)) || (!toZero && candleTS.Equal(to)) {
-				result = append(result, candle)
-				continue
-			}
+	var first, last = 0, c.size
+	if !from.IsZero() {
+		first = c.search(from)
+	}
+	if !to.IsZero() {
+		last = c.search(to.Add(time.Nanosecond))
+	}
+	if first > last {
+		first = last
+	}
+
+	if count != 0 && last-first > count {
+		first = last - count
+	} else if first == last && count != 0 {
+		first, last = c.size-count, c.size
+		if first < 0 {
+			first = 0
 		}
 	}
-	if count != 0 && len(result) > count {
-		result = result[len(result)-count:]
-	} else if len(result) == 0 && count != 0 {
-		result = c.store[len(c.store)-count:]
+	if first == last {
+		return nil
 	}
 
+	result := make([]bitmex.TradeBuck, 0, last-first)
+	for i := first; i < last; i++ {
+		result = append(result, c.at(i).candle)
+	}
 	return result
 }
 
 func (c *CandleCache) Count() int {
 	c.Lock()
 	defer c.Unlock()
-	return len(c.store)
+	return c.size
 }
 
-func (c *CandleCache) sort() {
-	sort.SliceStable(c.store, func(i, j int) bool {
-		timestamp1, err := time.Parse(
-			tradeapi.TradeBucketedTimestampLayout,
-			c.store[i].Timestamp,
-		)
-		if err != nil {
-			return false
+// Validate checks that candles are in ascending order without duplicates
+// and neighboring candles are spaced by the bin size
+func (c *CandleCache) Validate() error {
+	c.Lock()
+	defer c.Unlock()
+
+	for i := 1; i < c.size; i++ {
+		prev, entry := c.at(i-1), c.at(i)
+		switch {
+		case entry.ts.Equal(prev.ts):
+			return fmt.Errorf("duplicate candle timestamp: %s", entry.candle.Timestamp)
+		case entry.ts.Before(prev.ts):
+			return fmt.Errorf("candle timestamp %s is before previous %s", entry.candle.Timestamp, prev.candle.Timestamp)
+		case c.binSize.Duration() != 0 && entry.ts.Sub(prev.ts) != c.binSize.Duration():
+			return fmt.Errorf("candle timestamp %s spaced by %v from previous, expected %v",
+				entry.candle.Timestamp, entry.ts.Sub(prev.ts), c.binSize.Duration())
 		}
-		timestamp2, err := time.Parse(
-			tradeapi.TradeBucketedTimestampLayout,
-			c.store[j].Timestamp,
-		)
-		if err != nil {
-			return false
+	}
+	return nil
+}
+
+// upsert replaces stored candle with the same timestamp or inserts candle keeping the order,
+// candle older than all stored candles is skipped when the cache is full. Must be called under lock
+func (c *CandleCache) upsert(candle bitmex.TradeBuck) error {
+	ts, err := parseTimestamp(candle.Timestamp)
+	if err != nil {
+		return err
+	}
+	if c.maxCount <= 0 {
+		return nil
+	}
+
+	c.closeForming(ts)
+
+	pos := c.search(ts)
+	if pos < c.size && c.at(pos).ts.Equal(ts) {
+		c.ring[c.index(pos)].candle = candle
+		return nil
+	}
+
+	if c.size == c.maxCount {
+		if pos == 0 {
+			return nil
 		}
+		c.start = c.index(1)
+		c.size--
+		pos--
+	}
 
-		return timestamp1.Before(timestamp2)
+	for i := c.size; i > pos; i-- {
+		c.ring[c.index(i)] = c.ring[c.index(i-1)]
+	}
+	c.ring[c.index(pos)] = candleEntry{ts: ts, candle: candle}
+	c.size++
+	return nil
+}
+
+// search returns position of the first candle with timestamp not before ts
+func (c *CandleCache) search(ts time.Time) int {
+	return sort.Search(c.size, func(i int) bool {
+		return !c.at(i).ts.Before(ts)
 	})
 }
+
+// at returns candle entry by position from the oldest one
+func (c *CandleCache) at(pos int) candleEntry {
+	return c.ring[c.index(pos)]
+}
+
+func (c *CandleCache) index(pos int) int {
+	return (c.start + pos) % c.maxCount
+}
+
+// candles returns stored candles from the oldest one
+func (c *CandleCache) candles() []bitmex.TradeBuck {
+	result := make([]bitmex.TradeBuck, 0, c.size)
+	for i := 0; i < c.size; i++ {
+		result = append(result, c.at(i).candle)
+	}
+	return result
+}
+
+func parseTimestamp(timestamp string) (time.Time, error) {
+	ts, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, timestamp)
+	if err != nil {
+		return ts, fmt.Errorf("candle timestamp %q parse failed: %v", timestamp, err)
+	}
+	return ts, nil
+}
+
+// ToTradeBuck converts candle received from websocket to rest api candle
+func ToTradeBuck(candle data.TradeBin) bitmex.TradeBuck {
+	return bitmex.TradeBuck{
+		Symbol:          string(candle.Symbol),
+		Timestamp:       candle.Timestamp,
+		HomeNotional:    candle.HomeNotional,
+		ForeignNotional: candle.ForeignNotional,
+		Open:            candle.Open,
+		High:            candle.High,
+		Low:             candle.Low,
+		Close:           candle.Close,
+		Trades:          candle.Trades,
+		Volume:          candle.Volume,
+		LastSize:        candle.LastSize,
+		Turnover:        candle.Turnover,
+		Vwap:            candle.Vwap,
+	}
+}
+
+// ContiguousTail returns the last ordered candles spaced by the bin size, candles before a gap are dropped
+func ContiguousTail(candles []bitmex.TradeBuck, binSize models.BinSize) ([]bitmex.TradeBuck, error) {
+	if len(candles) == 0 {
+		return candles, nil
+	}
+	next, err := parseTimestamp(candles[len(candles)-1].Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(candles) - 2; i >= 0; i-- {
+		ts, err := parseTimestamp(candles[i].Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		if next.Sub(ts) != binSize.Duration() {
+			return candles[i+1:], nil
+		}
+		next = ts
+	}
+	return candles, nil
+}
@@ -0,0 +1,107 @@
+package candlecache
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+const benchCandles = 500
+
+var benchStart = time.Date(2020, 5, 16, 10, 0, 0, 0, time.UTC)
+
+// sliceCache is the previous slice based implementation,
+// timestamps are parsed in the sort comparator and on every range query
+type sliceCache struct {
+	store    []bitmex.TradeBuck
+	maxCount int
+}
+
+func (c *sliceCache) Store(candle bitmex.TradeBuck) {
+	c.store = append(c.store, candle)
+	c.sort()
+	if len(c.store) > c.maxCount {
+		c.store = c.store[len(c.store)-c.maxCount:]
+	}
+}
+
+func (c *sliceCache) GetBucketed(from time.Time, count int) []bitmex.TradeBuck {
+	var result []bitmex.TradeBuck
+	c.sort()
+	for _, candle := range c.store {
+		candleTS, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candle.Timestamp)
+		if err != nil {
+			return nil
+		}
+		if !candleTS.Before(from) {
+			result = append(result, candle)
+		}
+	}
+	if count != 0 && len(result) > count {
+		result = result[len(result)-count:]
+	}
+	return result
+}
+
+func (c *sliceCache) sort() {
+	sort.SliceStable(c.store, func(i, j int) bool {
+		timestamp1, _ := time.Parse(tradeapi.TradeBucketedTimestampLayout, c.store[i].Timestamp)
+		timestamp2, _ := time.Parse(tradeapi.TradeBucketedTimestampLayout, c.store[j].Timestamp)
+		return timestamp1.Before(timestamp2)
+	})
+}
+
+func benchCandle(i int) bitmex.TradeBuck {
+	return bitmex.TradeBuck{
+		Symbol:    string(types.XBTUSD),
+		Close:     float64(i),
+		Timestamp: benchStart.Add(time.Duration(i) * time.Minute).Format(tradeapi.TradeBucketedTimestampLayout),
+	}
+}
+
+func BenchmarkCandleCache_Store(b *testing.B) {
+	c := NewCandleCache(models.Bin1m, benchCandles, types.XBTUSD, logrus.New())
+	for i := 0; i < b.N; i++ {
+		candle := benchCandle(i)
+		_ = c.Store(data.TradeBin{Symbol: types.XBTUSD, Timestamp: candle.Timestamp, Close: candle.Close})
+	}
+}
+
+func BenchmarkSliceCache_Store(b *testing.B) {
+	c := &sliceCache{maxCount: benchCandles}
+	for i := 0; i < b.N; i++ {
+		c.Store(benchCandle(i))
+	}
+}
+
+func BenchmarkCandleCache_GetBucketed(b *testing.B) {
+	c := NewCandleCache(models.Bin1m, benchCandles, types.XBTUSD, logrus.New())
+	for i := 0; i < benchCandles; i++ {
+		c.StoreBatch([]bitmex.TradeBuck{benchCandle(i)})
+	}
+	from := benchStart.Add((benchCandles - 100) * time.Minute)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = c.GetBucketed(from, time.Time{}, 50)
+	}
+}
+
+func BenchmarkSliceCache_GetBucketed(b *testing.B) {
+	c := &sliceCache{maxCount: benchCandles}
+	for i := 0; i < benchCandles; i++ {
+		c.Store(benchCandle(i))
+	}
+	from := benchStart.Add((benchCandles - 100) * time.Minute)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = c.GetBucketed(from, 50)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
 )
@@ -105,10 +107,10 @@ func TestCandleCache_StoreBatch(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			c := NewCandleCache(tt.fields.maxCount, tt.fields.symbol, tt.fields.log)
+			c := NewCandleCache(models.Bin5m, tt.fields.maxCount, tt.fields.symbol, tt.fields.log)
 			c.StoreBatch(tt.args.batch)
-			require.Equal(t, tt.fields.maxCount, len(c.store), "batch not stored")
-			require.Equal(t, tt.wantStore, c.store)
+			require.Equal(t, tt.fields.maxCount, c.Count(), "batch not stored")
+			require.Equal(t, tt.wantStore, c.candles())
 		})
 	}
 }
@@ -121,7 +123,7 @@ func TestCandleCache_Store(t *testing.T) {
 		log      *logrus.Logger
 	}
 	type args struct {
-		candle data.BitmexIncomingData
+		candle data.TradeBin
 	}
 	tests := []struct {
 		name      string
@@ -165,11 +167,9 @@ func TestCandleCache_Store(t *testing.T) {
 				log:    nil,
 			},
 			args: args{
-				candle: data.BitmexIncomingData{
-					Symbol: types.XBTUSD,
-					TradeBinData: data.TradeBinData{
-						Close: 456,
-					},
+				candle: data.TradeBin{
+					Symbol:    types.XBTUSD,
+					Close:     456,
 					Timestamp: "2020-05-16T10:45:00.000Z",
 				},
 			},
@@ -216,11 +216,9 @@ func TestCandleCache_Store(t *testing.T) {
 				log:      nil,
 			},
 			args: args{
-				candle: data.BitmexIncomingData{
-					Symbol: types.Symbol("BTC"),
-					TradeBinData: data.TradeBinData{
-						Close: 456,
-					},
+				candle: data.TradeBin{
+					Symbol:    types.Symbol("BTC"),
+					Close:     456,
 					Timestamp: "2020-05-16T10:45:00.000Z",
 				},
 			},
@@ -231,11 +229,11 @@ func TestCandleCache_Store(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			c := NewCandleCache(tt.fields.maxCount, tt.fields.symbol, tt.fields.log)
-			c.store = tt.fields.store
+			c := NewCandleCache(models.Bin5m, tt.fields.maxCount, tt.fields.symbol, tt.fields.log)
+			c.StoreBatch(tt.fields.store)
 			err := c.Store(tt.args.candle)
 			require.Equal(t, tt.wantErr, err != nil, "error", err)
-			require.Equal(t, tt.wantStore, c.store)
+			require.Equal(t, tt.wantStore, c.candles())
 		})
 	}
 }
@@ -375,7 +373,7 @@ func TestCandleCache_GetBucketed(t *testing.T) {
 						Timestamp: "2020-05-16T10:25:00.000Z",
 					},
 				},
-				maxCount: 5,
+				maxCount: 7,
 				symbol:   types.XBTUSD,
 				log:      logrus.New(),
 			},
@@ -460,7 +458,7 @@ func TestCandleCache_GetBucketed(t *testing.T) {
 						Timestamp: "2020-05-16T10:25:00.000Z",
 					},
 				},
-				maxCount: 5,
+				maxCount: 7,
 				symbol:   types.XBTUSD,
 				log:      logrus.New(),
 			},
@@ -574,11 +572,169 @@ func TestCandleCache_GetBucketed(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			c := NewCandleCache(tt.fields.maxCount, tt.fields.symbol, tt.fields.log)
-			c.store = tt.fields.store
+			c := NewCandleCache(models.Bin5m, tt.fields.maxCount, tt.fields.symbol, tt.fields.log)
+			c.StoreBatch(tt.fields.store)
 			got := c.GetBucketed(tt.args.from, tt.args.to, tt.args.count)
 			require.Equal(t, len(tt.want), len(got))
 			require.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestCandleCache_upsert(t *testing.T) {
+	c := NewCandleCache(models.Bin5m, 5, types.XBTUSD, logrus.New())
+	c.StoreBatch([]bitmex.TradeBuck{
+		{Symbol: string(types.XBTUSD), Close: 100, Timestamp: "2020-05-16T10:20:00.000Z"},
+		{Symbol: string(types.XBTUSD), Close: 101, Timestamp: "2020-05-16T10:25:00.000Z"},
+	})
+	c.StoreBatch([]bitmex.TradeBuck{
+		{Symbol: string(types.XBTUSD), Close: 102, Timestamp: "2020-05-16T10:25:00.000Z"},
+		{Symbol: string(types.XBTUSD), Close: 103, Timestamp: "2020-05-16T10:30:00.000Z"},
+	})
+	err := c.Store(data.TradeBin{Symbol: types.XBTUSD, Close: 104, Timestamp: "2020-05-16T10:30:00.000Z"})
+	require.NoError(t, err)
+	err = c.Store(data.TradeBin{Symbol: types.XBTUSD, Close: 105, Timestamp: "not a time"})
+	require.Error(t, err)
+
+	require.NoError(t, c.Validate())
+	var closes []float64
+	for _, candle := range c.candles() {
+		closes = append(closes, candle.Close)
+	}
+	require.Equal(t, []float64{100, 102, 104}, closes)
+}
+
+func TestCandleCache_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		timestamps []string
+		wantErr    bool
+	}{
+		{
+			name:       "ok",
+			timestamps: []string{"2020-05-16T10:20:00.000Z", "2020-05-16T10:25:00.000Z", "2020-05-16T10:30:00.000Z"},
+		},
+		{
+			name:       "empty",
+			timestamps: nil,
+		},
+		{
+			name:       "duplicate",
+			timestamps: []string{"2020-05-16T10:20:00.000Z", "2020-05-16T10:20:00.000Z"},
+			wantErr:    true,
+		},
+		{
+			name:       "not monotonic",
+			timestamps: []string{"2020-05-16T10:25:00.000Z", "2020-05-16T10:20:00.000Z"},
+			wantErr:    true,
+		},
+		{
+			name:       "gap",
+			timestamps: []string{"2020-05-16T10:20:00.000Z", "2020-05-16T10:30:00.000Z"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCandleCache(models.Bin5m, 5, types.XBTUSD, logrus.New())
+			for i, timestamp := range tt.timestamps {
+				ts, err := parseTimestamp(timestamp)
+				require.NoError(t, err)
+				c.ring[i] = candleEntry{ts: ts, candle: bitmex.TradeBuck{Timestamp: timestamp}}
+				c.size++
+			}
+			err := c.Validate()
+			require.Equal(t, tt.wantErr, err != nil, "error", err)
+		})
+	}
+}
+
+func TestCandleCache_ring(t *testing.T) {
+	c := NewCandleCache(models.Bin1m, 3, types.XBTUSD, logrus.New())
+	start := time.Date(2020, 5, 16, 10, 0, 0, 0, time.UTC)
+	candleAt := func(i int) bitmex.TradeBuck {
+		return bitmex.TradeBuck{
+			Symbol:    string(types.XBTUSD),
+			Close:     float64(i),
+			Timestamp: start.Add(time.Duration(i) * time.Minute).Format(tradeapi.TradeBucketedTimestampLayout),
+		}
+	}
+
+	c.StoreBatch([]bitmex.TradeBuck{candleAt(3), candleAt(1), candleAt(5), candleAt(4), candleAt(2)})
+	require.Equal(t, []bitmex.TradeBuck{candleAt(3), candleAt(4), candleAt(5)}, c.candles())
+	require.NoError(t, c.Validate())
+
+	c.StoreBatch([]bitmex.TradeBuck{candleAt(0)})
+	require.Equal(t, []bitmex.TradeBuck{candleAt(3), candleAt(4), candleAt(5)}, c.candles(), "older candle skipped")
+
+	c.StoreBatch([]bitmex.TradeBuck{candleAt(6), candleAt(7)})
+	require.Equal(t, []bitmex.TradeBuck{candleAt(5), candleAt(6), candleAt(7)}, c.candles())
+	require.Equal(t, []bitmex.TradeBuck{candleAt(6)},
+		c.GetBucketed(start.Add(6*time.Minute), start.Add(6*time.Minute), 0))
+	require.Equal(t, []bitmex.TradeBuck{candleAt(6), candleAt(7)}, c.GetBucketed(time.Time{}, time.Time{}, 2))
+	require.Equal(t, []bitmex.TradeBuck{candleAt(5), candleAt(6), candleAt(7)},
+		c.GetBucketed(start.Add(time.Hour), time.Time{}, 5), "last candles if nothing found")
+}
+
+func TestContiguousTail(t *testing.T) {
+	candle := func(ts string) bitmex.TradeBuck {
+		return bitmex.TradeBuck{Symbol: string(types.XBTUSD), Timestamp: ts}
+	}
+	tests := []struct {
+		name    string
+		candles []bitmex.TradeBuck
+		want    []bitmex.TradeBuck
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "without gaps",
+			candles: []bitmex.TradeBuck{
+				candle("2020-05-16T10:05:00.000Z"),
+				candle("2020-05-16T10:10:00.000Z"),
+				candle("2020-05-16T10:15:00.000Z"),
+			},
+			want: []bitmex.TradeBuck{
+				candle("2020-05-16T10:05:00.000Z"),
+				candle("2020-05-16T10:10:00.000Z"),
+				candle("2020-05-16T10:15:00.000Z"),
+			},
+		},
+		{
+			name: "candles before gap dropped",
+			candles: []bitmex.TradeBuck{
+				candle("2020-05-16T09:50:00.000Z"),
+				candle("2020-05-16T09:55:00.000Z"),
+				candle("2020-05-16T10:10:00.000Z"),
+				candle("2020-05-16T10:15:00.000Z"),
+			},
+			want: []bitmex.TradeBuck{
+				candle("2020-05-16T10:10:00.000Z"),
+				candle("2020-05-16T10:15:00.000Z"),
+			},
+		},
+		{
+			name: "wrong timestamp",
+			candles: []bitmex.TradeBuck{
+				candle("2020-05-16 10:10"),
+				candle("2020-05-16T10:15:00.000Z"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ContiguousTail(tt.candles, models.Bin5m)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package candlecache
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+// FormingCandle candle of the bin in progress built from trades, it is changed by every trade until the bin is closed.
+// As for closed candles, timestamp is the bin close time
+type FormingCandle struct {
+	bitmex.TradeBuck
+	Closed     bool      // false, the candle is not closed and must not be used as a closed candle
+	UpdatedAt  time.Time // time of the last trade
+	BuyVolume  int64     // volume of trades with buy taker side
+	SellVolume int64     // volume of trades with sell taker side
+}
+
+// forming in-progress candle state of the cache
+type forming struct {
+	candle   FormingCandle
+	end      time.Time
+	notional float64 // sum of price * size for vwap
+}
+
+// UpdateForming adds trade to the forming candle, trade of the next bin starts a new forming candle.
+// Trades of already closed bins are skipped
+func (c *CandleCache) UpdateForming(trade data.Trade) error {
+	c.Lock()
+	defer c.Unlock()
+	if trade.Symbol != c.symbol || c.binSize.Duration() == 0 {
+		return nil
+	}
+	ts, err := parseTimestamp(trade.Timestamp)
+	if err != nil {
+		return err
+	}
+	end := ts.Truncate(c.binSize.Duration()).Add(c.binSize.Duration())
+	if c.size != 0 && !end.After(c.at(c.size-1).ts) {
+		return nil
+	}
+	if c.firstTrade.IsZero() {
+		c.firstTrade = ts
+	}
+
+	switch {
+	case c.forming == nil || end.After(c.forming.end):
+		c.closeDelta()
+		c.forming = &forming{
+			end: end,
+			candle: FormingCandle{
+				TradeBuck: bitmex.TradeBuck{
+					Symbol:    string(trade.Symbol),
+					Timestamp: end.Format(tradeapi.TradeBucketedTimestampLayout),
+					Open:      trade.Price,
+					High:      trade.Price,
+					Low:       trade.Price,
+				},
+			},
+		}
+	case end.Before(c.forming.end):
+		return nil
+	}
+
+	candle := &c.forming.candle
+	if trade.Price > candle.High {
+		candle.High = trade.Price
+	}
+	if trade.Price < candle.Low {
+		candle.Low = trade.Price
+	}
+	candle.Close = trade.Price
+	candle.Trades++
+	candle.Volume += int64(trade.Size)
+	candle.LastSize = trade.Size
+	switch trade.Side {
+	case types.SideBuy:
+		candle.BuyVolume += int64(trade.Size)
+	case types.SideSell:
+		candle.SellVolume += int64(trade.Size)
+	}
+	candle.Turnover += trade.GrossValue
+	candle.HomeNotional += trade.HomeNotional
+	candle.ForeignNotional += trade.ForeignNotional
+	c.forming.notional += trade.Price * float64(trade.Size)
+	if candle.Volume != 0 {
+		candle.Vwap = c.forming.notional / float64(candle.Volume)
+	}
+	if ts.After(candle.UpdatedAt) {
+		candle.UpdatedAt = ts
+	}
+	return nil
+}
+
+// GetForming returns candle of the bin in progress, false if there were no trades after the last closed candle
+func (c *CandleCache) GetForming() (FormingCandle, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if c.forming == nil {
+		return FormingCandle{}, false
+	}
+	return c.forming.candle, true
+}
+
+// closeForming drops forming candle closed by the stored candle. Must be called under lock
+func (c *CandleCache) closeForming(ts time.Time) {
+	if c.forming != nil && !ts.Before(c.forming.end) {
+		c.closeDelta()
+		c.forming = nil
+	}
+}
+
+// UpdateForming is not supported, forming candle is built from the source cache
+func (c *ResampledCache) UpdateForming(trade data.Trade) error {
+	return nil
+}
+
+// GetForming returns candle of the bin in progress built from closed candles of the bin
+// and forming candle of the source cache
+func (c *ResampledCache) GetForming() (FormingCandle, bool) {
+	sourceForming, ok := c.source.GetForming()
+	if !ok {
+		return FormingCandle{}, false
+	}
+	ts, err := parseTimestamp(sourceForming.Timestamp)
+	if err != nil {
+		return FormingCandle{}, false
+	}
+	end := CloseTime(ts, c.binSize)
+	sourceDuration := c.binSize.Source().Duration()
+
+	candles := c.source.GetBucketed(end.Add(-c.binSize.Duration()+sourceDuration), ts.Add(-sourceDuration), 0)
+	candles = append(candles, sourceForming.TradeBuck)
+	result := FormingCandle{
+		TradeBuck:  aggregate(candles, end),
+		UpdatedAt:  sourceForming.UpdatedAt,
+		BuyVolume:  sourceForming.BuyVolume,
+		SellVolume: sourceForming.SellVolume,
+	}
+	for _, delta := range c.source.GetVolumeDeltas(int(c.binSize.Duration() / sourceDuration)) {
+		if delta.ts.After(end.Add(-c.binSize.Duration())) && delta.ts.Before(ts) {
+			result.BuyVolume += delta.Buy
+			result.SellVolume += delta.Sell
+		}
+	}
+	return result, true
+}
+
+// FormingBuilder builds forming candles of the exchange caches from the trade stream
+type FormingBuilder struct {
+	exchange types.Exchange
+	caches   *Registry
+	messages chan data.Event
+	log      *logrus.Logger
+}
+
+func NewFormingBuilder(
+	exchange types.Exchange, caches *Registry, messages chan data.Event, log *logrus.Logger,
+) *FormingBuilder {
+	return &FormingBuilder{
+		exchange: exchange,
+		caches:   caches,
+		messages: messages,
+		log:      log,
+	}
+}
+
+func (b *FormingBuilder) Start(ctx context.Context) {
+	b.log.Infof("forming candles builder started")
+	for {
+		select {
+		case <-ctx.Done():
+			b.log.Infof("forming candles builder stopped")
+			return
+		case event := <-b.messages:
+			trades, ok := event.(*data.TradeEvent)
+			if !ok {
+				b.log.Warnf("forming candles builder is not supported this table: %v", event.GetTable())
+				continue
+			}
+			for _, trade := range trades.Data {
+				err := b.caches.UpdateForming(b.exchange, trade)
+				if err != nil {
+					b.log.Warnf("update forming candle failed: %v", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package candlecache
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+func formingTrade(ts string, price float64, size int) data.Trade {
+	return data.Trade{
+		Symbol:     types.XBTUSD,
+		Timestamp:  ts,
+		Price:      price,
+		Size:       size,
+		GrossValue: int64(size) * 100,
+	}
+}
+
+func TestCandleCache_UpdateForming(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored []bitmex.TradeBuck
+		trades []data.Trade
+		want   bitmex.TradeBuck
+		wantOk bool
+	}{
+		{
+			name: "no trades",
+		},
+		{
+			name: "trades of one bin",
+			trades: []data.Trade{
+				formingTrade("2020-05-16T10:05:00.000Z", 10, 1),
+				formingTrade("2020-05-16T10:06:10.500Z", 14, 2),
+				formingTrade("2020-05-16T10:08:00.000Z", 8, 1),
+				formingTrade("2020-05-16T10:09:59.999Z", 12, 4),
+			},
+			want: bitmex.TradeBuck{
+				Symbol:    string(types.XBTUSD),
+				Timestamp: "2020-05-16T10:10:00.000Z",
+				Open:      10,
+				High:      14,
+				Low:       8,
+				Close:     12,
+				Trades:    4,
+				Volume:    8,
+				LastSize:  4,
+				Turnover:  800,
+				Vwap:      11.75,
+			},
+			wantOk: true,
+		},
+		{
+			name: "trade of the next bin starts new candle",
+			trades: []data.Trade{
+				formingTrade("2020-05-16T10:09:00.000Z", 10, 1),
+				formingTrade("2020-05-16T10:10:00.000Z", 11, 2),
+				formingTrade("2020-05-16T10:09:59.000Z", 9, 1),
+			},
+			want: bitmex.TradeBuck{
+				Symbol:    string(types.XBTUSD),
+				Timestamp: "2020-05-16T10:15:00.000Z",
+				Open:      11,
+				High:      11,
+				Low:       11,
+				Close:     11,
+				Trades:    1,
+				Volume:    2,
+				LastSize:  2,
+				Turnover:  200,
+				Vwap:      11,
+			},
+			wantOk: true,
+		},
+		{
+			name: "trades of closed bin skipped",
+			stored: []bitmex.TradeBuck{
+				{Symbol: string(types.XBTUSD), Timestamp: "2020-05-16T10:10:00.000Z", Close: 10},
+			},
+			trades: []data.Trade{
+				formingTrade("2020-05-16T10:09:00.000Z", 10, 1),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCandleCache(models.Bin5m, 10, types.XBTUSD, logrus.New())
+			c.StoreBatch(tt.stored)
+			for _, trade := range tt.trades {
+				require.NoError(t, c.UpdateForming(trade))
+			}
+			got, ok := c.GetForming()
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got.TradeBuck)
+			require.False(t, got.Closed)
+		})
+	}
+}
+
+func TestCandleCache_closeForming(t *testing.T) {
+	c := NewCandleCache(models.Bin5m, 10, types.XBTUSD, logrus.New())
+	require.NoError(t, c.UpdateForming(formingTrade("2020-05-16T10:06:00.000Z", 10, 1)))
+
+	c.StoreBatch([]bitmex.TradeBuck{{Symbol: string(types.XBTUSD), Timestamp: "2020-05-16T10:05:00.000Z", Close: 9}})
+	_, ok := c.GetForming()
+	require.True(t, ok, "previous bin does not close forming candle")
+
+	c.StoreBatch([]bitmex.TradeBuck{{Symbol: string(types.XBTUSD), Timestamp: "2020-05-16T10:10:00.000Z", Close: 10}})
+	_, ok = c.GetForming()
+	require.False(t, ok, "forming candle closed")
+}
+
+func TestResampledCache_GetForming(t *testing.T) {
+	source := NewCandleCache(models.Bin5m, 10, types.XBTUSD, logrus.New())
+	source.StoreBatch([]bitmex.TradeBuck{
+		resampleCandle("2020-05-16T10:00:00.000Z", 1, 1, 1, 1, 1, 1),
+		resampleCandle("2020-05-16T10:05:00.000Z", 10, 12, 9, 11, 10, 10),
+		resampleCandle("2020-05-16T10:10:00.000Z", 11, 15, 10, 14, 13, 20),
+	})
+	c := NewResampledCache(models.Bin15m, source)
+
+	_, ok := c.GetForming()
+	require.False(t, ok)
+
+	require.NoError(t, source.UpdateForming(formingTrade("2020-05-16T10:11:00.000Z", 16, 10)))
+	got, ok := c.GetForming()
+	require.True(t, ok)
+	require.Equal(t, bitmex.TradeBuck{
+		Symbol:    string(types.XBTUSD),
+		Timestamp: "2020-05-16T10:15:00.000Z",
+		Open:      10,
+		High:      16,
+		Low:       9,
+		Close:     16,
+		Trades:    3,
+		Volume:    40,
+		Vwap:      13,
+		Turnover:  1300,
+		LastSize:  10,
+	}, got.TradeBuck)
+
+	require.NoError(t, source.UpdateForming(formingTrade("2020-05-16T10:16:00.000Z", 17, 1)))
+	got, ok = c.GetForming()
+	require.True(t, ok)
+	require.Equal(t, "2020-05-16T10:30:00.000Z", got.Timestamp)
+	require.Equal(t, float64(17), got.Open)
+	require.Equal(t, 1, got.Trades)
+}
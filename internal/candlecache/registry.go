@@ -0,0 +1,161 @@
+package candlecache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+// ErrUnknownKey returned for cache key with not supported exchange, empty symbol or unknown bin size
+var ErrUnknownKey = errors.New("unknown candles cache key")
+
+// Key candles cache key
+type Key struct {
+	Exchange types.Exchange
+	Symbol   types.Symbol
+	BinSize  models.BinSize
+}
+
+func NewKey(exchange types.Exchange, symbol types.Symbol, binSize models.BinSize) Key {
+	return Key{
+		Exchange: exchange,
+		Symbol:   symbol,
+		BinSize:  binSize,
+	}
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s:%s:%s", k.Exchange, k.Symbol, k.BinSize)
+}
+
+// Source returns key of the cache which candles are used for building candles of the key
+func (k Key) Source() Key {
+	return NewKey(k.Exchange, k.Symbol, k.BinSize.Source())
+}
+
+func (k Key) validate() error {
+	if k.Exchange != types.Bitmex || k.Symbol == "" || k.BinSize.Duration() == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, k)
+	}
+	return nil
+}
+
+// LoadFunc loads candles into the cache
+type LoadFunc func(cache Cache) error
+
+// Registry keeps candles caches by exchange, symbol and bin size, caches are created on first request.
+// Resampled bin size cache reads candles from the source bin size cache of the same exchange and symbol
+type Registry struct {
+	mx       sync.Mutex
+	caches   map[Key]Cache
+	capacity map[Key]int
+	loads    map[Key]*sync.Mutex
+	maxCount int
+	log      *logrus.Logger
+}
+
+// NewRegistry creates registry, maxCount is the default count of candles kept by a cache
+func NewRegistry(maxCount int, log *logrus.Logger) *Registry {
+	return &Registry{
+		caches:   make(map[Key]Cache),
+		capacity: make(map[Key]int),
+		loads:    make(map[Key]*sync.Mutex),
+		maxCount: maxCount,
+		log:      log,
+	}
+}
+
+// Register sets count of candles kept by the cache of the key, the source cache is enlarged to keep
+// maxCount resampled candles. Cache created before registration keeps its size
+func (r *Registry) Register(key Key, maxCount int) error {
+	err := key.validate()
+	if err != nil {
+		return err
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	source := key.Source()
+	capacity := maxCount * int(key.BinSize.Duration()/source.BinSize.Duration())
+	if capacity > r.capacity[source] {
+		r.capacity[source] = capacity
+	}
+	if _, ok := r.caches[source]; ok {
+		r.log.Warnf("candles cache %s already created, its size is not changed", source)
+	}
+	return nil
+}
+
+// GetCache returns cache of the key, cache is created if it does not exist
+func (r *Registry) GetCache(key Key) (Cache, error) {
+	err := key.validate()
+	if err != nil {
+		return nil, err
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.getCache(key), nil
+}
+
+// getCache must be called under lock
+func (r *Registry) getCache(key Key) Cache {
+	cache, ok := r.caches[key]
+	if ok {
+		return cache
+	}
+	if key.BinSize.IsResampled() {
+		cache = NewResampledCache(key.BinSize, r.getCache(key.Source()))
+	} else {
+		capacity := r.capacity[key]
+		if capacity < r.maxCount {
+			capacity = r.maxCount
+		}
+		cache = NewCandleCache(key.BinSize, capacity, key.Symbol, r.log)
+	}
+	r.caches[key] = cache
+	return cache
+}
+
+// Load runs load of the key source cache, loads of the same source cache are run one by one,
+// so warm-up and backfills of the key do not overlap
+func (r *Registry) Load(key Key, load LoadFunc) error {
+	err := key.validate()
+	if err != nil {
+		return err
+	}
+	source := key.Source()
+
+	r.mx.Lock()
+	cache := r.getCache(source)
+	mx, ok := r.loads[source]
+	if !ok {
+		mx = &sync.Mutex{}
+		r.loads[source] = mx
+	}
+	r.mx.Unlock()
+
+	mx.Lock()
+	defer mx.Unlock()
+	return load(cache)
+}
+
+// UpdateForming adds trade to forming candles of the trade symbol caches
+func (r *Registry) UpdateForming(exchange types.Exchange, trade data.Trade) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for key, cache := range r.caches {
+		if key.Exchange != exchange || key.Symbol != trade.Symbol {
+			continue
+		}
+		err := cache.UpdateForming(trade)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
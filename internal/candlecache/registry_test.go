@@ -0,0 +1,113 @@
+package candlecache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestRegistry_GetCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     Key
+		wantErr bool
+	}{
+		{name: "streamed bin size", key: NewKey(types.Bitmex, types.XBTUSD, models.Bin5m)},
+		{name: "resampled bin size", key: NewKey(types.Bitmex, types.XBTUSD, models.Bin15m)},
+		{name: "unknown exchange", key: NewKey(types.Binance, types.XBTUSD, models.Bin5m), wantErr: true},
+		{name: "empty symbol", key: NewKey(types.Bitmex, "", models.Bin5m), wantErr: true},
+		{name: "unknown bin size", key: NewKey(types.Bitmex, types.XBTUSD, 0), wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(10, logrus.New())
+			cache, err := r.GetCache(tt.key)
+			if tt.wantErr {
+				require.True(t, errors.Is(err, ErrUnknownKey))
+				require.Nil(t, cache)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, cache)
+
+			same, err := r.GetCache(tt.key)
+			require.NoError(t, err)
+			require.True(t, cache == same, "cache is created once")
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry(5, logrus.New())
+	require.NoError(t, r.Register(NewKey(types.Bitmex, types.XBTUSD, models.Bin15m), 4))
+
+	source, err := r.GetCache(NewKey(types.Bitmex, types.XBTUSD, models.Bin5m))
+	require.NoError(t, err)
+	require.Equal(t, 12, source.(*CandleCache).maxCount, "source keeps 4 resampled candles")
+
+	other, err := r.GetCache(NewKey(types.Bitmex, "ETHUSD", models.Bin5m))
+	require.NoError(t, err)
+	require.Equal(t, 5, other.(*CandleCache).maxCount, "default size")
+	require.False(t, source == other)
+
+	require.Error(t, r.Register(NewKey(types.Bitmex, types.XBTUSD, 0), 4))
+}
+
+func TestRegistry_Load(t *testing.T) {
+	r := NewRegistry(10, logrus.New())
+	resampled := NewKey(types.Bitmex, types.XBTUSD, models.Bin15m)
+	source := resampled.Source()
+
+	var (
+		wg      sync.WaitGroup
+		mx      sync.Mutex
+		running int
+		maxRun  int
+	)
+	for i := 0; i < 5; i++ {
+		key := source
+		if i%2 == 0 {
+			key = resampled
+		}
+		wg.Add(1)
+		go func(key Key) {
+			defer wg.Done()
+			err := r.Load(key, func(cache Cache) error {
+				mx.Lock()
+				running++
+				if running > maxRun {
+					maxRun = running
+				}
+				mx.Unlock()
+
+				time.Sleep(time.Millisecond)
+				cache.StoreBatch([]bitmex.TradeBuck{{Symbol: string(types.XBTUSD), Timestamp: "2020-05-16T10:05:00.000Z"}})
+
+				mx.Lock()
+				running--
+				mx.Unlock()
+				return nil
+			})
+			require.NoError(t, err)
+		}(key)
+	}
+	wg.Wait()
+	require.Equal(t, 1, maxRun, "loads of the same source cache do not overlap")
+
+	cache, err := r.GetCache(source)
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.Count(), "resampled key loads the source cache")
+
+	require.Error(t, r.Load(NewKey(types.Binance, types.XBTUSD, models.Bin5m), func(cache Cache) error {
+		return nil
+	}))
+}
@@ -0,0 +1,163 @@
+package candlecache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+// Resample aggregates ordered candles of the bin size source into candles of the bin size.
+// As bitmex candles, resampled candle timestamp is the candle close time.
+// Only complete candles are returned, candle is complete when all source candles are present
+func Resample(candles []bitmex.TradeBuck, binSize models.BinSize) ([]bitmex.TradeBuck, error) {
+	if !binSize.IsResampled() {
+		return candles, nil
+	}
+	ratio := int(binSize.Duration() / binSize.Source().Duration())
+
+	var (
+		result []bitmex.TradeBuck
+		end    time.Time
+		first  int
+	)
+	for i, candle := range candles {
+		ts, err := parseTimestamp(candle.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		candleEnd := CloseTime(ts, binSize)
+		if candleEnd.Equal(end) {
+			continue
+		}
+		if i-first == ratio {
+			result = append(result, aggregate(candles[first:i], end))
+		}
+		end, first = candleEnd, i
+	}
+	if len(candles)-first == ratio {
+		result = append(result, aggregate(candles[first:], end))
+	}
+	return result, nil
+}
+
+// CloseTime returns close time of the bin size candle which includes source candle closed at ts.
+// Weeks are closed on Monday 00:00 UTC
+func CloseTime(ts time.Time, binSize models.BinSize) time.Time {
+	d := binSize.Duration()
+	closeTime := ts.Truncate(d)
+	if closeTime.Before(ts) {
+		closeTime = closeTime.Add(d)
+	}
+	return closeTime
+}
+
+// aggregate merges ordered candles into one candle closed at closeTime,
+// volume weighted average price is calculated, candle without volume keeps the last vwap
+func aggregate(candles []bitmex.TradeBuck, closeTime time.Time) bitmex.TradeBuck {
+	result := bitmex.TradeBuck{
+		Symbol:    candles[0].Symbol,
+		Timestamp: closeTime.Format(tradeapi.TradeBucketedTimestampLayout),
+		Open:      candles[0].Open,
+		High:      candles[0].High,
+		Low:       candles[0].Low,
+	}
+	var vwapSum float64
+	for _, candle := range candles {
+		vwapSum += candle.Vwap * float64(candle.Volume)
+		if candle.High > result.High {
+			result.High = candle.High
+		}
+		if candle.Low < result.Low {
+			result.Low = candle.Low
+		}
+		result.Close = candle.Close
+		result.Trades += candle.Trades
+		result.Volume += candle.Volume
+		result.Turnover += candle.Turnover
+		result.HomeNotional += candle.HomeNotional
+		result.ForeignNotional += candle.ForeignNotional
+		result.LastSize = candle.LastSize
+		result.Vwap = candle.Vwap
+	}
+	if result.Volume != 0 {
+		result.Vwap = vwapSum / float64(result.Volume)
+	}
+	return result
+}
+
+// ResampledCache builds candles of resampled bin size from the source bin size cache
+type ResampledCache struct {
+	binSize models.BinSize
+	source  Cache
+}
+
+func NewResampledCache(binSize models.BinSize, source Cache) *ResampledCache {
+	return &ResampledCache{
+		binSize: binSize,
+		source:  source,
+	}
+}
+
+// StoreBatch is not supported, candles are stored in the source cache
+func (c *ResampledCache) StoreBatch(batch []bitmex.TradeBuck) {
+}
+
+// Store is not supported, candles are stored in the source cache
+func (c *ResampledCache) Store(candle data.TradeBin) error {
+	return fmt.Errorf("%s candles are resampled from %s cache, store is not supported",
+		c.binSize, c.binSize.Source())
+}
+
+// GetBucketed returns complete resampled candles closed in [from, to], zero from or to is not limited,
+// count limits result by last candles
+func (c *ResampledCache) GetBucketed(from, to time.Time, count int) []bitmex.TradeBuck {
+	ratio := int(c.binSize.Duration() / c.binSize.Source().Duration())
+	var sourceFrom, sourceTo = from, to
+	if !from.IsZero() {
+		sourceFrom = from.Add(-c.binSize.Duration() + c.binSize.Source().Duration())
+	}
+	var sourceCount int
+	if count != 0 {
+		sourceCount = (count + 1) * ratio
+	}
+
+	candles, err := Resample(c.source.GetBucketed(sourceFrom, sourceTo, sourceCount), c.binSize)
+	if err != nil {
+		return nil
+	}
+
+	var result = candles[:0]
+	for _, candle := range candles {
+		ts, err := parseTimestamp(candle.Timestamp)
+		if err != nil {
+			return nil
+		}
+		if (!from.IsZero() && ts.Before(from)) || (!to.IsZero() && ts.After(to)) {
+			continue
+		}
+		result = append(result, candle)
+	}
+	if count != 0 && len(result) > count {
+		result = result[len(result)-count:]
+	}
+	return result
+}
+
+// Count returns count of complete resampled candles
+func (c *ResampledCache) Count() int {
+	return len(c.GetBucketed(time.Time{}, time.Time{}, c.source.Count()))
+}
+
+// Validate validates the source cache
+func (c *ResampledCache) Validate() error {
+	err := c.source.Validate()
+	if err != nil {
+		return errors.New(c.binSize.Source().String() + " source cache: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package candlecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+func resampleCandle(ts string, open, high, low, closePrice, vwap float64, volume int) bitmex.TradeBuck {
+	return bitmex.TradeBuck{
+		Symbol:    string(types.XBTUSD),
+		Timestamp: ts,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     closePrice,
+		Trades:    1,
+		Volume:    int64(volume),
+		Vwap:      vwap,
+		Turnover:  int64(volume) * 10,
+		LastSize:  volume,
+	}
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name    string
+		candles []bitmex.TradeBuck
+		binSize models.BinSize
+		want    []bitmex.TradeBuck
+		wantErr bool
+	}{
+		{
+			name: "not resampled bin size",
+			candles: []bitmex.TradeBuck{
+				resampleCandle("2020-05-16T10:05:00.000Z", 1, 2, 1, 2, 1.5, 10),
+			},
+			binSize: models.Bin5m,
+			want: []bitmex.TradeBuck{
+				resampleCandle("2020-05-16T10:05:00.000Z", 1, 2, 1, 2, 1.5, 10),
+			},
+		},
+		{
+			name: "15m from 5m, incomplete buckets skipped",
+			candles: []bitmex.TradeBuck{
+				resampleCandle("2020-05-16T10:00:00.000Z", 9, 9, 9, 9, 9, 1),
+				resampleCandle("2020-05-16T10:05:00.000Z", 10, 12, 9, 11, 10, 10),
+				resampleCandle("2020-05-16T10:10:00.000Z", 11, 15, 10, 14, 13, 20),
+				resampleCandle("2020-05-16T10:15:00.000Z", 14, 14, 8, 9, 11, 10),
+				resampleCandle("2020-05-16T10:20:00.000Z", 9, 10, 9, 10, 9.5, 5),
+			},
+			binSize: models.Bin15m,
+			want: []bitmex.TradeBuck{
+				{
+					Symbol:    string(types.XBTUSD),
+					Timestamp: "2020-05-16T10:15:00.000Z",
+					Open:      10,
+					High:      15,
+					Low:       8,
+					Close:     9,
+					Trades:    3,
+					Volume:    40,
+					Vwap:      11.75,
+					Turnover:  400,
+					LastSize:  10,
+				},
+			},
+		},
+		{
+			name: "1w closed on monday",
+			candles: []bitmex.TradeBuck{
+				resampleCandle("2020-05-12T00:00:00.000Z", 1, 2, 1, 2, 1, 1),
+				resampleCandle("2020-05-13T00:00:00.000Z", 2, 3, 2, 3, 1, 1),
+				resampleCandle("2020-05-14T00:00:00.000Z", 3, 4, 3, 4, 1, 1),
+				resampleCandle("2020-05-15T00:00:00.000Z", 4, 5, 4, 5, 1, 1),
+				resampleCandle("2020-05-16T00:00:00.000Z", 5, 6, 5, 6, 1, 1),
+				resampleCandle("2020-05-17T00:00:00.000Z", 6, 7, 6, 7, 1, 1),
+				resampleCandle("2020-05-18T00:00:00.000Z", 7, 8, 7, 8, 1, 1),
+			},
+			binSize: models.Bin1w,
+			want: []bitmex.TradeBuck{
+				{
+					Symbol:    string(types.XBTUSD),
+					Timestamp: "2020-05-18T00:00:00.000Z",
+					Open:      1,
+					High:      8,
+					Low:       1,
+					Close:     8,
+					Trades:    7,
+					Volume:    7,
+					Vwap:      1,
+					Turnover:  70,
+					LastSize:  1,
+				},
+			},
+		},
+		{
+			name: "wrong timestamp",
+			candles: []bitmex.TradeBuck{
+				resampleCandle("2020-05-16 10:05", 1, 2, 1, 2, 1.5, 10),
+			},
+			binSize: models.Bin15m,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resample(tt.candles, tt.binSize)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCloseTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		ts      time.Time
+		binSize models.BinSize
+		want    time.Time
+	}{
+		{
+			name:    "closes bucket",
+			ts:      time.Date(2020, 5, 16, 12, 0, 0, 0, time.UTC),
+			binSize: models.Bin4h,
+			want:    time.Date(2020, 5, 16, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "inside bucket",
+			ts:      time.Date(2020, 5, 16, 13, 0, 0, 0, time.UTC),
+			binSize: models.Bin4h,
+			want:    time.Date(2020, 5, 16, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "week",
+			ts:      time.Date(2020, 5, 16, 0, 0, 0, 0, time.UTC),
+			binSize: models.Bin1w,
+			want:    time.Date(2020, 5, 18, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CloseTime(tt.ts, tt.binSize))
+		})
+	}
+}
+
+func TestResampledCache_GetBucketed(t *testing.T) {
+	source := NewCandleCache(models.Bin5m, 20, types.XBTUSD, logrus.New())
+	start := time.Date(2020, 5, 16, 10, 5, 0, 0, time.UTC)
+	var batch []bitmex.TradeBuck
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * 5 * time.Minute).Format("2006-01-02T15:04:05.000Z")
+		batch = append(batch, resampleCandle(ts, float64(i), float64(i), float64(i), float64(i), 1, 1))
+	}
+	source.StoreBatch(batch)
+	c := NewResampledCache(models.Bin15m, source)
+
+	got := c.GetBucketed(time.Time{}, time.Time{}, 0)
+	require.Len(t, got, 3)
+	require.Equal(t, "2020-05-16T10:15:00.000Z", got[0].Timestamp)
+	require.Equal(t, "2020-05-16T10:45:00.000Z", got[2].Timestamp)
+	require.Equal(t, 3, c.Count())
+
+	got = c.GetBucketed(time.Date(2020, 5, 16, 10, 30, 0, 0, time.UTC), time.Time{}, 0)
+	require.Len(t, got, 2)
+	require.Equal(t, float64(3), got[0].Open)
+	require.Equal(t, float64(5), got[0].Close)
+
+	got = c.GetBucketed(time.Time{}, time.Time{}, 1)
+	require.Len(t, got, 1)
+	require.Equal(t, "2020-05-16T10:45:00.000Z", got[0].Timestamp)
+
+	require.Error(t, c.Store(data.TradeBin{}))
+	require.NoError(t, c.Validate())
+}
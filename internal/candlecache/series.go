@@ -0,0 +1,153 @@
+package candlecache
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// SeriesType candles series derived from cache candles
+type SeriesType string
+
+const (
+	CandlesSeries    SeriesType = ""            // candles as is
+	HeikinAshiSeries SeriesType = "heikin_ashi" // heikin-ashi candles
+	RenkoSeries      SeriesType = "renko"       // renko bricks by closes
+	RangeSeries      SeriesType = "range"       // range bars by candles prices
+)
+
+// Series converts ordered candles to the series type.
+// Brick size of renko and range bars is brickSize, if it is zero then ATR of the last atrCount candles.
+// Bricks and bars have timestamp of the candle which completed them, several bricks can have the same timestamp
+func Series(candles []bitmex.TradeBuck, seriesType SeriesType, brickSize float64, atrCount int) (
+	[]bitmex.TradeBuck, error,
+) {
+	switch seriesType {
+	case CandlesSeries:
+		return candles, nil
+	case HeikinAshiSeries:
+		return HeikinAshi(candles), nil
+	case RenkoSeries, RangeSeries:
+	default:
+		return nil, fmt.Errorf("unknown candles series: %s", seriesType)
+	}
+
+	if brickSize == 0 {
+		brickSize = ATR(candles, atrCount)
+	}
+	if brickSize <= 0 {
+		return nil, errors.New("brick size must be positive, set brick size or atr count")
+	}
+	if seriesType == RenkoSeries {
+		return Renko(candles, brickSize), nil
+	}
+	return RangeBars(candles, brickSize), nil
+}
+
+// HeikinAshi returns heikin-ashi candles, the first candle open is the middle of the candle body
+func HeikinAshi(candles []bitmex.TradeBuck) []bitmex.TradeBuck {
+	var result = make([]bitmex.TradeBuck, 0, len(candles))
+	for i, candle := range candles {
+		ha := candle
+		ha.Close = (candle.Open + candle.High + candle.Low + candle.Close) / 4
+		if i == 0 {
+			ha.Open = (candle.Open + candle.Close) / 2
+		} else {
+			ha.Open = (result[i-1].Open + result[i-1].Close) / 2
+		}
+		ha.High = math.Max(candle.High, math.Max(ha.Open, ha.Close))
+		ha.Low = math.Min(candle.Low, math.Min(ha.Open, ha.Close))
+		result = append(result, ha)
+	}
+	return result
+}
+
+// Renko returns renko bricks by candles closes, the first close is the base price.
+// A brick in the opposite direction is built when price moves two bricks from the last brick close
+func Renko(candles []bitmex.TradeBuck, brickSize float64) []bitmex.TradeBuck {
+	var result []bitmex.TradeBuck
+	if len(candles) == 0 || brickSize <= 0 {
+		return result
+	}
+	top, bottom := candles[0].Close, candles[0].Close
+	for _, candle := range candles[1:] {
+		for candle.Close >= top+brickSize {
+			result = append(result, newBrick(candle, top, top+brickSize))
+			bottom, top = top, top+brickSize
+		}
+		for candle.Close <= bottom-brickSize {
+			result = append(result, newBrick(candle, bottom, bottom-brickSize))
+			top, bottom = bottom, bottom-brickSize
+		}
+	}
+	return result
+}
+
+// RangeBars returns bars with high and low range equal to rangeSize, the last not completed bar is skipped.
+// Price inside a candle moves from open to the nearest extreme, then to the other extreme and to close
+func RangeBars(candles []bitmex.TradeBuck, rangeSize float64) []bitmex.TradeBuck {
+	var result []bitmex.TradeBuck
+	if len(candles) == 0 || rangeSize <= 0 {
+		return result
+	}
+	bar := newBrick(candles[0], candles[0].Open, candles[0].Open)
+	for _, candle := range candles {
+		path := []float64{candle.Open, candle.High, candle.Low, candle.Close}
+		if candle.Close >= candle.Open {
+			path[1], path[2] = candle.Low, candle.High
+		}
+		for _, price := range path {
+			for price > bar.Low+rangeSize {
+				top := bar.Low + rangeSize
+				result = append(result, newBrick(candle, bar.Open, top))
+				result[len(result)-1].Low = bar.Low
+				bar = newBrick(candle, top, top)
+			}
+			for price < bar.High-rangeSize {
+				bottom := bar.High - rangeSize
+				result = append(result, newBrick(candle, bar.Open, bottom))
+				result[len(result)-1].High = bar.High
+				bar = newBrick(candle, bottom, bottom)
+			}
+			bar.High = math.Max(bar.High, price)
+			bar.Low = math.Min(bar.Low, price)
+			bar.Close = price
+		}
+	}
+	return result
+}
+
+// ATR returns average true range of the last count candles, 0 if there are not enough candles
+func ATR(candles []bitmex.TradeBuck, count int) float64 {
+	if count <= 0 || len(candles) <= count {
+		return 0
+	}
+	var (
+		highs  = make([]float64, 0, len(candles))
+		lows   = make([]float64, 0, len(candles))
+		closes = make([]float64, 0, len(candles))
+	)
+	for _, candle := range candles {
+		highs = append(highs, candle.High)
+		lows = append(lows, candle.Low)
+		closes = append(closes, candle.Close)
+	}
+	atr := talib.Atr(highs, lows, closes, count)
+	return atr[len(atr)-1]
+}
+
+// newBrick returns candle with open and close prices and timestamp of the candle
+func newBrick(candle bitmex.TradeBuck, openPrice, closePrice float64) bitmex.TradeBuck {
+	return bitmex.TradeBuck{
+		Symbol:    candle.Symbol,
+		Timestamp: candle.Timestamp,
+		Open:      openPrice,
+		High:      math.Max(openPrice, closePrice),
+		Low:       math.Min(openPrice, closePrice),
+		Close:     closePrice,
+	}
+}
@@ -0,0 +1,105 @@
+package candlecache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func seriesCandle(ts string, open, high, low, closePrice float64) bitmex.TradeBuck {
+	return bitmex.TradeBuck{Symbol: "XBTUSD", Timestamp: ts, Open: open, High: high, Low: low, Close: closePrice}
+}
+
+func TestHeikinAshi(t *testing.T) {
+	got := HeikinAshi([]bitmex.TradeBuck{
+		seriesCandle("2020-05-16T10:05:00.000Z", 10, 14, 8, 12),
+		seriesCandle("2020-05-16T10:10:00.000Z", 12, 20, 11, 17),
+	})
+	require.Equal(t, []bitmex.TradeBuck{
+		seriesCandle("2020-05-16T10:05:00.000Z", 11, 14, 8, 11),
+		seriesCandle("2020-05-16T10:10:00.000Z", 11, 20, 11, 15),
+	}, got)
+}
+
+func TestRenko(t *testing.T) {
+	tests := []struct {
+		name      string
+		candles   []bitmex.TradeBuck
+		brickSize float64
+		want      []bitmex.TradeBuck
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "up bricks and reversal",
+			candles: []bitmex.TradeBuck{
+				seriesCandle("2020-05-16T10:05:00.000Z", 0, 0, 0, 100),
+				seriesCandle("2020-05-16T10:10:00.000Z", 0, 0, 0, 125),
+				seriesCandle("2020-05-16T10:15:00.000Z", 0, 0, 0, 112),
+				seriesCandle("2020-05-16T10:20:00.000Z", 0, 0, 0, 99),
+			},
+			brickSize: 10,
+			want: []bitmex.TradeBuck{
+				seriesCandle("2020-05-16T10:10:00.000Z", 100, 110, 100, 110),
+				seriesCandle("2020-05-16T10:10:00.000Z", 110, 120, 110, 120),
+				seriesCandle("2020-05-16T10:20:00.000Z", 110, 110, 100, 100),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Renko(tt.candles, tt.brickSize))
+		})
+	}
+}
+
+func TestRangeBars(t *testing.T) {
+	got := RangeBars([]bitmex.TradeBuck{
+		seriesCandle("2020-05-16T10:05:00.000Z", 100, 104, 99, 103),
+		seriesCandle("2020-05-16T10:10:00.000Z", 103, 108, 96, 97),
+	}, 5)
+	require.Equal(t, []bitmex.TradeBuck{
+		seriesCandle("2020-05-16T10:10:00.000Z", 100, 104, 99, 104),
+		seriesCandle("2020-05-16T10:10:00.000Z", 104, 108, 103, 103),
+		seriesCandle("2020-05-16T10:10:00.000Z", 103, 103, 98, 98),
+	}, got)
+}
+
+func TestSeries(t *testing.T) {
+	candles := []bitmex.TradeBuck{
+		seriesCandle("2020-05-16T10:05:00.000Z", 100, 110, 90, 105),
+		seriesCandle("2020-05-16T10:10:00.000Z", 105, 115, 95, 110),
+		seriesCandle("2020-05-16T10:15:00.000Z", 110, 130, 110, 130),
+	}
+	tests := []struct {
+		name       string
+		seriesType SeriesType
+		brickSize  float64
+		atrCount   int
+		wantLen    int
+		wantErr    bool
+	}{
+		{name: "candles", seriesType: CandlesSeries, wantLen: 3},
+		{name: "heikin ashi", seriesType: HeikinAshiSeries, wantLen: 3},
+		{name: "renko fixed brick", seriesType: RenkoSeries, brickSize: 5, wantLen: 5},
+		{name: "renko atr brick", seriesType: RenkoSeries, atrCount: 2, wantLen: 1},
+		{name: "range without brick size", seriesType: RangeSeries, wantErr: true},
+		{name: "unknown", seriesType: "kagi", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Series(candles, tt.seriesType, tt.brickSize, tt.atrCount)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, tt.wantLen)
+		})
+	}
+}
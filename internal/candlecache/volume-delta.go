@@ -0,0 +1,100 @@
+package candlecache
+
+import (
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+)
+
+// VolumeDelta buy and sell taker volumes of the closed bin built from trades, timestamp is the bin close time
+type VolumeDelta struct {
+	Timestamp string
+	Buy       int64
+	Sell      int64
+	ts        time.Time
+}
+
+// Delta returns buy volume minus sell volume
+func (d VolumeDelta) Delta() int64 {
+	return d.Buy - d.Sell
+}
+
+// CumulativeDelta returns cumulative volume delta of the ordered deltas
+func CumulativeDelta(deltas []VolumeDelta) []int64 {
+	var (
+		result = make([]int64, 0, len(deltas))
+		sum    int64
+	)
+	for _, delta := range deltas {
+		sum += delta.Delta()
+		result = append(result, sum)
+	}
+	return result
+}
+
+// GetVolumeDeltas returns last count volume deltas of the bins closed after the first received trade,
+// bins without trades are skipped, 0 count returns all deltas
+func (c *CandleCache) GetVolumeDeltas(count int) []VolumeDelta {
+	c.Lock()
+	defer c.Unlock()
+	first := 0
+	if count != 0 && len(c.deltas) > count {
+		first = len(c.deltas) - count
+	}
+	return append([]VolumeDelta(nil), c.deltas[first:]...)
+}
+
+// closeDelta saves volume delta of the forming candle, bin opened before the first trade is skipped
+// as its trades were not received. Must be called under lock
+func (c *CandleCache) closeDelta() {
+	if c.forming == nil || c.forming.end.Add(-c.binSize.Duration()).Before(c.firstTrade) {
+		return
+	}
+	if len(c.deltas) != 0 && !c.forming.end.After(c.deltas[len(c.deltas)-1].ts) {
+		return
+	}
+	c.deltas = append(c.deltas, VolumeDelta{
+		Timestamp: c.forming.candle.Timestamp,
+		Buy:       c.forming.candle.BuyVolume,
+		Sell:      c.forming.candle.SellVolume,
+		ts:        c.forming.end,
+	})
+	if len(c.deltas) > c.maxCount {
+		c.deltas = append(c.deltas[:0], c.deltas[len(c.deltas)-c.maxCount:]...)
+	}
+}
+
+// GetVolumeDeltas returns last count volume deltas aggregated from the source deltas,
+// only bins with deltas of all source bins are returned
+func (c *ResampledCache) GetVolumeDeltas(count int) []VolumeDelta {
+	ratio := int(c.binSize.Duration() / c.binSize.Source().Duration())
+	var sourceCount int
+	if count != 0 {
+		sourceCount = (count + 1) * ratio
+	}
+
+	var (
+		result []VolumeDelta
+		bin    VolumeDelta
+		parts  int
+	)
+	for _, delta := range c.source.GetVolumeDeltas(sourceCount) {
+		end := CloseTime(delta.ts, c.binSize)
+		if !end.Equal(bin.ts) {
+			if parts == ratio {
+				result = append(result, bin)
+			}
+			bin, parts = VolumeDelta{Timestamp: end.Format(tradeapi.TradeBucketedTimestampLayout), ts: end}, 0
+		}
+		bin.Buy += delta.Buy
+		bin.Sell += delta.Sell
+		parts++
+	}
+	if parts == ratio {
+		result = append(result, bin)
+	}
+	if count != 0 && len(result) > count {
+		result = result[len(result)-count:]
+	}
+	return result
+}
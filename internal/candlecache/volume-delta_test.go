@@ -0,0 +1,86 @@
+package candlecache
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+func sideTrade(ts string, side types.Side, size int) data.Trade {
+	trade := formingTrade(ts, 10, size)
+	trade.Side = side
+	return trade
+}
+
+func TestCandleCache_GetVolumeDeltas(t *testing.T) {
+	c := NewCandleCache(models.Bin5m, 3, types.XBTUSD, logrus.New())
+	for _, trade := range []data.Trade{
+		sideTrade("2020-05-16T10:06:00.000Z", types.SideBuy, 5),
+		sideTrade("2020-05-16T10:11:00.000Z", types.SideBuy, 3),
+		sideTrade("2020-05-16T10:12:00.000Z", types.SideSell, 1),
+	} {
+		require.NoError(t, c.UpdateForming(trade))
+	}
+	require.Empty(t, c.GetVolumeDeltas(0), "bin opened before the first trade is skipped")
+
+	forming, ok := c.GetForming()
+	require.True(t, ok)
+	require.Equal(t, int64(3), forming.BuyVolume)
+	require.Equal(t, int64(1), forming.SellVolume)
+
+	require.NoError(t, c.UpdateForming(sideTrade("2020-05-16T10:16:00.000Z", types.SideSell, 4)))
+	c.StoreBatch([]bitmex.TradeBuck{{Symbol: string(types.XBTUSD), Timestamp: "2020-05-16T10:20:00.000Z", Close: 10}})
+
+	want := []VolumeDelta{
+		{Timestamp: "2020-05-16T10:15:00.000Z", Buy: 3, Sell: 1},
+		{Timestamp: "2020-05-16T10:20:00.000Z", Sell: 4},
+	}
+	got := c.GetVolumeDeltas(0)
+	require.Len(t, got, len(want))
+	for i := range want {
+		require.Equal(t, want[i].Timestamp, got[i].Timestamp)
+		require.Equal(t, want[i].Buy, got[i].Buy)
+		require.Equal(t, want[i].Sell, got[i].Sell)
+	}
+	require.Equal(t, []int64{2, -2}, CumulativeDelta(got))
+	require.Len(t, c.GetVolumeDeltas(1), 1)
+	require.Equal(t, "2020-05-16T10:20:00.000Z", c.GetVolumeDeltas(1)[0].Timestamp)
+
+	for _, ts := range []string{"2020-05-16T10:21:00.000Z", "2020-05-16T10:26:00.000Z", "2020-05-16T10:31:00.000Z"} {
+		require.NoError(t, c.UpdateForming(sideTrade(ts, types.SideBuy, 1)))
+	}
+	require.Len(t, c.GetVolumeDeltas(0), 3, "deltas are limited by the cache max count")
+}
+
+func TestResampledCache_GetVolumeDeltas(t *testing.T) {
+	source := NewCandleCache(models.Bin5m, 10, types.XBTUSD, logrus.New())
+	for _, trade := range []data.Trade{
+		sideTrade("2020-05-16T10:11:00.000Z", types.SideBuy, 3),
+		sideTrade("2020-05-16T10:16:00.000Z", types.SideSell, 4),
+		sideTrade("2020-05-16T10:21:00.000Z", types.SideBuy, 2),
+		sideTrade("2020-05-16T10:26:00.000Z", types.SideBuy, 1),
+		sideTrade("2020-05-16T10:31:00.000Z", types.SideSell, 1),
+		sideTrade("2020-05-16T10:36:00.000Z", types.SideSell, 2),
+	} {
+		require.NoError(t, source.UpdateForming(trade))
+	}
+	c := NewResampledCache(models.Bin15m, source)
+
+	got := c.GetVolumeDeltas(0)
+	require.Len(t, got, 1, "bin with missing source deltas is skipped")
+	require.Equal(t, "2020-05-16T10:30:00.000Z", got[0].Timestamp)
+	require.Equal(t, int64(3), got[0].Buy)
+	require.Equal(t, int64(4), got[0].Sell)
+
+	forming, ok := c.GetForming()
+	require.True(t, ok)
+	require.Equal(t, "2020-05-16T10:45:00.000Z", forming.Timestamp)
+	require.Equal(t, int64(0), forming.BuyVolume)
+	require.Equal(t, int64(3), forming.SellVolume)
+}
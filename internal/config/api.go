@@ -22,6 +22,8 @@ type APISettings struct {
 	TimeoutSec          int
 	RetrySec            int
 	BufferSize          int
+	OverflowPolicy      string
+	OrderProcBufferSize int // queue size of the order processor subscriber, the queue spills over the size
 	Currency            string
 	Symbol              string
 	OrderType           types.OrderType
@@ -30,6 +32,15 @@ type APISettings struct {
 	LimitContractsCount int
 	SellOrderCoef       float64
 	BuyOrderCoef        float64
+
+	CancelOrdersOnShutdown bool
+	FormingCandles         bool
+
+	// MakerFee and TakerFee fee rates, negative rate is rebate
+	MakerFee float64
+	TakerFee float64
+	// InstrumentFees fee rates are read from the instrument, it is set if maker and taker fees are not configured
+	InstrumentFees bool
 }
 
 type ExchangesAccess struct {
@@ -56,6 +67,7 @@ func initExchangesAPI() ExchangesSettings {
 			TimeoutSec:          30,
 			RetrySec:            5,
 			BufferSize:          10,
+			OverflowPolicy:      "coalesce",
 			Currency:            "XBt",
 			Symbol:              "XBTUSD",
 			OrderType:           types.Limit,
@@ -64,6 +76,7 @@ func initExchangesAPI() ExchangesSettings {
 			LimitContractsCount: 300,
 			BuyOrderCoef:        0.2,
 			SellOrderCoef:       0.1,
+			InstrumentFees:      true,
 		}
 	} else {
 		bitmex = APISettings{
@@ -72,6 +85,8 @@ func initExchangesAPI() ExchangesSettings {
 			TimeoutSec:          viper.GetInt("exchanges_settings.bitmex.timeout_sec"),
 			RetrySec:            viper.GetInt("exchanges_settings.bitmex.retry_sec"),
 			BufferSize:          viper.GetInt("exchanges_settings.bitmex.buffer_size"),
+			OverflowPolicy:      viper.GetString("exchanges_settings.bitmex.overflow_policy"),
+			OrderProcBufferSize: viper.GetInt("exchanges_settings.bitmex.order_proc_buffer_size"),
 			Symbol:              viper.GetString("exchanges_settings.bitmex.symbol"),
 			Currency:            viper.GetString("exchanges_settings.bitmex.currency"),
 			OrderType:           types.OrderType(viper.GetString("exchanges_settings.bitmex.order_type")),
@@ -80,6 +95,14 @@ func initExchangesAPI() ExchangesSettings {
 			LimitContractsCount: viper.GetInt("exchanges_settings.bitmex.limit_contracts_cnt"),
 			BuyOrderCoef:        viper.GetFloat64("exchanges_settings.bitmex.buy_order_coef"),
 			SellOrderCoef:       viper.GetFloat64("exchanges_settings.bitmex.sell_order_coef"),
+
+			CancelOrdersOnShutdown: viper.GetBool("exchanges_settings.bitmex.cancel_orders_on_shutdown"),
+			FormingCandles:         viper.GetBool("exchanges_settings.bitmex.forming_candles"),
+
+			MakerFee: viper.GetFloat64("exchanges_settings.bitmex.maker_fee"),
+			TakerFee: viper.GetFloat64("exchanges_settings.bitmex.taker_fee"),
+			InstrumentFees: !viper.IsSet("exchanges_settings.bitmex.maker_fee") &&
+				!viper.IsSet("exchanges_settings.bitmex.taker_fee"),
 		}
 	}
 	fmt.Println("--------------------------------------------")
@@ -10,14 +10,15 @@ import (
 )
 
 type GlobalConfig struct {
-	ExchangesSettings ExchangesSettings
-	Admin             Admin
-	Scheduler         Scheduler
-	Accesses          ExchangesAccess
-	GlobStrategies    StrategiesGlobConfig
-	OrdProcPeriodSec  int
-	DBPath            string
-	UpdatedAt         time.Time
+	ExchangesSettings  ExchangesSettings
+	Admin              Admin
+	Scheduler          Scheduler
+	Accesses           ExchangesAccess
+	GlobStrategies     StrategiesGlobConfig
+	OrdProcPeriodSec   int
+	ShutdownTimeoutSec int
+	DBPath             string
+	UpdatedAt          time.Time
 }
 
 type Admin struct {
@@ -85,16 +86,13 @@ func toGlobalConfig(cfgM models.GlobalConfig) GlobalConfig {
 	strategiesCfg.MacdFastCount = cfgM.StrategiesConfig.MacdFastCount
 	strategiesCfg.MacdSlowCount = cfgM.StrategiesConfig.MacdSlowCount
 	strategiesCfg.MacdSigCount = cfgM.StrategiesConfig.MacdSigCount
-	switch cfgM.StrategiesConfig.Bin {
-	case models.Bin1m:
-		cfg.GlobStrategies.M1 = &strategiesCfg
-	case models.Bin5m:
-		cfg.GlobStrategies.M5 = &strategiesCfg
-	case models.Bin1h:
-		cfg.GlobStrategies.H1 = &strategiesCfg
-	case models.Bin1d:
-		cfg.GlobStrategies.D1 = &strategiesCfg
-	}
+	strategiesCfg.BBPeriod = cfgM.StrategiesConfig.BBPeriod
+	strategiesCfg.BBDeviation = cfgM.StrategiesConfig.BBDeviation
+	strategiesCfg.BBMAType = cfgM.StrategiesConfig.BBMAType
+	strategiesCfg.MacdFastMAType = cfgM.StrategiesConfig.MacdFastMAType
+	strategiesCfg.MacdSlowMAType = cfgM.StrategiesConfig.MacdSlowMAType
+	strategiesCfg.MacdSigMAType = cfgM.StrategiesConfig.MacdSigMAType
+	cfg.GlobStrategies.set(cfgM.StrategiesConfig.Bin.String(), &strategiesCfg)
 
 	return cfg
 }
@@ -164,6 +162,12 @@ func toModelConfiguration(cfg GlobalConfig) (models.GlobalConfig, error) {
 	cfgM.StrategiesConfig.MacdFastCount = strategy.MacdFastCount
 	cfgM.StrategiesConfig.MacdSlowCount = strategy.MacdSlowCount
 	cfgM.StrategiesConfig.MacdSigCount = strategy.MacdSigCount
+	cfgM.StrategiesConfig.BBPeriod = strategy.BBPeriod
+	cfgM.StrategiesConfig.BBDeviation = strategy.BBDeviation
+	cfgM.StrategiesConfig.BBMAType = strategy.BBMAType
+	cfgM.StrategiesConfig.MacdFastMAType = strategy.MacdFastMAType
+	cfgM.StrategiesConfig.MacdSlowMAType = strategy.MacdSlowMAType
+	cfgM.StrategiesConfig.MacdSigMAType = strategy.MacdSigMAType
 
 	return cfgM, nil
 }
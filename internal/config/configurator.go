@@ -82,10 +82,11 @@ func (u *Configurator) parseConfig(cfgFile string) error {
 				Username:    viper.GetString("admin.username"),
 				SecretToken: viper.GetString("admin.secret_token"),
 			},
-			Accesses:         initExchangesAccesses(),
-			DBPath:           initDBPath(),
-			Scheduler:        initSchedulers(),
-			OrdProcPeriodSec: viper.GetInt("ord_proc_period_sec"),
+			Accesses:           initExchangesAccesses(),
+			DBPath:             initDBPath(),
+			Scheduler:          initSchedulers(),
+			OrdProcPeriodSec:   viper.GetInt("ord_proc_period_sec"),
+			ShutdownTimeoutSec: viper.GetInt("shutdown_timeout_sec"),
 		}
 		err := u.Update()
 		if err != nil {
@@ -102,10 +103,11 @@ func (u *Configurator) parseConfig(cfgFile string) error {
 			Username:    viper.GetString("admin.username"),
 			SecretToken: viper.GetString("admin.secret_token"),
 		},
-		Accesses:         initExchangesAccesses(),
-		DBPath:           initDBPath(),
-		Scheduler:        initSchedulers(),
-		OrdProcPeriodSec: viper.GetInt("ord_proc_period_sec"),
+		Accesses:           initExchangesAccesses(),
+		DBPath:             initDBPath(),
+		Scheduler:          initSchedulers(),
+		OrdProcPeriodSec:   viper.GetInt("ord_proc_period_sec"),
+		ShutdownTimeoutSec: viper.GetInt("shutdown_timeout_sec"),
 	}
 	fmt.Println("--------------------------------------------")
 	fmt.Printf("global cfg: %#v\n", u.cfg)
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 )
 
@@ -14,6 +17,11 @@ type StrategiesGlobConfig struct {
 	M5 *StrategiesConfig
 	H1 *StrategiesConfig
 	D1 *StrategiesConfig
+	// resampled from bitmex candles
+	M15 *StrategiesConfig
+	M30 *StrategiesConfig
+	H4  *StrategiesConfig
+	W1  *StrategiesConfig
 }
 
 func initGlobStrategies() StrategiesGlobConfig {
@@ -44,46 +52,23 @@ func initGlobStrategies() StrategiesGlobConfig {
 	} else {
 		for k := range globStrateg {
 			k = strings.ToLower(k)
-			var strategies = StrategiesConfig{
-				EnableMACD:        viper.GetBool(sprintFstrategy("strategies_g.%s.enable_macd", k)),
-				EnableRSIBB:       viper.GetBool(sprintFstrategy("strategies_g.%s.enable_rsi_bb", k)),
-				RetryProcessCount: viper.GetInt(sprintFstrategy("strategies_g.%s.retry_process_count", k)),
-				GetCandlesCount:   viper.GetInt(sprintFstrategy("strategies_g.%s.get_candles_count", k)),
-
-				CandlesFilterEnable:   viper.GetBool(sprintFstrategy("strategies_g.%s.candles_filter_enable", k)),
-				TrendFilterEnable:     viper.GetBool(sprintFstrategy("strategies_g.%s.trend_filter_enable", k)),
-				MaxFilterTrendCount:   viper.GetInt(sprintFstrategy("strategies_g.%s.max_filter_trend_count", k)),
-				MaxCandlesFilterCount: viper.GetInt(sprintFstrategy("strategies_g.%s.max_candles_filter_count", k)),
-
-				BBLastCandlesCount: viper.GetInt(sprintFstrategy("strategies_g.%s.bb_last_candles_count", k)),
-				MacdFastCount:      viper.GetInt(sprintFstrategy("strategies_g.%s.macd_fast_count", k)),
-				MacdSlowCount:      viper.GetInt(sprintFstrategy("strategies_g.%s.macd_slow_count", k)),
-				MacdSigCount:       viper.GetInt(sprintFstrategy("strategies_g.%s.macd_sig_count", k)),
-				RsiCount:           viper.GetInt(sprintFstrategy("strategies_g.%s.rsi_count", k)),
-				RsiMinBorder:       viper.GetUint32(sprintFstrategy("strategies_g.%s.rsi_min_border", k)),
-				RsiMaxBorder:       viper.GetUint32(sprintFstrategy("strategies_g.%s.rsi_max_border", k)),
-				RsiTradeCoef:       viper.GetFloat64(sprintFstrategy("strategies_g.%s.rsi_trade_coef", k)),
+			var strategies StrategiesConfig
+			readStrategiesConfig(viper.GetViper(), sprintFstrategy("strategies_g.%s.", k), &strategies)
+			instances, err := readStrategyInstances(viper.Get(sprintFstrategy("strategies_g.%s.strategies", k)), strategies)
+			if err != nil {
+				logrus.Fatalf("%s strategies cfg: %v", k, err)
 			}
+			strategies.Instances = instances
 
-			switch k {
-			case "1m":
-				globalStrategies.M1 = &strategies
-				fmt.Println("--------------------------------------------")
-				fmt.Printf("1m strategies cfg: %#v\n", strategies)
-				fmt.Println("--------------------------------------------")
-			case "5m":
-				globalStrategies.M5 = &strategies
-				fmt.Println("--------------------------------------------")
-				fmt.Printf("5m strategies cfg: %#v\n", strategies)
-				fmt.Println("--------------------------------------------")
-			case "1h":
-				globalStrategies.H1 = &strategies
-				fmt.Println("--------------------------------------------")
-				fmt.Printf("1h strategies cfg: %#v\n", strategies)
-				fmt.Println("--------------------------------------------")
-			default:
-				logrus.Fatal("unknown global strategies bin size key, must be only: 1m,5m, 1h, 1d")
+			if err := strategies.Validate(); err != nil {
+				logrus.Fatalf("%s strategies cfg: %v", k, err)
+			}
+			if !globalStrategies.set(k, &strategies) {
+				logrus.Fatal("unknown global strategies bin size key, must be only: 1m, 5m, 15m, 30m, 1h, 4h, 1d, 1w")
 			}
+			fmt.Println("--------------------------------------------")
+			fmt.Printf("%s strategies cfg: %#v\n", k, strategies)
+			fmt.Println("--------------------------------------------")
 		}
 	}
 
@@ -96,47 +81,220 @@ func (s *StrategiesGlobConfig) GetCfgByBinSize(binSize string) *StrategiesConfig
 		return s.M1
 	case "5m":
 		return s.M5
+	case "15m":
+		return s.M15
+	case "30m":
+		return s.M30
 	case "1h":
 		return s.H1
+	case "4h":
+		return s.H4
 	case "1d":
 		return s.D1
+	case "1w":
+		return s.W1
 	default:
 		return nil
 	}
 }
 
-func (s *StrategiesGlobConfig) GetThemes() []types.Theme {
-	var result []types.Theme
-	if s.M1 != nil {
-		result = append(result, types.TradeBin1m)
-	}
-	if s.M5 != nil {
-		result = append(result, types.TradeBin5m)
-	}
-	if s.H1 != nil {
-		result = append(result, types.TradeBin1h)
+// set sets strategies config by bin size, returns false for unknown bin size
+func (s *StrategiesGlobConfig) set(binSize string, cfg *StrategiesConfig) bool {
+	switch binSize {
+	case "1m":
+		s.M1 = cfg
+	case "5m":
+		s.M5 = cfg
+	case "15m":
+		s.M15 = cfg
+	case "30m":
+		s.M30 = cfg
+	case "1h":
+		s.H1 = cfg
+	case "4h":
+		s.H4 = cfg
+	case "1d":
+		s.D1 = cfg
+	case "1w":
+		s.W1 = cfg
+	default:
+		return false
 	}
-	if s.D1 != nil {
-		result = append(result, types.TradeBin1d)
+	return true
+}
+
+// GetThemes returns bitmex trade bin themes, resampled bin sizes are subscribed by their source bin size
+func (s *StrategiesGlobConfig) GetThemes() []types.Theme {
+	var (
+		result []types.Theme
+		exist  = make(map[types.Theme]bool)
+	)
+	for _, binSize := range s.GetBinSizes() {
+		bin, err := models.ToBinSize(binSize)
+		if err != nil {
+			continue
+		}
+		theme := types.Theme(string(types.TradeBin) + bin.Source().String())
+		if exist[theme] {
+			continue
+		}
+		exist[theme] = true
+		result = append(result, theme)
 	}
 	return result
 }
 
 func (s *StrategiesGlobConfig) GetBinSizes() []string {
 	var result []string
-	if s.M1 != nil {
-		result = append(result, "1m")
+	for _, binSize := range []string{"1m", "5m", "15m", "30m", "1h", "4h", "1d", "1w"} {
+		if s.GetCfgByBinSize(binSize) != nil {
+			result = append(result, binSize)
+		}
 	}
-	if s.M5 != nil {
-		result = append(result, "5m")
+	return result
+}
+
+// readStrategiesConfig overrides fields of the config by keys with the prefix which are set in v
+func readStrategiesConfig(v *viper.Viper, prefix string, cfg *StrategiesConfig) { // nolint:funlen
+	var (
+		boolKey = func(key string, field *bool) {
+			if v.IsSet(prefix + key) {
+				*field = v.GetBool(prefix + key)
+			}
+		}
+		intKey = func(key string, field *int) {
+			if v.IsSet(prefix + key) {
+				*field = v.GetInt(prefix + key)
+			}
+		}
+		uint32Key = func(key string, field *uint32) {
+			if v.IsSet(prefix + key) {
+				*field = v.GetUint32(prefix + key)
+			}
+		}
+		floatKey = func(key string, field *float64) {
+			if v.IsSet(prefix + key) {
+				*field = v.GetFloat64(prefix + key)
+			}
+		}
+		stringKey = func(key string, field *string) {
+			if v.IsSet(prefix + key) {
+				*field = v.GetString(prefix + key)
+			}
+		}
+	)
+	boolKey("enable_macd", &cfg.EnableMACD)
+	boolKey("enable_rsi_bb", &cfg.EnableRSIBB)
+	boolKey("enable_bb", &cfg.EnableRSIBB)
+	boolKey("enable_rsi", &cfg.EnableRSI)
+	intKey("retry_process_count", &cfg.RetryProcessCount)
+	intKey("get_candles_count", &cfg.GetCandlesCount)
+
+	boolKey("candles_filter_enable", &cfg.CandlesFilterEnable)
+	boolKey("trend_filter_enable", &cfg.TrendFilterEnable)
+	intKey("max_filter_trend_count", &cfg.MaxFilterTrendCount)
+	intKey("max_candles_filter_count", &cfg.MaxCandlesFilterCount)
+	boolKey("volume_filter_enable", &cfg.VolumeFilterEnable)
+	floatKey("min_relative_volume", &cfg.MinRelativeVolume)
+	boolKey("levels_filter_enable", &cfg.LevelsFilterEnable)
+	floatKey("levels_distance", &cfg.LevelsDistance)
+
+	intKey("bb_last_candles_count", &cfg.BBLastCandlesCount)
+	intKey("macd_fast_count", &cfg.MacdFastCount)
+	intKey("macd_slow_count", &cfg.MacdSlowCount)
+	intKey("macd_sig_count", &cfg.MacdSigCount)
+	intKey("rsi_count", &cfg.RsiCount)
+	uint32Key("rsi_min_border", &cfg.RsiMinBorder)
+	uint32Key("rsi_max_border", &cfg.RsiMaxBorder)
+	floatKey("rsi_trade_coef", &cfg.RsiTradeCoef)
+	boolKey("rsi_cross_confirm", &cfg.RsiCrossConfirm)
+	boolKey("rsi_exit_midline", &cfg.RsiExitMidline)
+
+	intKey("bb_period", &cfg.BBPeriod)
+	floatKey("bb_deviation", &cfg.BBDeviation)
+	stringKey("bb_ma_type", &cfg.BBMAType)
+	stringKey("macd_fast_ma_type", &cfg.MacdFastMAType)
+	stringKey("macd_slow_ma_type", &cfg.MacdSlowMAType)
+	stringKey("macd_sig_ma_type", &cfg.MacdSigMAType)
+
+	intKey("warmup_candles_count", &cfg.WarmupCandlesCount)
+
+	stringKey("series", &cfg.Series)
+	stringKey("filter_series", &cfg.FilterSeries)
+	floatKey("series_brick_size", &cfg.SeriesBrickSize)
+	intKey("series_atr_count", &cfg.SeriesATRCount)
+
+	floatKey("straddle_trade_coef", &cfg.StraddleTradeCoef)
+	floatKey("straddle_skew", &cfg.StraddleSkew)
+	intKey("straddle_spread_ticks", &cfg.StraddleSpreadTicks)
+	intKey("straddle_requote_ticks", &cfg.StraddleRequoteTicks)
+}
+
+// readStrategyInstances reads list of strategy instances, every instance has name, type and parameters
+// which override strategies config of the bin size
+func readStrategyInstances(value interface{}, binCfg StrategiesConfig) ([]StrategyInstance, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.New("strategies must be list of strategy instances")
 	}
-	if s.H1 != nil {
-		result = append(result, "1h")
+	var (
+		result = make([]StrategyInstance, 0, len(items))
+		names  = make(map[string]bool)
+	)
+	for i, item := range items {
+		params, err := toStringMap(item)
+		if err != nil {
+			return nil, fmt.Errorf("strategy instance #%d: %v", i, err)
+		}
+		v := viper.New()
+		err = v.MergeConfigMap(params)
+		if err != nil {
+			return nil, fmt.Errorf("strategy instance #%d: %v", i, err)
+		}
+
+		instance := StrategyInstance{
+			Name:   strings.ToLower(v.GetString("name")),
+			Type:   strings.ToLower(v.GetString("type")),
+			Config: binCfg,
+		}
+		if instance.Type == "" {
+			return nil, fmt.Errorf("strategy instance #%d type is empty", i)
+		}
+		if instance.Name == "" {
+			instance.Name = instance.Type
+		}
+		if names[instance.Name] {
+			return nil, fmt.Errorf("strategy instance name %s is duplicated", instance.Name)
+		}
+		names[instance.Name] = true
+
+		instance.Config.Instances = nil
+		readStrategiesConfig(v, "", &instance.Config)
+		if err := instance.Config.Validate(); err != nil {
+			return nil, fmt.Errorf("strategy instance %s: %v", instance.Name, err)
+		}
+		result = append(result, instance)
 	}
-	if s.D1 != nil {
-		result = append(result, "1d")
+	return result, nil
+}
+
+// toStringMap converts yaml or json map to map with string keys
+func toStringMap(value interface{}) (map[string]interface{}, error) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, nil
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			result[fmt.Sprint(k)] = v
+		}
+		return result, nil
+	default:
+		return nil, errors.New("strategy instance must be map of parameters")
 	}
-	return result
 }
 
 func sprintFstrategy(config, name string) string {
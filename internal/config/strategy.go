@@ -1,8 +1,35 @@
 package config
 
+import (
+	"fmt"
+
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+)
+
+// strategy types enabled by flags of the bin size, instances of these strategies are named by their types
+const (
+	BBRSIStrategyType = "bb_rsi"
+	MACDStrategyType  = "macd"
+	RSIStrategyType   = "rsi"
+)
+
+// StraddleStrategyType type of the straddle strategy, it is enabled only by strategies list of the bin size
+const StraddleStrategyType = "straddle"
+
+// StrategyInstance named instance of the strategy type, Config is strategies config of the bin size
+// with parameters of the instance
+type StrategyInstance struct {
+	Name   string
+	Type   string
+	Config StrategiesConfig
+}
+
 type StrategiesConfig struct {
 	EnableMACD          bool
-	EnableRSIBB         bool
+	EnableRSIBB         bool // enable_bb or enable_rsi_bb
+	EnableRSI           bool
 	TrendFilterEnable   bool
 	CandlesFilterEnable bool
 	RetryProcessCount   int
@@ -11,18 +38,212 @@ type StrategiesConfig struct {
 	MaxFilterTrendCount   int
 	MaxCandlesFilterCount int
 
+	// VolumeFilterEnable enables the volume filter, MinRelativeVolume min relative volume of the last candle
+	// confirmed by the filter, 1 if it is zero
+	VolumeFilterEnable bool
+	MinRelativeVolume  float64
+	// LevelsFilterEnable enables the levels filter, LevelsDistance max distance from the close to the level
+	// as part of the close, 0.002 if it is zero
+	LevelsFilterEnable bool
+	LevelsDistance     float64
+
 	BBLastCandlesCount int
 
 	RsiCount     int
 	RsiMinBorder uint32
 	RsiMaxBorder uint32
 	RsiTradeCoef float64
+	// RsiCrossConfirm rsi strategy enters when rsi crosses back through the border, otherwise beyond the border.
+	// RsiExitMidline rsi strategy closes the position when rsi crosses the midline against it
+	RsiCrossConfirm bool
+	RsiExitMidline  bool
 
 	MacdFastCount int
 	MacdSlowCount int
 	MacdSigCount  int
+
+	// BBPeriod bollinger band period, get_candles_count if it is zero.
+	// BBDeviation multiplier of the standard deviation, 2 if it is zero
+	BBPeriod    int
+	BBDeviation float64
+	// moving average types: SMA, EMA, WMA, DEMA, TEMA, KAMA.
+	// Defaults are EMA for bollinger band and EMA, EMA, WMA for macd fast, slow and signal
+	BBMAType       string
+	MacdFastMAType string
+	MacdSlowMAType string
+	MacdSigMAType  string
+
+	// WarmupCandlesCount count of candles loaded into the cache on start, candles are read from db first
+	WarmupCandlesCount int
+
+	// Series candles series used by strategies and FilterSeries used by filters:
+	// empty - candles, heikin_ashi, renko, range
+	Series       string
+	FilterSeries string
+	// SeriesBrickSize renko brick and range bar size, if it is zero then ATR of SeriesATRCount candles is used
+	SeriesBrickSize float64
+	SeriesATRCount  int
+
+	// StraddleTradeCoef part of the available balance for both straddle orders, buy_order_coef or sell_order_coef
+	// of the signal side if it is zero. StraddleSkew part of the signal side order, 0.5 if it is zero.
+	// StraddleSpreadTicks distance of the orders from the mid price in ticks,
+	// StraddleRequoteTicks min price change in ticks which amends the order, 1 if it is zero
+	StraddleTradeCoef    float64
+	StraddleSkew         float64
+	StraddleSpreadTicks  int
+	StraddleRequoteTicks int
+
+	// Instances strategy instances configured by strategies list of the bin size
+	Instances []StrategyInstance
 }
 
 func (strategies *StrategiesConfig) AnyStrategyEnabled() bool {
-	return strategies.EnableRSIBB
+	return len(strategies.GetInstances()) > 0
+}
+
+// GetInstances returns configured strategy instances and instances of strategies enabled by flags,
+// instance of the flag is skipped if configured instance has the same name
+func (strategies *StrategiesConfig) GetInstances() []StrategyInstance {
+	var (
+		result = append([]StrategyInstance{}, strategies.Instances...)
+		names  = make(map[string]bool, len(result))
+	)
+	for _, instance := range result {
+		names[instance.Name] = true
+	}
+	for _, flag := range []struct {
+		enabled      bool
+		strategyType string
+	}{
+		{strategies.EnableRSIBB, BBRSIStrategyType},
+		{strategies.EnableMACD, MACDStrategyType},
+		{strategies.EnableRSI, RSIStrategyType},
+	} {
+		if !flag.enabled || names[flag.strategyType] {
+			continue
+		}
+		instance := StrategyInstance{Name: flag.strategyType, Type: flag.strategyType, Config: *strategies}
+		instance.Config.Instances = nil
+		result = append(result, instance)
+	}
+	return result
+}
+
+// WarmupCount returns count of candles loaded on start, not less than needed for macd and bollinger band
+// signals init
+func (strategies *StrategiesConfig) WarmupCount() int {
+	count := strategies.MacdSlowCount * 2
+	for _, needed := range []int{
+		strategies.MACDLookback() + strategies.MacdSigCount + 1,
+		strategies.BBCandlesCount() + strategies.MacdSigCount,
+		strategies.WarmupCandlesCount,
+	} {
+		if needed > count {
+			count = needed
+		}
+	}
+	return count
+}
+
+// MACDLookback returns count of values before the first macd value
+func (strategies *StrategiesConfig) MACDLookback() int {
+	fast, slow, sig := strategies.GetMacdMATypes()
+	lookback := trademath.MALookback(fast, strategies.MacdFastCount)
+	if slowLookback := trademath.MALookback(slow, strategies.MacdSlowCount); slowLookback > lookback {
+		lookback = slowLookback
+	}
+	return lookback + trademath.MALookback(sig, strategies.MacdSigCount)
+}
+
+// Validate checks moving average types and straddle skew
+func (strategies *StrategiesConfig) Validate() error {
+	if strategies.StraddleSkew != 0 && (strategies.StraddleSkew < 0.5 || strategies.StraddleSkew > 1) {
+		return fmt.Errorf("straddle skew %v must be from 0.5 to 1", strategies.StraddleSkew)
+	}
+	for _, maType := range []string{
+		strategies.BBMAType, strategies.MacdFastMAType, strategies.MacdSlowMAType, strategies.MacdSigMAType,
+	} {
+		if maType == "" {
+			continue
+		}
+		_, err := trademath.ToMAType(maType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (strategies *StrategiesConfig) GetStraddleSkew() float64 {
+	if strategies.StraddleSkew == 0 {
+		return 0.5
+	}
+	return strategies.StraddleSkew
+}
+
+func (strategies *StrategiesConfig) GetStraddleRequoteTicks() int {
+	if strategies.StraddleRequoteTicks <= 0 {
+		return 1
+	}
+	return strategies.StraddleRequoteTicks
+}
+
+func (strategies *StrategiesConfig) GetBBPeriod() int {
+	if strategies.BBPeriod == 0 {
+		return strategies.GetCandlesCount
+	}
+	return strategies.BBPeriod
+}
+
+func (strategies *StrategiesConfig) GetBBDeviation() float64 {
+	if strategies.BBDeviation == 0 {
+		return 2
+	}
+	return strategies.BBDeviation
+}
+
+func (strategies *StrategiesConfig) GetBBMAType() talib.MaType {
+	return toMAType(strategies.BBMAType, talib.EMA)
+}
+
+func (strategies *StrategiesConfig) GetMinRelativeVolume() float64 {
+	if strategies.MinRelativeVolume == 0 {
+		return 1
+	}
+	return strategies.MinRelativeVolume
+}
+
+func (strategies *StrategiesConfig) GetLevelsDistance() float64 {
+	if strategies.LevelsDistance == 0 {
+		return 0.002
+	}
+	return strategies.LevelsDistance
+}
+
+// BBCandlesCount returns count of closes needed for the bollinger band
+func (strategies *StrategiesConfig) BBCandlesCount() int {
+	period := strategies.GetBBPeriod()
+	if lookback := trademath.MALookback(strategies.GetBBMAType(), period); lookback >= period {
+		return lookback + 1
+	}
+	return period
+}
+
+// GetMacdMATypes returns moving average types of macd fast, slow and signal lines
+func (strategies *StrategiesConfig) GetMacdMATypes() (fast, slow, sig talib.MaType) {
+	return toMAType(strategies.MacdFastMAType, talib.EMA),
+		toMAType(strategies.MacdSlowMAType, talib.EMA),
+		toMAType(strategies.MacdSigMAType, talib.WMA)
+}
+
+// toMAType returns moving average type by name, defaultType if name is empty or unknown
+func toMAType(name string, defaultType talib.MaType) talib.MaType {
+	if name == "" {
+		return defaultType
+	}
+	maType, err := trademath.ToMAType(name)
+	if err != nil {
+		return defaultType
+	}
+	return maType
 }
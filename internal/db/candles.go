@@ -0,0 +1,126 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+const candleTimestampLayout = "2006-01-02 15:04:05+00:00"
+
+// SaveCandles saves closed candles of the bin size, candle with already saved symbol and timestamp is updated
+func (db *DB) SaveCandles(binSize models.BinSize, candles []bitmex.TradeBuck) (err error) {
+	if len(candles) == 0 {
+		return nil
+	}
+	for i := 0; i < db.retry; i++ {
+		err = db.saveCandles(binSize, candles)
+		if err == sqlite3.ErrBusy {
+			db.log.Warnf("db is busy, wait to next")
+			time.Sleep(1 * time.Second)
+			continue
+		}
+		break
+	}
+	return err
+}
+
+func (db *DB) saveCandles(binSize models.BinSize, candles []bitmex.TradeBuck) (err error) {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			txErr := tx.Rollback()
+			if txErr != nil {
+				db.log.Errorf("save candles rollback tx failed: %v", txErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var updatedAt = time.Now().Unix()
+	for _, candle := range candles {
+		var candleM *models.Candle
+		candleM, err = models.ToCandleModel(binSize, candle)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO candles(
+                    symbol,
+                    bin,
+                    timestamp,
+                    open,
+                    high,
+                    low,
+                    close,
+                    trades,
+                    volume,
+                    vwap,
+                    lastsize,
+                    turnover,
+                    homenotional,
+                    foreignnotional,
+                    created_at,
+                    updated_at
+	) VALUES (
+	          $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15
+	) ON CONFLICT (symbol, bin, timestamp) DO UPDATE SET
+	          open=excluded.open,
+	          high=excluded.high,
+	          low=excluded.low,
+	          close=excluded.close,
+	          trades=excluded.trades,
+	          volume=excluded.volume,
+	          vwap=excluded.vwap,
+	          lastsize=excluded.lastsize,
+	          turnover=excluded.turnover,
+	          homenotional=excluded.homenotional,
+	          foreignnotional=excluded.foreignnotional,
+	          updated_at=excluded.updated_at`,
+			candleM.Symbol,
+			binSize.String(),
+			candleM.Timestamp.Format(candleTimestampLayout),
+			candleM.Open,
+			candleM.High,
+			candleM.Low,
+			candleM.Close,
+			candleM.Trades,
+			candleM.Volume,
+			candleM.Vwap,
+			candleM.LastSize,
+			candleM.Turnover,
+			candleM.HomeNotional,
+			candleM.ForeignNotional,
+			updatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCandles returns saved candles of the symbol and the bin size with timestamps in [from, to],
+// ordered by timestamp
+func (db *DB) GetCandles(symbol string, binSize models.BinSize, from, to time.Time) ([]*models.Candle, error) {
+	var result []*models.Candle
+	err := sqlx.Select(db.ql, &result,
+		`SELECT * FROM candles WHERE symbol=$1 AND bin=$2 AND timestamp>=$3 AND timestamp<=$4 ORDER BY timestamp`,
+		symbol,
+		binSize.String(),
+		from.UTC().Format(candleTimestampLayout),
+		to.UTC().Format(candleTimestampLayout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	db.log.Debugf("GetCandles SQL RESULT: %d candles", len(result))
+	return result, nil
+}
@@ -270,8 +270,15 @@ func saveStrategies(tx *sqlx.Tx, globalConfigID int, settings models.StrategiesC
                               macd_fast_count,
                               macd_slow_count,
                               macd_sig_count,
+                              bb_period,
+                              bb_deviation,
+                              bb_ma_type,
+                              macd_fast_ma_type,
+                              macd_slow_ma_type,
+                              macd_sig_ma_type,
                               global_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21,
+			$22, $23)`,
 			settings.Bin.String(),
 			settings.EnableRSIBB,
 			settings.RetryProcessCount,
@@ -288,6 +295,12 @@ func saveStrategies(tx *sqlx.Tx, globalConfigID int, settings models.StrategiesC
 			settings.MacdFastCount,
 			settings.MacdSlowCount,
 			settings.MacdSigCount,
+			settings.BBPeriod,
+			settings.BBDeviation,
+			settings.BBMAType,
+			settings.MacdFastMAType,
+			settings.MacdSlowMAType,
+			settings.MacdSigMAType,
 			globalConfigID)
 		_, err := res.LastInsertId()
 		if err != nil {
@@ -313,7 +326,13 @@ func saveStrategies(tx *sqlx.Tx, globalConfigID int, settings models.StrategiesC
                               rsi_trade_coef=$13,
                               macd_fast_count=$14,
                               macd_slow_count=$15,
-                              macd_sig_count=$16 WHERE global_id=$17`,
+                              macd_sig_count=$16,
+                              bb_period=$17,
+                              bb_deviation=$18,
+                              bb_ma_type=$19,
+                              macd_fast_ma_type=$20,
+                              macd_slow_ma_type=$21,
+                              macd_sig_ma_type=$22 WHERE global_id=$23`,
 		settings.Bin.String(),
 		settings.EnableRSIBB,
 		settings.RetryProcessCount,
@@ -330,6 +349,12 @@ func saveStrategies(tx *sqlx.Tx, globalConfigID int, settings models.StrategiesC
 		settings.MacdFastCount,
 		settings.MacdSlowCount,
 		settings.MacdSigCount,
+		settings.BBPeriod,
+		settings.BBDeviation,
+		settings.BBMAType,
+		settings.MacdFastMAType,
+		settings.MacdSlowMAType,
+		settings.MacdSigMAType,
 		globalConfigID)
 	_, err = res.LastInsertId()
 	if err != nil {
@@ -447,7 +472,8 @@ func (db *DB) getConfiguration() (*models.GlobalConfig, error) {
        sc.id, sc.bin, sc.enable_rsi_bb, sc.retry_process_count, sc.get_candles_count, sc.trend_filter_enable,
        sc.candles_filter_enable, sc.max_filter_trend_count, sc.max_candles_filter_count, sc.bb_last_candles_count,
        sc.rsi_count, sc.rsi_min_border, sc.rsi_max_border, sc.rsi_trade_coef, sc.macd_fast_count, sc.macd_slow_count,
-       sc.macd_sig_count
+       sc.macd_sig_count, sc.bb_period, sc.bb_deviation, sc.bb_ma_type, sc.macd_fast_ma_type,
+       sc.macd_slow_ma_type, sc.macd_sig_ma_type
 	FROM global_config as gc
 		CROSS JOIN  admin as a ON gc.admin_id=a.id AND a.global_id=gc.id
 		CROSS JOIN exchanges_api_settings as eas ON eas.global_id=gc.id
@@ -486,7 +512,10 @@ func (db *DB) getConfiguration() (*models.GlobalConfig, error) {
 		&globalConfig.StrategiesConfig.RsiCount, &globalConfig.StrategiesConfig.RsiMinBorder,
 		&globalConfig.StrategiesConfig.RsiMaxBorder, &globalConfig.StrategiesConfig.RsiTradeCoef,
 		&globalConfig.StrategiesConfig.MacdFastCount, &globalConfig.StrategiesConfig.MacdSlowCount,
-		&globalConfig.StrategiesConfig.MacdSigCount,
+		&globalConfig.StrategiesConfig.MacdSigCount, &globalConfig.StrategiesConfig.BBPeriod,
+		&globalConfig.StrategiesConfig.BBDeviation, &globalConfig.StrategiesConfig.BBMAType,
+		&globalConfig.StrategiesConfig.MacdFastMAType, &globalConfig.StrategiesConfig.MacdSlowMAType,
+		&globalConfig.StrategiesConfig.MacdSigMAType,
 	)
 	if err != nil {
 		return nil, err
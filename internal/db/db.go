@@ -22,9 +22,12 @@ type DatabaseManager interface {
 	Close() error
 
 	// Candles
-	//SaveCandle(candle *models.Candle) (int64, error)
-	//GetCandlesByTimestamp(from, to time.Time) ([]*models.Candle, error)
-	//GetPreviousCandlesByTheme(theme types.Theme, from, to time.Time, limit int) ([]*models.Candle, error)
+	SaveCandles(binSize models.BinSize, candles []bitmex.TradeBuck) error
+	GetCandles(symbol string, binSize models.BinSize, from, to time.Time) ([]*models.Candle, error)
+
+	// Indicators snapshots
+	SaveIndicatorsSnapshot(snapshot models.IndicatorsSnapshot) error
+	GetIndicatorsSnapshot(symbol string, binSize models.BinSize) (*models.IndicatorsSnapshot, error)
 
 	// Signals
 	SaveSignal(data models.Signal) (int64, error)
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+)
+
+// SaveIndicatorsSnapshot saves snapshot of the symbol and the bin size, the previous snapshot is replaced
+func (db *DB) SaveIndicatorsSnapshot(snapshot models.IndicatorsSnapshot) (err error) {
+	for i := 0; i < db.retry; i++ {
+		err = db.saveIndicatorsSnapshot(snapshot)
+		if err == sqlite3.ErrBusy {
+			db.log.Warnf("db is busy, wait to next")
+			time.Sleep(1 * time.Second)
+			continue
+		}
+		break
+	}
+	return err
+}
+
+func (db *DB) saveIndicatorsSnapshot(snapshot models.IndicatorsSnapshot) error {
+	var updatedAt = time.Now().Unix()
+	_, err := db.ql.Exec(`INSERT INTO indicator_snapshots(
+                    symbol,
+                    bin,
+                    timestamp,
+                    data,
+                    created_at,
+                    updated_at
+	) VALUES (
+	          $1, $2, $3, $4, $5, $5
+	) ON CONFLICT (symbol, bin) DO UPDATE SET
+	          timestamp=excluded.timestamp,
+	          data=excluded.data,
+	          updated_at=excluded.updated_at`,
+		snapshot.Symbol,
+		snapshot.BinSize.String(),
+		snapshot.Timestamp.UTC().Format(candleTimestampLayout),
+		snapshot.Data,
+		updatedAt,
+	)
+	return err
+}
+
+// GetIndicatorsSnapshot returns saved snapshot of the symbol and the bin size, nil if it is not saved
+func (db *DB) GetIndicatorsSnapshot(symbol string, binSize models.BinSize) (*models.IndicatorsSnapshot, error) {
+	var result []*models.IndicatorsSnapshot
+	err := sqlx.Select(db.ql, &result,
+		`SELECT * FROM indicator_snapshots WHERE symbol=$1 AND bin=$2 LIMIT 1`,
+		symbol,
+		binSize.String(),
+	)
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return result[0], nil
+}
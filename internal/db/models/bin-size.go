@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql/driver"
 	"fmt"
+	"time"
 )
 
 type BinSize uint8
@@ -13,21 +14,52 @@ const (
 	Bin5m
 	Bin1h
 	Bin1d
+	Bin15m
+	Bin30m
+	Bin4h
+	Bin1w
 )
 
+type binSizeInfo struct {
+	name     string
+	duration time.Duration
+	source   BinSize // bin size streamed by bitmex which candles are aggregated, 0 for streamed bin sizes
+}
+
+// binSizes supported bin sizes, a new bin size is added by a new row here and a row in bin_size table
+var binSizes = map[BinSize]binSizeInfo{
+	Bin1m:  {name: "1m", duration: time.Minute},
+	Bin5m:  {name: "5m", duration: 5 * time.Minute},
+	Bin1h:  {name: "1h", duration: time.Hour},
+	Bin1d:  {name: "1d", duration: 24 * time.Hour},
+	Bin15m: {name: "15m", duration: 15 * time.Minute, source: Bin5m},
+	Bin30m: {name: "30m", duration: 30 * time.Minute, source: Bin5m},
+	Bin4h:  {name: "4h", duration: 4 * time.Hour, source: Bin1h},
+	Bin1w:  {name: "1w", duration: 7 * 24 * time.Hour, source: Bin1d},
+}
+
 func (b BinSize) String() string {
-	switch b {
-	case Bin1m:
-		return "1m"
-	case Bin5m:
-		return "5m"
-	case Bin1h:
-		return "1h"
-	case Bin1d:
-		return "1d"
-	default:
-		return ""
+	return binSizes[b].name
+}
+
+// Duration returns candle duration, 0 for unknown bin size
+func (b BinSize) Duration() time.Duration {
+	return binSizes[b].duration
+}
+
+// IsResampled returns true if candles of the bin size are not streamed by bitmex
+// and are built by aggregating candles of the source bin size
+func (b BinSize) IsResampled() bool {
+	return binSizes[b].source != 0
+}
+
+// Source returns bin size which candles are streamed by bitmex and used for building this bin size candles,
+// for streamed bin sizes returns the bin size itself
+func (b BinSize) Source() BinSize {
+	if b.IsResampled() {
+		return binSizes[b].source
 	}
+	return b
 }
 
 func (b *BinSize) Scan(value interface{}) error {
@@ -45,16 +77,10 @@ func (b *BinSize) Value() (driver.Value, error) {
 }
 
 func ToBinSize(binSize string) (BinSize, error) {
-	switch binSize {
-	case "1m":
-		return Bin1m, nil
-	case "5m":
-		return Bin5m, nil
-	case "1h":
-		return Bin1h, nil
-	case "1d":
-		return Bin1d, nil
-	default:
-		return 0, fmt.Errorf("unknown bin_size:%s", binSize)
+	for bin, info := range binSizes {
+		if info.name == binSize {
+			return bin, nil
+		}
 	}
+	return 0, fmt.Errorf("unknown bin_size:%s", binSize)
 }
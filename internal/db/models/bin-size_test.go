@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToBinSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		want         BinSize
+		wantSource   BinSize
+		wantDuration time.Duration
+		wantErr      bool
+	}{
+		{name: "1m", want: Bin1m, wantSource: Bin1m, wantDuration: time.Minute},
+		{name: "1d", want: Bin1d, wantSource: Bin1d, wantDuration: 24 * time.Hour},
+		{name: "15m", want: Bin15m, wantSource: Bin5m, wantDuration: 15 * time.Minute},
+		{name: "4h", want: Bin4h, wantSource: Bin1h, wantDuration: 4 * time.Hour},
+		{name: "1w", want: Bin1w, wantSource: Bin1d, wantDuration: 7 * 24 * time.Hour},
+		{name: "2m", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToBinSize(tt.name)
+			require.Equal(t, tt.wantErr, err != nil, "error", err)
+			if tt.wantErr {
+				return
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.name, got.String())
+			require.Equal(t, tt.wantSource, got.Source())
+			require.Equal(t, tt.wantSource != tt.want, got.IsResampled())
+			require.Equal(t, tt.wantDuration, got.Duration())
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
 
 type Candle struct {
 	ID              int64     `db:"id" json:"id"`
-	Theme           string    `db:"theme" json:"theme"`
 	Symbol          string    `db:"symbol"`
+	BinSize         BinSize   `db:"bin"`
 	Timestamp       time.Time `db:"timestamp"`
 	Open            float64   `db:"open"`
 	High            float64   `db:"high"`
@@ -21,3 +26,45 @@ type Candle struct {
 	CreatedAt       int64     `db:"created_at"`
 	UpdatedAt       int64     `db:"updated_at"`
 }
+
+func ToCandleModel(binSize BinSize, candle bitmex.TradeBuck) (*Candle, error) {
+	timestamp, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candle.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return &Candle{
+		Symbol:          candle.Symbol,
+		BinSize:         binSize,
+		Timestamp:       timestamp.UTC(),
+		Open:            candle.Open,
+		High:            candle.High,
+		Low:             candle.Low,
+		Close:           candle.Close,
+		Trades:          candle.Trades,
+		Volume:          candle.Volume,
+		Vwap:            candle.Vwap,
+		LastSize:        candle.LastSize,
+		Turnover:        candle.Turnover,
+		HomeNotional:    candle.HomeNotional,
+		ForeignNotional: candle.ForeignNotional,
+	}, nil
+}
+
+// ToTradeBuck converts stored candle to bitmex candle
+func (c *Candle) ToTradeBuck() bitmex.TradeBuck {
+	return bitmex.TradeBuck{
+		Symbol:          c.Symbol,
+		Timestamp:       c.Timestamp.UTC().Format(tradeapi.TradeBucketedTimestampLayout),
+		HomeNotional:    c.HomeNotional,
+		ForeignNotional: c.ForeignNotional,
+		Open:            c.Open,
+		High:            c.High,
+		Low:             c.Low,
+		Close:           c.Close,
+		Trades:          c.Trades,
+		Volume:          c.Volume,
+		LastSize:        c.LastSize,
+		Turnover:        c.Turnover,
+		Vwap:            c.Vwap,
+	}
+}
@@ -91,6 +91,13 @@ type StrategiesConfig struct {
 	MacdSlowCount int `json:"macd_slow_count" db:"macd_slow_count"`
 	MacdSigCount  int `json:"macd_sig_count" db:"macd_sig_count"`
 
+	BBPeriod       int     `json:"bb_period" db:"bb_period"`
+	BBDeviation    float64 `json:"bb_deviation" db:"bb_deviation"`
+	BBMAType       string  `json:"bb_ma_type" db:"bb_ma_type"`
+	MacdFastMAType string  `json:"macd_fast_ma_type" db:"macd_fast_ma_type"`
+	MacdSlowMAType string  `json:"macd_slow_ma_type" db:"macd_slow_ma_type"`
+	MacdSigMAType  string  `json:"macd_sig_ma_type" db:"macd_sig_ma_type"`
+
 	GlobalID int `json:"global_id" db:"global_id"`
 
 	RsiTradeCoef float64 `json:"rsi_trade_coef" db:"rsi_trade_coef"`
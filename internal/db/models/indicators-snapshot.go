@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IndicatorsSnapshot saved state of the streaming indicators of the symbol and the bin size
+type IndicatorsSnapshot struct {
+	ID        int64     `db:"id"`
+	Symbol    string    `db:"symbol"`
+	BinSize   BinSize   `db:"bin"`
+	Timestamp time.Time `db:"timestamp"` // timestamp of the last candle added to the indicators
+	Data      string    `db:"data"`      // indicators state in json
+	CreatedAt int64     `db:"created_at"`
+	UpdatedAt int64     `db:"updated_at"`
+}
@@ -50,7 +50,7 @@ func (db *DB) SaveOrder(ord bitmex.OrderCopied) (*models.Order, error) {
 				    $27, $28, $29, $30, $31, $32, $33, $34, $35
 				) RETURNING id`,
 		ord.Account,
-		ord.AvgPx,
+		order.AvgPx,
 		ord.ClOrdID,
 		ord.ClOrdLinkID,
 		ord.ContingencyType,
@@ -66,15 +66,15 @@ func (db *DB) SaveOrder(ord bitmex.OrderCopied) (*models.Order, error) {
 		ord.OrdType,
 		ord.OrderID,
 		ord.OrderQty,
-		ord.PegOffsetValue,
+		order.PegOffsetValue,
 		ord.PegPriceType,
-		ord.Price,
+		order.Price,
 		ord.SettlCurrency,
 		ord.Side,
-		ord.SimpleCumQty,
-		ord.SimpleLeavesQty,
-		ord.SimpleOrderQty,
-		ord.StopPx,
+		order.SimpleCumQty,
+		order.SimpleLeavesQty,
+		order.SimpleOrderQty,
+		order.StopPx,
 		ord.Symbol,
 		ord.Text,
 		ord.TimeInForce,
@@ -0,0 +1,169 @@
+// Package lifecycle owns the application root context and stops application components
+// one by one in the order they were registered.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultShutdownTimeout = 30 * time.Second
+
+// component is a long-running loop started by Go or a stop hook registered by OnStop
+type component struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+	stop   func(ctx context.Context) error
+}
+
+// Manager starts application components and stops them in registration order
+// when the process receives SIGINT/SIGTERM or Stop is called
+type Manager struct {
+	log     *logrus.Logger
+	timeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stopping context.Context
+	stop     context.CancelFunc
+
+	mx         sync.Mutex
+	components []*component
+}
+
+// New creates manager, timeout limits the whole shutdown duration
+func New(log *logrus.Logger, timeout time.Duration) *Manager {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopping, stop := context.WithCancel(ctx)
+	return &Manager{
+		log:      log,
+		timeout:  timeout,
+		ctx:      ctx,
+		cancel:   cancel,
+		stopping: stopping,
+		stop:     stop,
+	}
+}
+
+// Context returns context which is done when shutdown begins
+func (m *Manager) Context() context.Context {
+	return m.stopping
+}
+
+// Go runs component loop in a new goroutine.
+// Loop context is derived from the root context and is cancelled on the component turn in the shutdown order,
+// loop must return after that. Loop return before its turn is unexpected and begins shutdown
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	c := &component{
+		name:   name,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.add(c)
+
+	go func() {
+		defer close(c.done)
+		run(ctx)
+		if ctx.Err() == nil {
+			m.log.Errorf("component %s returned unexpectedly, shutdown started", name)
+			m.Stop()
+		}
+	}()
+}
+
+// OnStop registers hook which is called on its turn in the shutdown order
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error) {
+	m.add(&component{
+		name: name,
+		stop: stop,
+	})
+}
+
+// Stop begins shutdown
+func (m *Manager) Stop() {
+	m.stop()
+}
+
+// Wait blocks until SIGINT/SIGTERM is received or Stop is called, then stops components.
+// Returned error lists components which failed to stop in time
+func (m *Manager) Wait() error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sig)
+
+	select {
+	case s := <-sig:
+		m.log.Infof("signal %v received, shutdown started", s)
+		m.stop()
+	case <-m.stopping.Done():
+		m.log.Infof("shutdown started")
+	}
+
+	return m.shutdown()
+}
+
+// shutdown stops components in registration order and cancels the root context at the end,
+// so components which failed to stop in time are cancelled too
+func (m *Manager) shutdown() error {
+	defer m.cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	m.mx.Lock()
+	components := m.components
+	m.mx.Unlock()
+
+	var failed []string
+	for _, c := range components {
+		err := m.stopComponent(ctx, c)
+		if err != nil {
+			m.log.Errorf("component %s stop failed: %v", c.name, err)
+			failed = append(failed, fmt.Sprintf("%s: %v", c.name, err))
+			continue
+		}
+		m.log.Infof("component %s stopped", c.name)
+	}
+
+	if len(failed) != 0 {
+		return errors.New("shutdown failed - " + strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (m *Manager) stopComponent(ctx context.Context, c *component) error {
+	if c.stop != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return c.stop(ctx)
+	}
+
+	c.cancel()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) add(c *component) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.components = append(m.components, c)
+}
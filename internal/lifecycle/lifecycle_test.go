@@ -0,0 +1,103 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Wait(t *testing.T) {
+	m := New(logrus.New(), time.Second)
+
+	var (
+		mx      sync.Mutex
+		stopped []string
+	)
+	appendStopped := func(name string) {
+		mx.Lock()
+		defer mx.Unlock()
+		stopped = append(stopped, name)
+	}
+	for _, name := range []string{"first", "second"} {
+		name := name
+		m.Go(name, func(ctx context.Context) {
+			<-ctx.Done()
+			appendStopped(name)
+		})
+	}
+	m.OnStop("hook", func(ctx context.Context) error {
+		appendStopped("hook")
+		return nil
+	})
+	m.Go("last", func(ctx context.Context) {
+		<-ctx.Done()
+		appendStopped("last")
+	})
+
+	m.Stop()
+	require.NoError(t, m.Wait())
+	require.Equal(t, []string{"first", "second", "hook", "last"}, stopped)
+	require.Error(t, m.Context().Err())
+}
+
+func TestManager_Wait_failed(t *testing.T) {
+	m := New(logrus.New(), 50*time.Millisecond)
+
+	hang := make(chan struct{})
+	defer close(hang)
+	m.Go("hung", func(ctx context.Context) {
+		<-hang
+	})
+	m.OnStop("hook", func(ctx context.Context) error {
+		return nil
+	})
+
+	m.Stop()
+	err := m.Wait()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hung: context deadline exceeded")
+	require.Contains(t, err.Error(), "hook: context deadline exceeded")
+}
+
+func TestManager_Wait_hookError(t *testing.T) {
+	m := New(logrus.New(), time.Second)
+	m.OnStop("db", func(ctx context.Context) error {
+		return errors.New("close failed")
+	})
+	m.Go("done", func(ctx context.Context) {})
+
+	m.Stop()
+	err := m.Wait()
+	require.EqualError(t, err, "shutdown failed - db: close failed")
+}
+
+func TestManager_Wait_unexpectedReturn(t *testing.T) {
+	m := New(logrus.New(), time.Second)
+
+	var (
+		mx      sync.Mutex
+		stopped []string
+	)
+	m.Go("loop", func(ctx context.Context) {
+		<-ctx.Done()
+		mx.Lock()
+		defer mx.Unlock()
+		stopped = append(stopped, "loop")
+	})
+	m.OnStop("hook", func(ctx context.Context) error {
+		mx.Lock()
+		defer mx.Unlock()
+		stopped = append(stopped, "hook")
+		return nil
+	})
+	m.Go("ws", func(ctx context.Context) {})
+
+	require.NoError(t, m.Wait())
+	require.Equal(t, []string{"loop", "hook"}, stopped)
+	require.Error(t, m.Context().Err())
+}
@@ -0,0 +1,87 @@
+package orderproc
+
+import (
+	"errors"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// GetInstrument returns prices and contract specification of the configured symbol
+func (o *OrderProcessor) GetInstrument() (bitmex.Instrument, error) {
+	cfg, err := o.configurator.GetConfig()
+	if err != nil {
+		o.log.Fatal(err)
+	}
+	return o.getInstrument(cfg)
+}
+
+// PlaceLimitOrder places passive limit order of the side by the price, the order is not placed
+// if the position reached LimitContractsCount or available balance does not cover its initial margin and fee
+func (o *OrderProcessor) PlaceLimitOrder(
+	side types.Side, qty int64, price money.Decimal, clOrdID string,
+) (bitmex.OrderCopied, error) {
+	cfg, err := o.configurator.GetConfig()
+	if err != nil {
+		o.log.Fatal(err)
+	}
+	if qty <= 0 || price.Sign() <= 0 {
+		return bitmex.OrderCopied{}, errors.New("order quantity and price must be positive")
+	}
+	err = o.checkLimitContracts(cfg, side)
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	_, availableBalance, err := o.GetBalance()
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	contract, err := o.GetContract()
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	err = o.checkBalance(contract, side, price, qty, o.leverage(), availableBalance, true)
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+
+	params := &bitmex.OrderNewParams{
+		ClientOrderID: clOrdID,
+		Symbol:        cfg.ExchangesSettings.Bitmex.Symbol,
+		Side:          string(side),
+		OrderType:     string(types.Limit),
+		OrderQty:      float64(qty),
+		Price:         price.Float64(),
+		ExecInst:      string(types.PassiveOrderExecInstType),
+	}
+	o.log.Infof("create limit order params: %#v", params)
+	ord, err := o.api.GetBitmex().CreateOrder(params)
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	o.storeOrder(ord)
+	return ord, nil
+}
+
+// AmendOrder amends price or quantity of the open order
+func (o *OrderProcessor) AmendOrder(params *bitmex.OrderAmendParams) (bitmex.OrderCopied, error) {
+	ord, err := o.api.GetBitmex().AmendOrder(params)
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	o.storeOrder(ord)
+	return ord, nil
+}
+
+// CancelOrder cancels the open order by id
+func (o *OrderProcessor) CancelOrder(orderID, text string) error {
+	orders, err := o.api.GetBitmex().CancelOrders(&bitmex.OrderCancelParams{OrderID: orderID, Text: text})
+	if err != nil {
+		return err
+	}
+	for _, ord := range orders {
+		o.storeOrder(ord)
+	}
+	return nil
+}
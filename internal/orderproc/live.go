@@ -0,0 +1,393 @@
+package orderproc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+const (
+	maxFills       = 100
+	maxClosed      = 1000
+	execTypeTrade  = "Trade"
+	addedLiquidity = "AddedLiquidity"
+)
+
+// liveState account state received from bitmex private websocket streams,
+// REST api is used while the state is not synced
+type liveState struct {
+	ordersSynced bool
+	orders       map[string]bitmex.OrderCopied // active orders by order id
+	margins      map[string]data.Margin        // by currency
+	wallets      map[string]data.Wallet        // by currency
+	fills        []data.Execution              // last fills
+	instruments  map[string]bitmex.Instrument  // by symbol, received by partial
+	// closed ids of the last maxClosed orders closed by order stream, closedIDs in order of closing
+	closed    map[string]struct{}
+	closedIDs []string
+}
+
+func newLiveState() liveState {
+	return liveState{
+		orders:      make(map[string]bitmex.OrderCopied),
+		margins:     make(map[string]data.Margin),
+		wallets:     make(map[string]data.Wallet),
+		instruments: make(map[string]bitmex.Instrument),
+		closed:      make(map[string]struct{}),
+	}
+}
+
+// closeOrder removes the order and remembers it as closed, the oldest closed orders are forgotten
+func (s *liveState) closeOrder(orderID string) {
+	delete(s.orders, orderID)
+	if _, ok := s.closed[orderID]; ok {
+		return
+	}
+	s.closed[orderID] = struct{}{}
+	s.closedIDs = append(s.closedIDs, orderID)
+	if len(s.closedIDs) > maxClosed {
+		delete(s.closed, s.closedIDs[0])
+		s.closedIDs = s.closedIDs[1:]
+	}
+}
+
+// Start processes order, execution, margin, wallet and instrument messages from bitmex websocket until ctx is done
+func (o *OrderProcessor) Start(ctx context.Context) {
+	o.log.Infof("order processor started")
+	defer o.log.Infof("order processor finished")
+	if o.subscriber == nil {
+		o.log.Warnf("order processor subscriber not installed, only REST api is used")
+		<-ctx.Done()
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-o.subscriber.GetMsgChan():
+			o.processEvent(event)
+		}
+	}
+}
+
+func (o *OrderProcessor) processEvent(event data.Event) {
+	o.liveMx.Lock()
+	defer o.liveMx.Unlock()
+	switch e := event.(type) {
+	case *data.OrderEvent:
+		o.updateOrders(data.Action(e.Action), e.Data)
+	case *data.ExecutionEvent:
+		o.addFills(e.Data)
+	case *data.MarginEvent:
+		o.updateMargins(data.Action(e.Action), e.Data)
+	case *data.WalletEvent:
+		o.updateWallets(data.Action(e.Action), e.Data)
+	case *data.InstrumentEvent:
+		o.updateInstruments(data.Action(e.Action), e.Data)
+	default:
+		o.log.Warnf("order processor is not supported this table: %v", event.GetTable())
+	}
+}
+
+func (o *OrderProcessor) updateOrders(action data.Action, orders []data.Order) {
+	if action == data.Partial {
+		o.live.orders = make(map[string]bitmex.OrderCopied)
+		o.live.ordersSynced = true
+	}
+	for _, row := range orders {
+		if action == data.Delete {
+			o.live.closeOrder(row.OrderID)
+			continue
+		}
+		ord := o.live.orders[row.OrderID]
+		mergeOrder(&ord, row)
+		if !isActiveOrder(ord) {
+			o.log.Debugf("order %s is not active now, status: %s", ord.OrderID, ord.OrdStatus)
+			o.live.closeOrder(row.OrderID)
+			continue
+		}
+		o.live.orders[row.OrderID] = ord
+	}
+}
+
+func (o *OrderProcessor) addFills(executions []data.Execution) {
+	for _, exec := range executions {
+		if exec.ExecType != execTypeTrade {
+			continue
+		}
+		o.log.Infof("order %s filled: %s %d@%v, left: %d",
+			exec.OrderID, exec.Side, exec.LastQty, exec.LastPx, exec.LeavesQty)
+		o.live.fills = append(o.live.fills, exec)
+	}
+	if len(o.live.fills) > maxFills {
+		o.live.fills = o.live.fills[len(o.live.fills)-maxFills:]
+	}
+}
+
+func (o *OrderProcessor) updateMargins(action data.Action, margins []data.Margin) {
+	if action == data.Partial {
+		o.live.margins = make(map[string]data.Margin)
+	}
+	for _, row := range margins {
+		margin, ok := o.live.margins[row.Currency]
+		if !ok || action == data.Partial {
+			o.live.margins[row.Currency] = row
+			continue
+		}
+		if row.WalletBalance != nil {
+			margin.WalletBalance = row.WalletBalance
+		}
+		if row.MarginBalance != nil {
+			margin.MarginBalance = row.MarginBalance
+		}
+		if row.AvailableMargin != nil {
+			margin.AvailableMargin = row.AvailableMargin
+		}
+		if row.WithdrawableMargin != nil {
+			margin.WithdrawableMargin = row.WithdrawableMargin
+		}
+		if !row.Timestamp.IsZero() {
+			margin.Timestamp = row.Timestamp
+		}
+		o.live.margins[row.Currency] = margin
+	}
+}
+
+func (o *OrderProcessor) updateWallets(action data.Action, wallets []data.Wallet) {
+	if action == data.Partial {
+		o.live.wallets = make(map[string]data.Wallet)
+	}
+	for _, row := range wallets {
+		wallet, ok := o.live.wallets[row.Currency]
+		if !ok || row.Amount != nil {
+			wallet.Amount = row.Amount
+		}
+		wallet.Account = row.Account
+		wallet.Currency = row.Currency
+		if !row.Timestamp.IsZero() {
+			wallet.Timestamp = row.Timestamp
+		}
+		o.live.wallets[row.Currency] = wallet
+	}
+}
+
+// updateInstruments keeps instruments received by partial, update rows contain only changed fields,
+// so only non zero prices are applied
+func (o *OrderProcessor) updateInstruments(action data.Action, instruments []data.Instrument) {
+	if action == data.Partial {
+		o.live.instruments = make(map[string]bitmex.Instrument)
+	}
+	for _, row := range instruments {
+		inst, ok := o.live.instruments[string(row.Symbol)]
+		if !ok && action != data.Partial {
+			continue
+		}
+		if action == data.Partial {
+			inst = bitmex.Instrument{
+				Symbol:        string(row.Symbol),
+				State:         row.State,
+				Typ:           row.Typ,
+				QuoteCurrency: row.QuoteCurrency,
+				SettlCurrency: row.SettlCurrency,
+				IsInverse:     row.IsInverse,
+				IsQuanto:      row.IsQuanto,
+				Multiplier:    row.Multiplier,
+				LotSize:       row.LotSize,
+				TickSize:      row.TickSize,
+				MakerFee:      row.MakerFee,
+				TakerFee:      row.TakerFee,
+			}
+		}
+		mergeInstrumentPrices(&inst, row)
+		o.live.instruments[string(row.Symbol)] = inst
+	}
+}
+
+func mergeInstrumentPrices(inst *bitmex.Instrument, row data.Instrument) {
+	if row.LastPrice != 0 {
+		inst.LastPrice = row.LastPrice
+	}
+	if row.BidPrice != 0 {
+		inst.BidPrice = row.BidPrice
+	}
+	if row.AskPrice != 0 {
+		inst.AskPrice = row.AskPrice
+	}
+	if row.MidPrice != 0 {
+		inst.MidPrice = row.MidPrice
+	}
+	if row.MarkPrice != 0 {
+		inst.MarkPrice = row.MarkPrice
+	}
+	if row.FairPrice != 0 {
+		inst.FairPrice = row.FairPrice
+	}
+	if row.FundingRate != 0 {
+		inst.FundingRate = row.FundingRate
+	}
+	if !row.Timestamp.IsZero() {
+		inst.Timestamp = row.Timestamp
+	}
+}
+
+// liveInstrument returns instrument from websocket instrument stream, ok is false if partial is not received yet
+func (o *OrderProcessor) liveInstrument(symbol string) (bitmex.Instrument, bool) {
+	o.liveMx.Lock()
+	defer o.liveMx.Unlock()
+	inst, ok := o.live.instruments[symbol]
+	return inst, ok && inst.BidPrice != 0 && inst.AskPrice != 0
+}
+
+// liveBalance returns balance from websocket margin stream, ok is false if balance not received yet
+func (o *OrderProcessor) liveBalance(currency string) (walletBalance, availableBalance money.Satoshi, ok bool) {
+	o.liveMx.Lock()
+	defer o.liveMx.Unlock()
+	margin, exist := o.live.margins[currency]
+	if !exist || margin.AvailableMargin == nil {
+		return 0, 0, false
+	}
+	switch {
+	case margin.WalletBalance != nil:
+		walletBalance = money.Satoshi(*margin.WalletBalance)
+	case o.live.wallets[currency].Amount != nil:
+		walletBalance = money.Satoshi(*o.live.wallets[currency].Amount)
+	default:
+		return 0, 0, false
+	}
+	return walletBalance, money.Satoshi(*margin.AvailableMargin), true
+}
+
+// GetActiveOrders returns active orders by symbol from websocket order stream,
+// before order stream is synced active orders are requested by REST api
+func (o *OrderProcessor) GetActiveOrders(symbol string) ([]bitmex.OrderCopied, error) {
+	o.liveMx.Lock()
+	if o.live.ordersSynced {
+		var result = make([]bitmex.OrderCopied, 0, len(o.live.orders))
+		for _, ord := range o.live.orders {
+			if ord.Symbol == symbol {
+				result = append(result, ord)
+			}
+		}
+		o.liveMx.Unlock()
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Timestamp.Before(result[j].Timestamp)
+		})
+		return result, nil
+	}
+	o.liveMx.Unlock()
+
+	filter := fmt.Sprintf(`{"open": %t}`, true)
+	return o.api.GetBitmex().GetOrders(&bitmex.OrdersRequest{
+		Symbol: symbol,
+		Filter: filter,
+	})
+}
+
+// GetFills returns last fills of orders
+func (o *OrderProcessor) GetFills() []data.Execution {
+	o.liveMx.Lock()
+	defer o.liveMx.Unlock()
+	return append([]data.Execution{}, o.live.fills...)
+}
+
+// LastFillMaker returns true if the last fill of the side added liquidity and was charged by maker fee,
+// ok is false if there is no fill of the side
+func (o *OrderProcessor) LastFillMaker(symbol string, side types.Side) (maker, ok bool) {
+	o.liveMx.Lock()
+	defer o.liveMx.Unlock()
+	for i := len(o.live.fills) - 1; i >= 0; i-- {
+		exec := o.live.fills[i]
+		if exec.Symbol == types.Symbol(symbol) && exec.Side == string(side) {
+			return exec.LastLiquidityInd == addedLiquidity, true
+		}
+	}
+	return false, false
+}
+
+// storeOrder stores placed or amended order before it comes from order stream,
+// not active order is removed. Response is skipped if order stream has already closed the order
+// or has sent newer order state
+func (o *OrderProcessor) storeOrder(ord bitmex.OrderCopied) {
+	o.liveMx.Lock()
+	defer o.liveMx.Unlock()
+	if !o.live.ordersSynced {
+		return
+	}
+	if _, ok := o.live.closed[ord.OrderID]; ok {
+		return
+	}
+	if stored, ok := o.live.orders[ord.OrderID]; ok && stored.Timestamp.After(ord.Timestamp) {
+		return
+	}
+	if !isActiveOrder(ord) {
+		o.live.closeOrder(ord.OrderID)
+		return
+	}
+	o.live.orders[ord.OrderID] = ord
+}
+
+func isActiveOrder(ord bitmex.OrderCopied) bool {
+	return ord.OrdStatus == string(types.OrdNew) || ord.OrdStatus == string(types.OrdPartiallyFilled)
+}
+
+// mergeOrder updates order by fields present in order stream row, zero quantities and prices are applied
+func mergeOrder(ord *bitmex.OrderCopied, row data.Order) {
+	ord.OrderID = row.OrderID
+	if row.ClOrdID != "" {
+		ord.ClOrdID = row.ClOrdID
+	}
+	if row.Account != 0 {
+		ord.Account = row.Account
+	}
+	if row.Symbol != "" {
+		ord.Symbol = string(row.Symbol)
+	}
+	if row.Side != "" {
+		ord.Side = row.Side
+	}
+	if row.OrderQty != nil {
+		ord.OrderQty = *row.OrderQty
+	}
+	if row.Price != nil {
+		ord.Price = *row.Price
+	}
+	if row.StopPx != nil {
+		ord.StopPx = *row.StopPx
+	}
+	if row.OrdType != "" {
+		ord.OrdType = row.OrdType
+	}
+	if row.TimeInForce != "" {
+		ord.TimeInForce = row.TimeInForce
+	}
+	if row.ExecInst != "" {
+		ord.ExecInst = row.ExecInst
+	}
+	if row.OrdStatus != "" {
+		ord.OrdStatus = row.OrdStatus
+	}
+	if row.LeavesQty != nil {
+		ord.LeavesQty = *row.LeavesQty
+	}
+	if row.CumQty != nil {
+		ord.CumQty = *row.CumQty
+	}
+	if row.AvgPx != nil {
+		ord.AvgPx = *row.AvgPx
+	}
+	if row.Text != "" {
+		ord.Text = row.Text
+	}
+	if !row.Timestamp.IsZero() {
+		ord.Timestamp = row.Timestamp
+	}
+	if row.WorkingIndicator != nil {
+		ord.WorkingIndicator = *row.WorkingIndicator
+	}
+}
@@ -0,0 +1,225 @@
+package orderproc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestOrderProcessor_processEvent_orders(t *testing.T) {
+	o := &OrderProcessor{log: logrus.New(), live: newLiveState()}
+
+	o.processEvent(&data.OrderEvent{
+		Header: data.Header{Table: string(types.Order), Action: string(data.Partial)},
+	})
+	orders, err := o.GetActiveOrders(string(types.XBTUSD))
+	require.NoError(t, err)
+	require.Empty(t, orders)
+
+	o.processEvent(&data.OrderEvent{
+		Header: data.Header{Table: string(types.Order), Action: string(data.Insert)},
+		Data: []data.Order{
+			{
+				OrderID: "1", Symbol: "XBTUSD", Side: "Buy", OrderQty: int64Ptr(100), Price: float64Ptr(9300),
+				OrdStatus: "New", LeavesQty: int64Ptr(100),
+			},
+			{
+				OrderID: "2", Symbol: "XBTUSD", Side: "Sell", OrderQty: int64Ptr(200), Price: float64Ptr(9350),
+				OrdStatus: "New", LeavesQty: int64Ptr(200),
+			},
+		},
+	})
+	o.processEvent(&data.OrderEvent{
+		Header: data.Header{Table: string(types.Order), Action: string(data.Update)},
+		Data: []data.Order{
+			{OrderID: "1", Price: float64Ptr(9305), LeavesQty: int64Ptr(0)},
+			{OrderID: "2", OrdStatus: "PartiallyFilled", LeavesQty: int64Ptr(50), CumQty: int64Ptr(150)},
+		},
+	})
+
+	orders, err = o.GetActiveOrders(string(types.XBTUSD))
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	byID := map[string]float64{}
+	for _, ord := range orders {
+		byID[ord.OrderID] = ord.Price
+		if ord.OrderID == "1" {
+			require.Equal(t, int64(0), ord.LeavesQty)
+			require.Equal(t, int64(100), ord.OrderQty)
+		}
+		if ord.OrderID == "2" {
+			require.Equal(t, int64(50), ord.LeavesQty)
+			require.Equal(t, "Sell", ord.Side)
+		}
+	}
+	require.Equal(t, map[string]float64{"1": 9305, "2": 9350}, byID)
+
+	o.processEvent(&data.OrderEvent{
+		Header: data.Header{Table: string(types.Order), Action: string(data.Update)},
+		Data:   []data.Order{{OrderID: "2", OrdStatus: "Filled", CumQty: int64Ptr(200)}},
+	})
+	orders, err = o.GetActiveOrders(string(types.XBTUSD))
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.Equal(t, "1", orders[0].OrderID)
+}
+
+func TestOrderProcessor_processEvent_balance(t *testing.T) {
+	o := &OrderProcessor{log: logrus.New(), live: newLiveState()}
+
+	_, _, ok := o.liveBalance("XBt")
+	require.False(t, ok)
+
+	o.processEvent(&data.MarginEvent{
+		Header: data.Header{Table: string(types.Margin), Action: string(data.Partial)},
+		Data: []data.Margin{{
+			Currency:        "XBt",
+			WalletBalance:   int64Ptr(1000000),
+			AvailableMargin: int64Ptr(800000),
+		}},
+	})
+	o.processEvent(&data.MarginEvent{
+		Header: data.Header{Table: string(types.Margin), Action: string(data.Update)},
+		Data:   []data.Margin{{Currency: "XBt", AvailableMargin: int64Ptr(0)}},
+	})
+
+	wallet, available, ok := o.liveBalance("XBt")
+	require.True(t, ok)
+	require.Equal(t, money.Satoshi(1000000), wallet)
+	require.Equal(t, money.Satoshi(0), available)
+}
+
+func TestOrderProcessor_processEvent_fills(t *testing.T) {
+	o := &OrderProcessor{log: logrus.New(), live: newLiveState()}
+	o.processEvent(&data.ExecutionEvent{
+		Header: data.Header{Table: string(types.Execution), Action: string(data.Insert)},
+		Data: []data.Execution{
+			{ExecID: "1", OrderID: "1", ExecType: "New"},
+			{ExecID: "2", OrderID: "1", ExecType: "Trade", LastQty: 100, LastPx: 9300},
+		},
+	})
+	fills := o.GetFills()
+	require.Len(t, fills, 1)
+	require.Equal(t, "2", fills[0].ExecID)
+
+	_, ok := o.LastFillMaker("XBTUSD", types.SideBuy)
+	require.False(t, ok)
+	o.processEvent(&data.ExecutionEvent{
+		Header: data.Header{Table: string(types.Execution), Action: string(data.Insert)},
+		Data: []data.Execution{
+			{ExecID: "3", Symbol: "XBTUSD", Side: "Buy", ExecType: "Trade", LastLiquidityInd: "AddedLiquidity"},
+			{ExecID: "4", Symbol: "XBTUSD", Side: "Sell", ExecType: "Trade", LastLiquidityInd: "RemovedLiquidity"},
+		},
+	})
+	maker, ok := o.LastFillMaker("XBTUSD", types.SideBuy)
+	require.True(t, ok)
+	require.True(t, maker)
+	maker, ok = o.LastFillMaker("XBTUSD", types.SideSell)
+	require.True(t, ok)
+	require.False(t, maker)
+}
+
+func TestOrderProcessor_processEvent_instrument(t *testing.T) {
+	o := &OrderProcessor{log: logrus.New(), live: newLiveState()}
+
+	o.processEvent(&data.InstrumentEvent{
+		Header: data.Header{Table: string(types.Instrument), Action: string(data.Update)},
+		Data:   []data.Instrument{{Symbol: "XBTUSD", BidPrice: 9000}},
+	})
+	_, ok := o.liveInstrument("XBTUSD")
+	require.False(t, ok, "update before partial is skipped")
+
+	o.processEvent(&data.InstrumentEvent{
+		Header: data.Header{Table: string(types.Instrument), Action: string(data.Partial)},
+		Data: []data.Instrument{{
+			Symbol: "XBTUSD", IsInverse: true, LotSize: 100, TickSize: 0.5,
+			LastPrice: 9000, BidPrice: 9000, AskPrice: 9000.5, MidPrice: 9000.25,
+		}},
+	})
+	o.processEvent(&data.InstrumentEvent{
+		Header: data.Header{Table: string(types.Instrument), Action: string(data.Update)},
+		Data:   []data.Instrument{{Symbol: "XBTUSD", AskPrice: 9001}},
+	})
+
+	inst, ok := o.liveInstrument("XBTUSD")
+	require.True(t, ok)
+	require.Equal(t, int64(100), inst.LotSize)
+	require.Equal(t, 9000.0, inst.BidPrice)
+	require.Equal(t, 9001.0, inst.AskPrice)
+}
+
+func TestOrderProcessor_storeOrder(t *testing.T) {
+	var (
+		placedTS = time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+		streamTS = placedTS.Add(time.Second)
+	)
+	tests := []struct {
+		name   string
+		stream []data.Order
+		stored bitmex.OrderCopied
+		want   []bitmex.OrderCopied
+	}{
+		{
+			name:   "placed order before stream",
+			stored: bitmex.OrderCopied{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "New", Timestamp: placedTS},
+			want:   []bitmex.OrderCopied{{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "New", Timestamp: placedTS}},
+		},
+		{
+			name:   "filled by stream before response",
+			stream: []data.Order{{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "Filled", Timestamp: streamTS}},
+			stored: bitmex.OrderCopied{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "New", Timestamp: placedTS},
+		},
+		{
+			name:   "canceled by stream before amend response",
+			stream: []data.Order{{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "Canceled", Timestamp: placedTS}},
+			stored: bitmex.OrderCopied{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "New", Timestamp: streamTS},
+		},
+		{
+			name: "partially filled by stream after response",
+			stream: []data.Order{{
+				OrderID: "1", Symbol: "XBTUSD", OrdStatus: "PartiallyFilled", Price: float64Ptr(9300),
+				Timestamp: streamTS,
+			}},
+			stored: bitmex.OrderCopied{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "New", Price: 9305, Timestamp: placedTS},
+			want: []bitmex.OrderCopied{{
+				OrderID: "1", Symbol: "XBTUSD", OrdStatus: "PartiallyFilled", Price: 9300, Timestamp: streamTS,
+			}},
+		},
+		{
+			name:   "canceled by response",
+			stream: []data.Order{{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "New", Timestamp: placedTS}},
+			stored: bitmex.OrderCopied{OrderID: "1", Symbol: "XBTUSD", OrdStatus: "Canceled", Timestamp: streamTS},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &OrderProcessor{log: logrus.New(), live: newLiveState()}
+			o.processEvent(&data.OrderEvent{
+				Header: data.Header{Table: string(types.Order), Action: string(data.Partial)},
+				Data:   tt.stream,
+			})
+			o.storeOrder(tt.stored)
+			orders, err := o.GetActiveOrders(string(types.XBTUSD))
+			require.NoError(t, err)
+			require.Equal(t, len(tt.want), len(orders))
+			for i := range tt.want {
+				require.Equal(t, tt.want[i], orders[i])
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package orderproc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -11,14 +12,15 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/tagirmukail/tccbot-backend/internal/config"
+	bitmextradedata "github.com/tagirmukail/tccbot-backend/internal/tradedata/bitmex"
 	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
 )
 
 const (
-	limitBalanceContracts = 200
 	limitMinOnOrderQty    = 100
 	liquidationPriceLimit = 1600
 )
@@ -30,10 +32,18 @@ type OrderProcessor struct {
 	configurator    *config.Configurator
 	mx              sync.Mutex
 	currentPosition *bitmex.Position
+	subscriber      *bitmextradedata.Subscriber
+	liveMx          sync.Mutex
+	live            liveState
+	contractMx      sync.Mutex
+	contract        trademath.Contract
 }
 
 func New(
-	api tradeapi.API, configurator *config.Configurator, log *logrus.Logger,
+	api tradeapi.API,
+	configurator *config.Configurator,
+	subscriber *bitmextradedata.Subscriber,
+	log *logrus.Logger,
 ) *OrderProcessor {
 	rand.Seed(time.Now().UnixNano())
 
@@ -46,7 +56,9 @@ func New(
 		tickPeriod:   time.Duration(cfg.OrdProcPeriodSec) * time.Second,
 		api:          api,
 		configurator: configurator,
+		subscriber:   subscriber,
 		log:          log,
+		live:         newLiveState(),
 	}
 }
 
@@ -106,76 +118,101 @@ func (o *OrderProcessor) PlaceOrder(
 		if err != nil {
 			return nil, err
 		}
-		contracts := trademath.ConvertFromBTCToContracts(availableBalance)
-		if contracts <= limitBalanceContracts {
-			return nil, fmt.Errorf("balance is exhausted, %.3f left", availableBalance)
-		}
 		err = o.checkLimitContracts(cfg, side)
 		if err != nil {
 			return nil, err
 		}
-		inst, err := o.getInstrument(cfg)
+		contract, err := o.GetContract()
 		if err != nil {
 			return nil, err
 		}
-		var price float64
-		if side == types.SideSell {
-			price = inst.AskPrice
-		} else {
-			price = inst.BidPrice
+		inst, err := o.getInstrument(cfg)
+		if err != nil {
+			return nil, err
 		}
+		price := orderPrice(contract, inst, side)
 
-		if amount == 0 {
-			err = o.checkLiquidation(price, side)
+		leverage, maker := o.leverage(), MakerOrder(cfg, passive)
+		qty, err := explicitQty(contract, amount)
+		if err != nil {
+			return nil, err
+		}
+		if qty.IsZero() {
+			err = o.checkLiquidation(price.Float64(), side)
 			if err != nil {
 				return nil, err
 			}
-			amount, err = o.calcOrderQty(cfg, availableBalance, side)
+			qty, err = o.calcOrderQty(cfg, contract, price, leverage, availableBalance, side, maker)
 			if err != nil {
 				return nil, err
 			}
 		}
+		err = o.checkBalance(contract, side, price, qty.Int64(), leverage, availableBalance, maker)
+		if err != nil {
+			return nil, err
+		}
 
 		params := &bitmex.OrderNewParams{
 			Symbol:    cfg.ExchangesSettings.Bitmex.Symbol,
 			Side:      string(side),
 			OrderType: string(cfg.ExchangesSettings.Bitmex.OrderType),
-			OrderQty:  math.Round(amount),
-			Price:     price,
+			OrderQty:  qty.Float64(),
+			Price:     price.Float64(),
 		}
 		if passive {
 			params.ExecInst = string(types.PassiveOrderExecInstType)
 		}
 		o.log.Infof("create order params: %#v", params)
-		order, err = o.api.GetBitmex().CreateOrder(params)
+		ord, err := o.api.GetBitmex().CreateOrder(params)
 		if err != nil {
 			return nil, err
 		}
+		o.storeOrder(ord)
 
-		return order, nil
+		return ord, nil
 	default:
 		return nil, fmt.Errorf("unknown exchange: %s", exchange)
 	}
 }
 
-func (o *OrderProcessor) GetBalance() (walletBalance, availableBalance float64, err error) {
-	margins, err := o.api.GetBitmex().GetAllUserMargin()
-	if err != nil {
-		return 0, 0, err
+// explicitQty returns amount of contracts truncated to the lot size, zero amount means that quantity
+// is calculated by balance
+func explicitQty(contract trademath.Contract, amount float64) (money.Decimal, error) {
+	if amount <= 0 {
+		return money.Decimal{}, nil
 	}
-	if len(margins) == 0 {
-		return 0, 0, errors.New("user margins not exist")
+	qty := contract.RoundQty(money.FromFloat(amount))
+	if qty.IsZero() {
+		return qty, fmt.Errorf("order quantity %v less than lot size %d", amount, contract.LotSize)
 	}
+	return qty, nil
+}
 
+// GetBalance returns balance from websocket margin stream,
+// before margin stream is synced balance is requested by REST api
+func (o *OrderProcessor) GetBalance() (walletBalance, availableBalance money.Satoshi, err error) {
 	cfg, err := o.configurator.GetConfig()
 	if err != nil {
 		o.log.Fatal(err)
 	}
 
+	walletBalance, availableBalance, ok := o.liveBalance(cfg.ExchangesSettings.Bitmex.Currency)
+	if ok {
+		return walletBalance, availableBalance, nil
+	}
+
+	margins, err := o.api.GetBitmex().GetAllUserMargin()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(margins) == 0 {
+		return 0, 0, errors.New("user margins not exist")
+	}
+
 	for _, margin := range margins {
 		if cfg.ExchangesSettings.Bitmex.Currency == margin.Currency {
-			walletBalance = trademath.ConvertToBTC(margin.WalletBalance)
-			availableBalance = trademath.ConvertToBTC(margin.AvailableMargin)
+			walletBalance = money.Satoshi(margin.WalletBalance)
+			availableBalance = money.Satoshi(margin.AvailableMargin)
 			return walletBalance, availableBalance, nil
 		}
 	}
@@ -184,11 +221,94 @@ func (o *OrderProcessor) GetBalance() (walletBalance, availableBalance float64,
 		cfg.ExchangesSettings.Bitmex.Currency)
 }
 
+// CancelOpenOrders cancels all open orders by configured symbol, used on shutdown
+func (o *OrderProcessor) CancelOpenOrders(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	cfg, err := o.configurator.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	orders, err := o.api.GetBitmex().CancelAllOrders(&bitmex.OrderCancelAllParams{
+		Symbol: cfg.ExchangesSettings.Bitmex.Symbol,
+		Text:   "cancel open orders on shutdown",
+	})
+	if err != nil {
+		return err
+	}
+	o.log.Infof("canceled %d open orders by symbol %s", len(orders), cfg.ExchangesSettings.Bitmex.Symbol)
+	return nil
+}
+
+// GetContract returns contract specification of the configured symbol, it is requested once per symbol
+func (o *OrderProcessor) GetContract() (trademath.Contract, error) {
+	cfg, err := o.configurator.GetConfig()
+	if err != nil {
+		return trademath.Contract{}, err
+	}
+
+	o.contractMx.Lock()
+	defer o.contractMx.Unlock()
+	if o.contract.Symbol == cfg.ExchangesSettings.Bitmex.Symbol {
+		return withConfiguredFees(cfg, o.contract), nil
+	}
+	inst, err := o.requestInstrument(cfg)
+	if err != nil {
+		return trademath.Contract{}, err
+	}
+	contract, err := trademath.NewContract(inst)
+	if err != nil {
+		return trademath.Contract{}, err
+	}
+	o.contract = contract
+	return withConfiguredFees(cfg, contract), nil
+}
+
+// MakerOrder returns true if the order is charged by maker fee, it is passive order of limit type
+func MakerOrder(cfg *config.GlobalConfig, passive bool) bool {
+	switch cfg.ExchangesSettings.Bitmex.OrderType {
+	case types.Limit, types.StopLimit, types.LimitIfTouched:
+		return passive
+	default:
+		return false
+	}
+}
+
+// withConfiguredFees returns the contract with configured fee rates, instrument rates are kept if they are not configured
+func withConfiguredFees(cfg *config.GlobalConfig, contract trademath.Contract) trademath.Contract {
+	if !cfg.ExchangesSettings.Bitmex.InstrumentFees {
+		contract.Fees = trademath.Fees{
+			Maker: money.FromFloat(cfg.ExchangesSettings.Bitmex.MakerFee),
+			Taker: money.FromFloat(cfg.ExchangesSettings.Bitmex.TakerFee),
+		}
+	}
+	return contract
+}
+
+// orderPrice returns ask price for sell order and bid price for buy order rounded to the tick size
+func orderPrice(contract trademath.Contract, inst bitmex.Instrument, side types.Side) money.Decimal {
+	if side == types.SideSell {
+		return contract.RoundPrice(money.FromFloat(inst.AskPrice))
+	}
+	return contract.RoundPrice(money.FromFloat(inst.BidPrice))
+}
+
+// getInstrument returns instrument from websocket instrument stream,
+// before instrument stream is synced instrument is requested by REST api
 func (o *OrderProcessor) getInstrument(cfg *config.GlobalConfig) (bitmex.Instrument, error) {
+	if inst, ok := o.liveInstrument(cfg.ExchangesSettings.Bitmex.Symbol); ok {
+		return inst, nil
+	}
+	return o.requestInstrument(cfg)
+}
+
+func (o *OrderProcessor) requestInstrument(cfg *config.GlobalConfig) (bitmex.Instrument, error) {
 	var resp bitmex.Instrument
 	insts, err := o.api.GetBitmex().GetInstrument(bitmex.InstrumentRequestParams{
 		Symbol:  cfg.ExchangesSettings.Bitmex.Symbol,
-		Columns: "lastPrice,bidPrice,midPrice,askPrice,markPrice",
+		Columns: "lastPrice,bidPrice,midPrice,askPrice,markPrice," + trademath.ContractColumns,
 		Count:   1,
 	})
 	if err != nil {
@@ -241,37 +361,167 @@ func (o *OrderProcessor) checkLimitContracts(cfg *config.GlobalConfig, side type
 	return nil
 }
 
-// calcOrderQty in contracts
+// calcOrderQty in contracts, initial margin and fee of the order are coefficient part of the balance,
+// so order notional is the part multiplied by the leverage without the fee,
+// quantity is not less than limitMinOnOrderQty and is rounded to the lot size
 func (o *OrderProcessor) calcOrderQty(
-	cfg *config.GlobalConfig, balance float64, side types.Side,
-) (qtyContrts float64, err error) {
+	cfg *config.GlobalConfig,
+	contract trademath.Contract,
+	price, leverage money.Decimal,
+	balance money.Satoshi,
+	side types.Side,
+	maker bool,
+) (qtyContrts money.Decimal, err error) {
 	position, ok := o.GetPosition()
 	if ok {
 		if position.CurrentQty > 0 {
-			qtyContrts = math.Abs(float64(position.CurrentQty))
+			qtyContrts = money.FromInt(position.CurrentQty)
 			return
 		}
 	}
 
-	var qtyBtc float64
+	var coef float64
 	switch side {
 	case types.SideBuy:
-		qtyBtc = balance * cfg.ExchangesSettings.Bitmex.BuyOrderCoef
+		coef = cfg.ExchangesSettings.Bitmex.BuyOrderCoef
 	case types.SideSell:
-		qtyBtc = balance * cfg.ExchangesSettings.Bitmex.SellOrderCoef
+		coef = cfg.ExchangesSettings.Bitmex.SellOrderCoef
 	default:
 		err = fmt.Errorf("unknown side type: %s", side)
 		return
 	}
+	contracts, err := contractsByCoef(contract, price, leverage, balance, coef, maker)
+	if err != nil {
+		return
+	}
+	if contracts < limitMinOnOrderQty {
+		contracts = limitMinOnOrderQty
+	}
+	qtyContrts = contract.RoundQty(money.FromInt(contracts))
+	if qtyContrts.IsZero() {
+		qtyContrts = money.FromInt(contract.LotSize)
+	}
+	return
+}
 
-	qtyContrts = trademath.ConvertFromBTCToContracts(qtyBtc)
+// OrderQty returns quantity in contracts of the order of the side by the current price,
+// initial margin and fee of the order are coef part of the available balance.
+// Quantity is not raised to limitMinOnOrderQty, error is returned if it is less than the lot size
+func (o *OrderProcessor) OrderQty(side types.Side, coef float64, passive bool) (int64, error) {
+	cfg, err := o.configurator.GetConfig()
+	if err != nil {
+		o.log.Fatal(err)
+	}
+	_, availableBalance, err := o.GetBalance()
+	if err != nil {
+		return 0, err
+	}
+	contract, err := o.GetContract()
+	if err != nil {
+		return 0, err
+	}
+	inst, err := o.getInstrument(cfg)
+	if err != nil {
+		return 0, err
+	}
+	qty, err := qtyByCoef(
+		contract, orderPrice(contract, inst, side), o.leverage(), availableBalance, coef, MakerOrder(cfg, passive),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return qty.Int64(), nil
+}
 
-	if qtyContrts < float64(limitMinOnOrderQty) {
-		qtyContrts = float64(limitMinOnOrderQty)
+// qtyByCoef in contracts rounded to the lot size, initial margin and fee of the order are coefficient part
+// of the balance, error is returned if the quantity is less than the lot size
+func qtyByCoef(
+	contract trademath.Contract,
+	price, leverage money.Decimal,
+	balance money.Satoshi,
+	coef float64,
+	maker bool,
+) (money.Decimal, error) {
+	contracts, err := contractsByCoef(contract, price, leverage, balance, coef, maker)
+	if err != nil {
+		return money.Decimal{}, err
 	}
+	qty := contract.RoundQty(money.FromInt(contracts))
+	if qty.IsZero() {
+		return qty, fmt.Errorf("order quantity %d by coef %v less than lot size %d", contracts, coef, contract.LotSize)
+	}
+	return qty, nil
+}
 
-	qtyContrts = math.Round(qtyContrts)
-	return
+// contractsByCoef returns not rounded quantity in contracts,
+// initial margin and fee of the order are coefficient part of the balance
+func contractsByCoef(
+	contract trademath.Contract,
+	price, leverage money.Decimal,
+	balance money.Satoshi,
+	coef float64,
+	maker bool,
+) (int64, error) {
+	if leverage.Cmp(money.One) < 0 {
+		leverage = money.One
+	}
+	// notional / leverage + notional * fee rate = balance * coef
+	cost := money.One.Quo(leverage)
+	if rate := contract.Fees.Rate(maker); rate.Sign() > 0 {
+		cost = cost.Add(rate)
+	}
+	amount := money.FromInt(int64(balance)).Mul(money.FromFloat(coef)).Quo(cost)
+	return contract.Contracts(price, money.Satoshi(amount.Int64()))
+}
+
+// checkBalance checks that available balance covers initial margin and fee of the order,
+// the order which only reduces the position does not need margin
+func (o *OrderProcessor) checkBalance(
+	contract trademath.Contract,
+	side types.Side,
+	price money.Decimal,
+	qty int64,
+	leverage money.Decimal,
+	balance money.Satoshi,
+	maker bool,
+) error {
+	if o.reducesPosition(side, qty) {
+		return nil
+	}
+	cost := contract.Margin(price, qty, leverage)
+	if fee := contract.Fee(price, qty, maker); fee > 0 {
+		cost += fee
+	}
+	if balance < cost {
+		return fmt.Errorf("balance is exhausted, %s left, order margin with fee %s", balance, cost)
+	}
+	return nil
+}
+
+// leverage of the current position, zero is cross margin
+func (o *OrderProcessor) leverage() money.Decimal {
+	position, ok := o.GetPosition()
+	if !ok {
+		return money.Zero
+	}
+	return money.FromFloat(position.Leverage)
+}
+
+// reducesPosition returns true if the order of the side and qty only closes part of the current position,
+// such order does not need margin
+func (o *OrderProcessor) reducesPosition(side types.Side, qty int64) bool {
+	position, ok := o.GetPosition()
+	if !ok {
+		return false
+	}
+	switch side {
+	case types.SideSell:
+		return position.CurrentQty > 0 && qty <= position.CurrentQty
+	case types.SideBuy:
+		return position.CurrentQty < 0 && qty <= -position.CurrentQty
+	default:
+		return false
+	}
 }
 
 //
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
 )
@@ -15,14 +17,16 @@ func TestOrderProcessor_calcOrderQty(t *testing.T) {
 		position *bitmex.Position
 	}
 	type args struct {
-		cfg     *config.GlobalConfig
-		balance float64
-		side    types.Side
+		cfg      *config.GlobalConfig
+		price    money.Decimal
+		leverage money.Decimal
+		balance  money.Satoshi
+		side     types.Side
 	}
 	type test struct {
 		fields         fields
 		args           args
-		wantQtyContrts float64
+		wantQtyContrts money.Decimal
 		wantErr        error
 	}
 
@@ -43,16 +47,18 @@ func TestOrderProcessor_calcOrderQty(t *testing.T) {
 						},
 					},
 				},
-				balance: 0.0090,
+				price:   money.FromInt(9000),
+				balance: money.FromBTCFloat(0.9),
 				side:    types.SideBuy,
 			},
-			wantQtyContrts: 345,
+			wantQtyContrts: money.FromInt(345),
 		}
 
 		o := &OrderProcessor{
 			currentPosition: tt.fields.position,
 		}
-		gotQtyContrts, err := o.calcOrderQty(tt.args.cfg, tt.args.balance, tt.args.side)
+		gotQtyContrts, err := o.calcOrderQty(
+			tt.args.cfg, trademath.XBTUSD, tt.args.price, tt.args.leverage, tt.args.balance, tt.args.side, true)
 		require.NoError(t, err)
 		require.Equal(t, tt.wantQtyContrts, gotQtyContrts)
 	})
@@ -74,16 +80,18 @@ func TestOrderProcessor_calcOrderQty(t *testing.T) {
 						},
 					},
 				},
-				balance: 0.0090,
+				price:   money.FromInt(9000),
+				balance: money.FromBTCFloat(0.9),
 				side:    types.SideBuy,
 			},
-			wantQtyContrts: 135000,
+			wantQtyContrts: money.FromInt(1200),
 		}
 
 		o := &OrderProcessor{
 			currentPosition: tt.fields.position,
 		}
-		gotQtyContrts, err := o.calcOrderQty(tt.args.cfg, tt.args.balance, tt.args.side)
+		gotQtyContrts, err := o.calcOrderQty(
+			tt.args.cfg, trademath.XBTUSD, tt.args.price, tt.args.leverage, tt.args.balance, tt.args.side, true)
 		require.NoError(t, err)
 		require.Equal(t, tt.wantQtyContrts, gotQtyContrts)
 	})
@@ -106,16 +114,18 @@ func TestOrderProcessor_calcOrderQty(t *testing.T) {
 						},
 					},
 				},
-				balance: 0.0090,
+				price:   money.FromInt(9000),
+				balance: money.FromBTCFloat(0.9),
 				side:    types.SideSell,
 			},
-			wantQtyContrts: 90000,
+			wantQtyContrts: money.FromInt(800),
 		}
 
 		o := &OrderProcessor{
 			currentPosition: tt.fields.position,
 		}
-		gotQtyContrts, err := o.calcOrderQty(tt.args.cfg, tt.args.balance, tt.args.side)
+		gotQtyContrts, err := o.calcOrderQty(
+			tt.args.cfg, trademath.XBTUSD, tt.args.price, tt.args.leverage, tt.args.balance, tt.args.side, true)
 		require.NoError(t, err)
 		require.Equal(t, tt.wantQtyContrts, gotQtyContrts)
 	})
@@ -137,22 +147,24 @@ func TestOrderProcessor_calcOrderQty(t *testing.T) {
 						},
 					},
 				},
-				balance: 0.0090,
+				price:   money.FromInt(9000),
+				balance: money.FromBTCFloat(0.9),
 				side:    types.SideEmpty,
 			},
-			wantQtyContrts: 0,
+			wantQtyContrts: money.FromInt(0),
 			wantErr:        fmt.Errorf("unknown side type: %s", types.SideEmpty),
 		}
 
 		o := &OrderProcessor{
 			currentPosition: tt.fields.position,
 		}
-		gotQtyContrts, err := o.calcOrderQty(tt.args.cfg, tt.args.balance, tt.args.side)
+		gotQtyContrts, err := o.calcOrderQty(
+			tt.args.cfg, trademath.XBTUSD, tt.args.price, tt.args.leverage, tt.args.balance, tt.args.side, true)
 		require.EqualError(t, err, tt.wantErr.Error())
 		require.Equal(t, tt.wantQtyContrts, gotQtyContrts)
 	})
 
-	t.Run("pnl == 0 and currency qty == 0", func(t *testing.T) {
+	t.Run("less than min qty", func(t *testing.T) {
 		tt := test{
 			fields: fields{
 				position: &bitmex.Position{
@@ -169,17 +181,156 @@ func TestOrderProcessor_calcOrderQty(t *testing.T) {
 						},
 					},
 				},
-				balance: 0.0090,
+				price:   money.FromInt(9000),
+				balance: money.FromBTCFloat(0.9),
 				side:    types.SideBuy,
 			},
-			wantQtyContrts: 9000,
+			wantQtyContrts: money.FromInt(100),
 		}
 
 		o := &OrderProcessor{
 			currentPosition: tt.fields.position,
 		}
-		gotQtyContrts, err := o.calcOrderQty(tt.args.cfg, tt.args.balance, tt.args.side)
+		gotQtyContrts, err := o.calcOrderQty(
+			tt.args.cfg, trademath.XBTUSD, tt.args.price, tt.args.leverage, tt.args.balance, tt.args.side, true)
 		require.NoError(t, err)
 		require.Equal(t, tt.wantQtyContrts, gotQtyContrts)
 	})
+
+	t.Run("isolated leverage", func(t *testing.T) {
+		o := &OrderProcessor{
+			currentPosition: &bitmex.Position{Leverage: 10},
+		}
+		cfg := &config.GlobalConfig{
+			ExchangesSettings: config.ExchangesSettings{
+				Bitmex: config.APISettings{BuyOrderCoef: 0.15},
+			},
+		}
+		gotQtyContrts, err := o.calcOrderQty(
+			cfg, trademath.XBTUSD, money.FromInt(9000), o.leverage(), money.FromBTCFloat(0.9), types.SideBuy, true)
+		require.NoError(t, err)
+		require.Equal(t, money.FromInt(12100), gotQtyContrts)
+
+		gotQtyContrts, err = o.calcOrderQty(
+			cfg, trademath.XBTUSD, money.FromInt(9000), o.leverage(), money.FromBTCFloat(0.9), types.SideBuy, false)
+		require.NoError(t, err)
+		require.Equal(t, money.FromInt(12000), gotQtyContrts, "taker fee is reserved from the balance")
+	})
+}
+
+func TestOrderProcessor_checkBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		position *bitmex.Position
+		side     types.Side
+		qty      int64
+		balance  money.Satoshi
+		maker    bool
+		wantErr  bool
+	}{
+		{name: "margin with taker fee", side: types.SideBuy, qty: 100, balance: 1111945},
+		{name: "taker fee is not covered", side: types.SideBuy, qty: 100, balance: 1111944, wantErr: true},
+		{name: "maker rebate is not counted", side: types.SideBuy, qty: 100, balance: 1111112, maker: true},
+		{name: "margin is not covered", side: types.SideBuy, qty: 100, balance: 1111110, maker: true, wantErr: true},
+		{name: "close position", position: &bitmex.Position{CurrentQty: 1000}, side: types.SideSell, qty: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &OrderProcessor{currentPosition: tt.position}
+			err := o.checkBalance(
+				trademath.XBTUSD, tt.side, money.FromInt(9000), tt.qty, money.One, tt.balance, tt.maker)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMakerOrder(t *testing.T) {
+	cfg := func(orderType types.OrderType) *config.GlobalConfig {
+		return &config.GlobalConfig{
+			ExchangesSettings: config.ExchangesSettings{Bitmex: config.APISettings{OrderType: orderType}},
+		}
+	}
+	require.True(t, MakerOrder(cfg(types.Limit), true))
+	require.False(t, MakerOrder(cfg(types.Limit), false))
+	require.False(t, MakerOrder(cfg(types.Market), true))
+}
+
+func TestOrderProcessor_reducesPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		position *bitmex.Position
+		side     types.Side
+		qty      int64
+		want     bool
+	}{
+		{name: "close long", position: &bitmex.Position{CurrentQty: 300}, side: types.SideSell, qty: 300, want: true},
+		{name: "close part of short", position: &bitmex.Position{CurrentQty: -300}, side: types.SideBuy, qty: 100, want: true},
+		{name: "reverse long", position: &bitmex.Position{CurrentQty: 300}, side: types.SideSell, qty: 400},
+		{name: "increase short", position: &bitmex.Position{CurrentQty: -300}, side: types.SideSell, qty: 100},
+		{name: "no position", side: types.SideBuy, qty: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &OrderProcessor{currentPosition: tt.position}
+			require.Equal(t, tt.want, o.reducesPosition(tt.side, tt.qty))
+		})
+	}
+}
+
+func TestQtyByCoef(t *testing.T) {
+	tests := []struct {
+		name     string
+		coef     float64
+		leverage money.Decimal
+		maker    bool
+		want     money.Decimal
+		wantErr  bool
+	}{
+		{name: "less than lot", coef: 0.0004, leverage: money.FromInt(10), wantErr: true},
+		{name: "less than min qty", coef: 0.0015, leverage: money.FromInt(10), want: money.FromInt(100)},
+		{name: "taker", coef: 0.1, leverage: money.FromInt(10), want: money.FromInt(8000)},
+		{name: "maker", coef: 0.1, leverage: money.FromInt(10), maker: true, want: money.FromInt(8100)},
+		{name: "cross margin", coef: 0.1, want: money.FromInt(800)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qtyByCoef(
+				trademath.XBTUSD, money.FromInt(9000), tt.leverage, money.FromBTCFloat(0.9), tt.coef, tt.maker,
+			)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExplicitQty(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		want    money.Decimal
+		wantErr bool
+	}{
+		{name: "calculated by balance", amount: 0, want: money.Decimal{}},
+		{name: "rounded to lot", amount: 250, want: money.FromInt(200)},
+		{name: "less than lot", amount: 99, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := explicitQty(trademath.XBTUSD, tt.amount)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
 }
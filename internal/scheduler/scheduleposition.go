@@ -1,12 +1,9 @@
 package scheduler
 
 import (
+	"context"
 	"errors"
 	"math"
-	"os"
-	"os/signal"
-	"sync"
-	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,6 +12,7 @@ import (
 	"github.com/tagirmukail/tccbot-backend/internal/orderproc"
 	betrayed "github.com/tagirmukail/tccbot-backend/internal/tradedata/bitmex"
 	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
@@ -24,6 +22,8 @@ import (
 const (
 	LimitPositionPnls      = 2
 	expirePositionDuration = 5 * time.Minute
+	// closePassive close position orders are passive
+	closePassive = true
 )
 
 type PnlType uint8
@@ -35,7 +35,7 @@ const (
 )
 
 type positionPnl struct {
-	pnl float64
+	pnl money.Satoshi
 	t   PnlType
 }
 
@@ -49,7 +49,6 @@ type PositionScheduler struct {
 	positionPnlLimit     int
 }
 
-// TODO смотреть изменения ордеров по ws, проверять активные, исполненые, отклоненые в procActiveOrders()
 // TODO добавить в конфигурацию настройку для включения определенного шедулера
 func NewPositionScheduler(
 	configurator *config.Configurator,
@@ -69,15 +68,9 @@ func NewPositionScheduler(
 	}
 }
 
-func (o *PositionScheduler) Start(wg *sync.WaitGroup) {
+func (o *PositionScheduler) Start(ctx context.Context) {
 	o.log.Infof("position scheduler started")
-	defer func() {
-		o.log.Infof("position scheduler finished")
-		wg.Done()
-	}()
-
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
+	defer o.log.Infof("position scheduler finished")
 
 	cfg, err := o.configurator.GetConfig()
 	if err != nil {
@@ -92,12 +85,12 @@ func (o *PositionScheduler) Start(wg *sync.WaitGroup) {
 	}()
 	for {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			return
-		case tradeData := <-o.bitmexDataSubscriber.GetMsgChan():
-			o.log.Debugf("PositionScheduler.Start process data table: %#v", tradeData.Table)
-			if tradeData.Table == string(types.Position) {
-				o.processPosition(tradeData.Data)
+		case event := <-o.bitmexDataSubscriber.GetMsgChan():
+			o.log.Debugf("PositionScheduler.Start process data table: %#v", event.GetTable())
+			if positions, ok := event.(*data.PositionEvent); ok {
+				o.processPosition(positions.Data)
 			}
 		case <-activeOrdersTick.C:
 			err = o.procActiveOrders()
@@ -128,13 +121,13 @@ func (o *PositionScheduler) Stop() error {
 	return nil
 }
 
-func (o *PositionScheduler) processPosition(positions []data.BitmexIncomingData) {
+func (o *PositionScheduler) processPosition(positions []data.Position) {
 	cfg, err := o.configurator.GetConfig()
 	if err != nil {
 		o.log.Fatal(err)
 	}
 
-	orders, err := getActiveOrders(o.api, cfg.ExchangesSettings.Bitmex.Symbol)
+	orders, err := o.orderProc.GetActiveOrders(cfg.ExchangesSettings.Bitmex.Symbol)
 	if err != nil {
 		o.log.Errorf("get active orders failed: %v", err)
 		return
@@ -145,6 +138,12 @@ func (o *PositionScheduler) processPosition(positions []data.BitmexIncomingData)
 		return
 	}
 
+	contract, err := o.orderProc.GetContract()
+	if err != nil {
+		o.log.Errorf("get contract failed: %v", err)
+		return
+	}
+
 	for _, positionData := range positions {
 		o.log.Debugf("PositionScheduler.Start process data : %#v", positionData)
 		var (
@@ -152,7 +151,7 @@ func (o *PositionScheduler) processPosition(positions []data.BitmexIncomingData)
 			err      error
 		)
 		if string(positionData.Symbol) == cfg.ExchangesSettings.Bitmex.Symbol {
-			position, err = FromBitmexIncDataToPosition(positionData)
+			position, err = FromPositionData(positionData)
 			if err != nil {
 				o.log.Errorf("[bitmex exchange data]:%#v convert to position failed [err]:%v",
 					positionData, err)
@@ -178,20 +177,45 @@ func (o *PositionScheduler) processPosition(positions []data.BitmexIncomingData)
 			"[avgCostPrice]:%v, [lastPrice]:%v, [currentQty]:%v",
 			pos.AvgCostPrice, pos.LastPrice, pos.CurrentQty)
 
-		unrealisedPnl := trademath.CalculateUnrealizedPNL(pos.AvgCostPrice, pos.LastPrice, pos.CurrentQty)
-		o.log.Debugf("current position [unrealised pnl in btc]: %.9f", unrealisedPnl)
-		var pnlType = Neutral
-		if unrealisedPnl >= cfg.Scheduler.Position.ProfitCloseBTC {
-			pnlType = Profit
-		} else if unrealisedPnl <= -cfg.Scheduler.Position.LossCloseBTC {
-			pnlType = Loss
-		}
+		pnl := o.calcPositionPnl(cfg, contract, pos, o.entryMaker(cfg, pos))
+		o.processPnl(cfg, &pnl, *position)
+	}
+}
+
+// entryMaker returns true if the entry order of the position was charged by maker fee, it is defined by the last fill
+// of the position side, without fills the entry order is the passive order of the configured type
+func (o *PositionScheduler) entryMaker(cfg *config.GlobalConfig, pos *bitmex.Position) bool {
+	side := types.SideBuy
+	if pos.CurrentQty < 0 {
+		side = types.SideSell
+	}
+	if maker, ok := o.orderProc.LastFillMaker(cfg.ExchangesSettings.Bitmex.Symbol, side); ok {
+		return maker
+	}
+	return orderproc.MakerOrder(cfg, true)
+}
 
-		o.processPnl(cfg, &positionPnl{
-			pnl: unrealisedPnl,
-			t:   pnlType,
-		}, *position)
+// calcPositionPnl unrealised pnl of the position without fees of the entry and close orders,
+// entry fee is defined by openMaker, close order is passive and is charged by maker fee if order type is limit
+func (o *PositionScheduler) calcPositionPnl(
+	cfg *config.GlobalConfig, contract trademath.Contract, pos *bitmex.Position, openMaker bool,
+) positionPnl {
+	var (
+		openPrice  = money.FromFloat(pos.AvgCostPrice)
+		lastPrice  = money.FromFloat(pos.LastPrice)
+		closeMaker = orderproc.MakerOrder(cfg, closePassive)
+		pnl        = contract.NetPNL(openPrice, lastPrice, pos.CurrentQty, openMaker, closeMaker)
+	)
+	o.log.Debugf("current position [unrealised pnl without fees in btc]: %s", pnl)
+
+	result := positionPnl{pnl: pnl, t: Neutral}
+	switch {
+	case pnl >= money.FromBTCFloat(cfg.Scheduler.Position.ProfitCloseBTC):
+		result.t = Profit
+	case pnl <= -money.FromBTCFloat(cfg.Scheduler.Position.LossCloseBTC):
+		result.t = Loss
 	}
+	return result
 }
 
 func (o *PositionScheduler) processPnl(cfg *config.GlobalConfig, p *positionPnl, position bitmex.Position) {
@@ -209,7 +233,11 @@ func (o *PositionScheduler) processPnl(cfg *config.GlobalConfig, p *positionPnl,
 }
 
 func (o *PositionScheduler) checkPlaceOrder(cfg *config.GlobalConfig, p *positionPnl) bool {
-	var placeOrder bool
+	var (
+		placeOrder bool
+		profitDiff = money.FromBTCFloat(cfg.Scheduler.Position.ProfitPnlDiff)
+		lossDiff   = money.FromBTCFloat(cfg.Scheduler.Position.LossPnlDiff)
+	)
 	switch {
 	case o.pnlT.t == Profit && p.t == Loss:
 		placeOrder = true
@@ -218,16 +246,16 @@ func (o *PositionScheduler) checkPlaceOrder(cfg *config.GlobalConfig, p *positio
 			"[pnlT]: %#v, [p]: %#v",
 			o.pnlT, p)
 		o.pnlT = *p
-	case o.pnlT.t == Profit && p.pnl+cfg.Scheduler.Position.ProfitPnlDiff <= o.pnlT.pnl:
+	case o.pnlT.t == Profit && p.pnl+profitDiff <= o.pnlT.pnl:
 		placeOrder = true
-	case o.pnlT.t == Loss && p.pnl < o.pnlT.pnl-(cfg.Scheduler.Position.LossPnlDiff):
+	case o.pnlT.t == Loss && p.pnl < o.pnlT.pnl-lossDiff:
 		placeOrder = true
 	case o.pnlT.t == Loss && p.pnl > o.pnlT.pnl: // m. b. not needed
 		o.log.Debugf("[o.pnlT.t == Loss && p.pnl > o.pnlT.pnl] we are waiting to check the position, ["+
 			"pnlT]: %#v, [p]: %#v",
 			o.pnlT, p)
 		o.pnlT = *p
-	case o.pnlT.t == Neutral && p.t == Loss && p.pnl < o.pnlT.pnl-(cfg.Scheduler.Position.LossPnlDiff):
+	case o.pnlT.t == Neutral && p.t == Loss && p.pnl < o.pnlT.pnl-lossDiff:
 		placeOrder = true
 	case o.pnlT.t == Neutral && p.t == Loss:
 		o.log.Debugf("[o.pnlT.t == Neutral && p.t == Loss] we are waiting to check the position, "+
@@ -257,7 +285,7 @@ func (o *PositionScheduler) placeClosePositionOrder(position bitmex.Position) (i
 		return nil, errors.New("qty is 0")
 	}
 	ord, err := o.orderProc.PlaceOrder(
-		types.Bitmex, side, math.Abs(float64(position.CurrentQty)), true)
+		types.Bitmex, side, math.Abs(float64(position.CurrentQty)), closePassive)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +298,7 @@ func (o *PositionScheduler) procActiveOrders() error {
 		o.log.Fatal(err)
 	}
 
-	orders, err := getActiveOrders(o.api, cfg.ExchangesSettings.Bitmex.Symbol)
+	orders, err := o.orderProc.GetActiveOrders(cfg.ExchangesSettings.Bitmex.Symbol)
 	if err != nil {
 		return err
 	}
@@ -299,12 +327,12 @@ func (o *PositionScheduler) procActiveOrder(
 		case types.SideSell:
 			diff := inst.BidPrice - order.Price
 			if math.Abs(diff) > cfg.Scheduler.Position.PriceTrailing {
-				price = inst.BidPrice + 0.5
+				price = inst.BidPrice + inst.TickSize
 			}
 		case types.SideBuy:
 			diff := inst.AskPrice - order.Price
 			if math.Abs(diff) > cfg.Scheduler.Position.PriceTrailing {
-				price = inst.AskPrice - 0.5
+				price = inst.AskPrice - inst.TickSize
 			}
 		}
 		if price == 0 {
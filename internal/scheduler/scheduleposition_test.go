@@ -7,6 +7,10 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
 )
 
 func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
@@ -31,7 +35,7 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 			fields: fields{
 				log: logrus.New(),
 				pnlT: positionPnl{
-					pnl: 0.04,
+					pnl: money.FromBTCFloat(0.04),
 					t:   Profit,
 				},
 			},
@@ -46,12 +50,12 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 					},
 				},
 				p: &positionPnl{
-					pnl: 0.08,
+					pnl: money.FromBTCFloat(0.08),
 					t:   Profit,
 				},
 			},
 			wantPnl: positionPnl{
-				pnl: 0.08,
+				pnl: money.FromBTCFloat(0.08),
 				t:   Profit,
 			},
 			want: false,
@@ -61,7 +65,7 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 			fields: fields{
 				log: logrus.New(),
 				pnlT: positionPnl{
-					pnl: 0.04,
+					pnl: money.FromBTCFloat(0.04),
 					t:   Profit,
 				},
 			},
@@ -76,12 +80,12 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 					},
 				},
 				p: &positionPnl{
-					pnl: 0.06,
+					pnl: money.FromBTCFloat(0.06),
 					t:   Profit,
 				},
 			},
 			wantPnl: positionPnl{
-				pnl: 0.06,
+				pnl: money.FromBTCFloat(0.06),
 				t:   Profit,
 			},
 			want: false,
@@ -91,7 +95,7 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 			fields: fields{
 				log: logrus.New(),
 				pnlT: positionPnl{
-					pnl: 0.05,
+					pnl: money.FromBTCFloat(0.05),
 					t:   Profit,
 				},
 			},
@@ -106,12 +110,12 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 					},
 				},
 				p: &positionPnl{
-					pnl: 0.019,
+					pnl: money.FromBTCFloat(0.019),
 					t:   Profit,
 				},
 			},
 			wantPnl: positionPnl{
-				pnl: 0.05,
+				pnl: money.FromBTCFloat(0.05),
 				t:   Profit,
 			},
 			want: true,
@@ -121,7 +125,7 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 			fields: fields{
 				log: logrus.New(),
 				pnlT: positionPnl{
-					pnl: 0.05,
+					pnl: money.FromBTCFloat(0.05),
 					t:   Profit,
 				},
 			},
@@ -136,12 +140,12 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 					},
 				},
 				p: &positionPnl{
-					pnl: 0.021,
+					pnl: money.FromBTCFloat(0.021),
 					t:   Profit,
 				},
 			},
 			wantPnl: positionPnl{
-				pnl: 0.05,
+				pnl: money.FromBTCFloat(0.05),
 				t:   Profit,
 			},
 			want: false,
@@ -161,3 +165,53 @@ func TestPositionScheduler_checkPlaceOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestPositionScheduler_calcPositionPnl(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType types.OrderType
+		openMaker bool
+		position  *bitmex.Position
+		want      positionPnl
+	}{
+		{
+			name:      "passive entry and exit with maker rebates reach profit",
+			orderType: types.Limit,
+			openMaker: true,
+			position:  &bitmex.Position{AvgCostPrice: 8000, LastPrice: 9000, CurrentQty: 100},
+			want:      positionPnl{pnl: 139479, t: Profit},
+		},
+		{
+			name:      "market exit with taker fees does not reach profit",
+			orderType: types.Market,
+			position:  &bitmex.Position{AvgCostPrice: 8000, LastPrice: 9000, CurrentQty: 100},
+			want:      positionPnl{pnl: 137118, t: Neutral},
+		},
+		{
+			name:      "taker fees reach loss",
+			orderType: types.Market,
+			position:  &bitmex.Position{AvgCostPrice: 9000, LastPrice: 8000, CurrentQty: 100},
+			want:      positionPnl{pnl: -140660, t: Loss},
+		},
+		{
+			name:      "taker entry with passive exit",
+			orderType: types.Limit,
+			position:  &bitmex.Position{AvgCostPrice: 8000, LastPrice: 9000, CurrentQty: 100},
+			want:      positionPnl{pnl: 138229, t: Neutral},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.GlobalConfig{
+				ExchangesSettings: config.ExchangesSettings{
+					Bitmex: config.APISettings{OrderType: tt.orderType},
+				},
+				Scheduler: config.Scheduler{
+					Position: config.PositionScheduler{ProfitCloseBTC: 0.0013939, LossCloseBTC: 0.0014},
+				},
+			}
+			o := &PositionScheduler{log: logrus.New()}
+			require.Equal(t, tt.want, o.calcPositionPnl(cfg, trademath.XBTUSD, tt.position, tt.openMaker))
+		})
+	}
+}
@@ -1,9 +1,8 @@
 package scheduler
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"sync"
 	"time"
 
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
@@ -13,23 +12,15 @@ import (
 )
 
 type Scheduler interface {
-	Start(wg *sync.WaitGroup)
+	Start(ctx context.Context)
 	Stop() error
 }
 
-func getActiveOrders(api tradeapi.API, symbol string) ([]bitmex.OrderCopied, error) {
-	filter := fmt.Sprintf(`{"open": %t}`, true)
-	return api.GetBitmex().GetOrders(&bitmex.OrdersRequest{
-		Symbol: symbol,
-		Filter: filter,
-	})
-}
-
 func getInstrument(api tradeapi.API, symbol string) (bitmex.Instrument, error) {
 	var resp bitmex.Instrument
 	insts, err := api.GetBitmex().GetInstrument(bitmex.InstrumentRequestParams{
 		Symbol:  symbol,
-		Columns: "lastPrice,bidPrice,midPrice,askPrice,markPrice",
+		Columns: "lastPrice,bidPrice,midPrice,askPrice,markPrice,tickSize",
 		Count:   1,
 	})
 	if err != nil {
@@ -41,7 +32,7 @@ func getInstrument(api tradeapi.API, symbol string) (bitmex.Instrument, error) {
 	return insts[0], nil
 }
 
-func FromBitmexIncDataToPosition(d data.BitmexIncomingData) (*bitmex.Position, error) { // nolint:funlen
+func FromPositionData(d data.Position) (*bitmex.Position, error) { // nolint:funlen
 	ts, err := time.Parse("2006-01-02T15:04:05.999Z", d.Timestamp)
 	if err != nil {
 		return nil, err
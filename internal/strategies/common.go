@@ -51,16 +51,3 @@ func (s *Strategies) fetchMacdVals(signals []*models.Signal) []float64 { // noli
 	}
 	return result
 }
-
-func (s *Strategies) macdTimeFrameDefine(size models.BinSize) int { // nolint:unused
-	switch size {
-	case models.Bin5m:
-		return 6
-	case models.Bin1h:
-		return 4
-	case models.Bin1d:
-		return 3
-	default:
-		return 0
-	}
-}
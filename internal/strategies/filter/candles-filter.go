@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
 	"github.com/tagirmukail/tccbot-backend/internal/config"
 	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
@@ -109,7 +110,7 @@ func (f *CandlesFilter) Apply(ctx context.Context) types.Side {
 // храним колличество прошедших свечей, после срабатывания сигнала
 // как только рост закончился, обнуляем счетчик свечей и переменную с максимальной ценой
 func (f *CandlesFilter) apply(ctxData *getFromCtxData) types.Side {
-	cfg := f.cfg.GlobStrategies.GetCfgByBinSize(ctxData.binSize.String())
+	cfg := strategiesConfig(ctxData, f.cfg)
 	if cfg == nil {
 		f.log.Errorf("cfg by bin size is empty")
 		return types.SideEmpty
@@ -121,5 +122,14 @@ func (f *CandlesFilter) apply(ctxData *getFromCtxData) types.Side {
 		f.actions = f.actions[len(f.actions)-cfg.MaxCandlesFilterCount:]
 	}
 
+	candles, err := candlecache.Series(
+		ctxData.candles, candlecache.SeriesType(cfg.FilterSeries), cfg.SeriesBrickSize, cfg.SeriesATRCount,
+	)
+	if err != nil {
+		f.log.Errorf("CandlesFilter.Apply candles series failed: %v", err)
+		return types.SideEmpty
+	}
+	ctxData.candles = candles
+
 	return f.checkTrend.check(ctxData, cfg)
 }
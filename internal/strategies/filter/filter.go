@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/tagirmukail/tccbot-backend/internal/config"
 	"github.com/tagirmukail/tccbot-backend/internal/db/models"
 	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
 )
@@ -19,6 +21,9 @@ type getFromCtxData struct {
 	action  stratypes.Action
 	binSize models.BinSize
 	candles []bitmex.TradeBuck
+	cfg     *config.StrategiesConfig
+	volume  *stratypes.Volume
+	levels  *trademath.Levels
 }
 
 func getFromCtx(ctx context.Context) (*getFromCtxData, error) {
@@ -53,9 +58,35 @@ func getFromCtx(ctx context.Context) (*getFromCtxData, error) {
 		return nil, errors.New("getFromCtx - context candles type is not <[]bitmex.TradeBuck>")
 	}
 
+	// strategies config of the strategy instance is optional, config of the bin size is used without it
+	cfg, _ := ctx.Value(stratypes.StrategiesConfigKey).(*config.StrategiesConfig)
+
+	// volume is optional, it is nil if volume of the bin size is not calculated
+	var volume *stratypes.Volume
+	if vol, ok := ctx.Value(stratypes.VolumeKey).(stratypes.Volume); ok {
+		volume = &vol
+	}
+	// levels are optional, they are nil if levels of the bin size are not recomputed yet
+	var levels *trademath.Levels
+	if lvls, ok := ctx.Value(stratypes.LevelsKey).(trademath.Levels); ok {
+		levels = &lvls
+	}
+
 	return &getFromCtxData{
 		action:  action,
 		binSize: binSize,
 		candles: candles,
+		cfg:     cfg,
+		volume:  volume,
+		levels:  levels,
 	}, nil
 }
+
+// strategiesConfig returns strategies config of the strategy instance from the context data,
+// strategies config of the bin size if the context has not config
+func strategiesConfig(ctxData *getFromCtxData, scfg *config.GlobalConfig) *config.StrategiesConfig {
+	if ctxData.cfg != nil {
+		return ctxData.cfg
+	}
+	return scfg.GlobStrategies.GetCfgByBinSize(ctxData.binSize.String())
+}
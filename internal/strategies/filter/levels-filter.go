@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"context"
+	"math"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+)
+
+// LevelsFilter confirms the trend reversal at support and resistance levels: up trend is sold if the last close
+// is near a swing zone or a resistance pivot point, down trend is bought if it is near a swing zone
+// or a support pivot point. Close is near the level if the distance is not greater than levels_distance of the close
+type LevelsFilter struct {
+	cfg *config.GlobalConfig
+	log *logrus.Logger
+}
+
+func NewLevelsFilter(cfg *config.GlobalConfig, log *logrus.Logger) *LevelsFilter {
+	return &LevelsFilter{
+		cfg: cfg,
+		log: log,
+	}
+}
+
+func (f *LevelsFilter) Apply(ctx context.Context) types.Side {
+	ctxData, err := getFromCtx(ctx)
+	if err != nil {
+		f.log.Debugf("LevelsFilter.Apply failed: %v", err)
+		return types.SideEmpty
+	}
+
+	cfg := strategiesConfig(ctxData, f.cfg)
+	if cfg == nil {
+		f.log.Errorf("cfg by bin size is empty")
+		return types.SideEmpty
+	}
+	if ctxData.levels == nil || len(ctxData.candles) == 0 {
+		f.log.Debug("LevelsFilter.Apply - levels or candles is empty, exit")
+		return types.SideEmpty
+	}
+
+	var (
+		levels   = ctxData.levels
+		price    = ctxData.candles[len(ctxData.candles)-1].Close
+		distance = price * cfg.GetLevelsDistance()
+	)
+	switch ctxData.action {
+	case stratypes.UpTrend:
+		pivots := []float64{levels.Pivots.R1, levels.Pivots.R2, levels.Pivots.R3, levels.Pivots.R4}
+		if nearZone(levels.Zones, price, distance) || nearLevel(pivots, price, distance) {
+			return types.SideSell
+		}
+	case stratypes.DownTrend:
+		pivots := []float64{levels.Pivots.S1, levels.Pivots.S2, levels.Pivots.S3, levels.Pivots.S4}
+		if nearZone(levels.Zones, price, distance) || nearLevel(pivots, price, distance) {
+			return types.SideBuy
+		}
+	}
+	return types.SideEmpty
+}
+
+// nearZone returns true if the price is inside a zone widened by the distance
+func nearZone(zones []trademath.Zone, price, distance float64) bool {
+	for _, zone := range zones {
+		if price >= zone.Low-distance && price <= zone.High+distance {
+			return true
+		}
+	}
+	return false
+}
+
+// nearLevel returns true if the distance from the price to a not empty level is not greater than the distance
+func nearLevel(levels []float64, price, distance float64) bool {
+	for _, level := range levels {
+		if level != 0 && math.Abs(price-level) <= distance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestLevelsFilter_Apply(t *testing.T) {
+	var (
+		cfg = &config.GlobalConfig{
+			GlobStrategies: config.StrategiesGlobConfig{
+				M5: &config.StrategiesConfig{LevelsDistance: 0.001},
+			},
+		}
+		levels = trademath.Levels{
+			Pivots: trademath.PivotPoints{P: 9000, R1: 9100, R2: 9200, S1: 8900, S2: 8800},
+			Zones:  []trademath.Zone{{Low: 8500, High: 8520, Level: 8510, Touches: 2}},
+		}
+	)
+	tests := []struct {
+		name     string
+		action   stratypes.Action
+		close    float64
+		noLevels bool
+		want     types.Side
+	}{
+		{name: "up trend near resistance", action: stratypes.UpTrend, close: 9195, want: types.SideSell},
+		{name: "up trend near support", action: stratypes.UpTrend, close: 8895, want: types.SideEmpty},
+		{name: "up trend in zone", action: stratypes.UpTrend, close: 8510, want: types.SideSell},
+		{name: "down trend near support", action: stratypes.DownTrend, close: 8805, want: types.SideBuy},
+		{name: "down trend near zone", action: stratypes.DownTrend, close: 8495, want: types.SideBuy},
+		{name: "down trend far from levels", action: stratypes.DownTrend, close: 8700, want: types.SideEmpty},
+		{name: "not trend", action: stratypes.NotTrend, close: 9100, want: types.SideEmpty},
+		{name: "levels not recomputed", action: stratypes.UpTrend, close: 9100, noLevels: true, want: types.SideEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), stratypes.ActionKey, tt.action)
+			ctx = context.WithValue(ctx, stratypes.CandlesKey, []bitmex.TradeBuck{{Close: tt.close}})
+			ctx = context.WithValue(ctx, stratypes.BinSizeKey, models.Bin5m)
+			if !tt.noLevels {
+				ctx = context.WithValue(ctx, stratypes.LevelsKey, levels)
+			}
+			got := NewLevelsFilter(cfg, logrus.New()).Apply(ctx)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
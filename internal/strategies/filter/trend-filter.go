@@ -6,7 +6,6 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/tagirmukail/tccbot-backend/internal/config"
-	"github.com/tagirmukail/tccbot-backend/internal/db/models"
 	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 )
@@ -34,11 +33,16 @@ func (f *TrendFilter) Apply(ctx context.Context) types.Side {
 		return types.SideEmpty
 	}
 
-	return f.checkAction(ctxData.action, ctxData.binSize)
+	cfg := strategiesConfig(ctxData, f.cfg)
+	if cfg == nil {
+		f.log.Errorf("cfg by bin size is empty")
+		return types.SideEmpty
+	}
+	return f.checkAction(ctxData.action, cfg)
 }
 
-func (f *TrendFilter) checkAction(action stratypes.Action, size models.BinSize) types.Side {
-	f.addInPrevAction(action, size)
+func (f *TrendFilter) checkAction(action stratypes.Action, cfg *config.StrategiesConfig) types.Side {
+	f.addInPrevAction(action, cfg)
 	// тренд прерывается, проверяем, если первый тренд экшенов - восходящий тренд,
 	// а остальные - это иные экшены, то тогда выставляем на продажу, если первый - нисходящий тренд,
 	// а остальные иные экшены, то выставляем на покупку, если остальные экшены(хотя бы один) такие же как и первый,
@@ -50,15 +54,10 @@ func (f *TrendFilter) checkAction(action stratypes.Action, size models.BinSize)
 	// then we put up for purchase, if the rest of the actions (at least one) same as the first one,
 	// then continue to observe, exit without action
 	f.log.Debug("TrendFilter.Apply - checkAction check prev actions")
-	return f.checkPrevActions(size)
+	return f.checkPrevActions(cfg)
 }
 
-func (f *TrendFilter) checkPrevActions(size models.BinSize) types.Side {
-	cfg := f.cfg.GlobStrategies.GetCfgByBinSize(size.String())
-	if cfg == nil {
-		f.log.Errorf("cfg by bin size is empty")
-		return types.SideEmpty
-	}
+func (f *TrendFilter) checkPrevActions(cfg *config.StrategiesConfig) types.Side {
 	if len(f.prevActions) < cfg.MaxFilterTrendCount {
 		f.log.Debug("TrendFilter.Apply - checkAction - checkPrevActions - " +
 			"prev action count less than max_filter_trend_count, exit")
@@ -95,12 +94,7 @@ func (f *TrendFilter) checkPrevActions(size models.BinSize) types.Side {
 	return types.SideEmpty
 }
 
-func (f *TrendFilter) addInPrevAction(action stratypes.Action, size models.BinSize) {
-	cfg := f.cfg.GlobStrategies.GetCfgByBinSize(size.String())
-	if cfg == nil {
-		f.log.Errorf("cfg by bin size is empty")
-		return
-	}
+func (f *TrendFilter) addInPrevAction(action stratypes.Action, cfg *config.StrategiesConfig) {
 	f.prevActions = append(f.prevActions, action)
 	if len(f.prevActions) >= cfg.MaxFilterTrendCount {
 		f.prevActions = f.prevActions[len(f.prevActions)-cfg.MaxFilterTrendCount:]
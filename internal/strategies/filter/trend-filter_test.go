@@ -346,3 +346,20 @@ func TestTrendFilter_Apply(t *testing.T) {
 		require.Empty(t, f.prevActions)
 	})
 }
+
+func TestTrendFilter_ApplyInstanceConfig(t *testing.T) {
+	f := NewTrendFilter(&config.GlobalConfig{
+		GlobStrategies: config.StrategiesGlobConfig{
+			M5: &config.StrategiesConfig{MaxFilterTrendCount: 6},
+		},
+	}, logrus.New())
+	f.prevActions = []stratypes.Action{stratypes.DownTrend, stratypes.NotTrend}
+
+	ctx := context.WithValue(context.Background(), stratypes.ActionKey, stratypes.NotTrend)
+	ctx = context.WithValue(ctx, stratypes.CandlesKey, []bitmex.TradeBuck{})
+	ctx = context.WithValue(ctx, stratypes.BinSizeKey, models.Bin5m)
+	ctx = context.WithValue(ctx, stratypes.StrategiesConfigKey, &config.StrategiesConfig{MaxFilterTrendCount: 3})
+
+	require.Equal(t, types.SideBuy, f.Apply(ctx))
+	require.Empty(t, f.prevActions)
+}
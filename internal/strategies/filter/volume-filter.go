@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+)
+
+// VolumeFilter confirms the trend reversal by volume: up trend is sold if the last close is above the session vwap
+// and the value area of the volume profile, down trend is bought if it is below them. Relative volume of the last
+// candle must be not less than min_relative_volume and taker volume delta of the last bin must not follow the trend
+type VolumeFilter struct {
+	cfg *config.GlobalConfig
+	log *logrus.Logger
+}
+
+func NewVolumeFilter(cfg *config.GlobalConfig, log *logrus.Logger) *VolumeFilter {
+	return &VolumeFilter{
+		cfg: cfg,
+		log: log,
+	}
+}
+
+func (f *VolumeFilter) Apply(ctx context.Context) types.Side {
+	ctxData, err := getFromCtx(ctx)
+	if err != nil {
+		f.log.Debugf("VolumeFilter.Apply failed: %v", err)
+		return types.SideEmpty
+	}
+
+	cfg := strategiesConfig(ctxData, f.cfg)
+	if cfg == nil {
+		f.log.Errorf("cfg by bin size is empty")
+		return types.SideEmpty
+	}
+	if ctxData.volume == nil || len(ctxData.candles) == 0 {
+		f.log.Debug("VolumeFilter.Apply - volume or candles is empty, exit")
+		return types.SideEmpty
+	}
+
+	var (
+		volume = ctxData.volume
+		last   = ctxData.candles[len(ctxData.candles)-1]
+		delta  = lastDelta(volume.Deltas, last.Timestamp)
+	)
+	if volume.RelativeVolume < cfg.GetMinRelativeVolume() {
+		f.log.Debugf("VolumeFilter.Apply - relative volume %v is low, exit", volume.RelativeVolume)
+		return types.SideEmpty
+	}
+	switch ctxData.action {
+	case stratypes.UpTrend:
+		if last.Close > volume.SessionVWAP && last.Close >= volume.Profile.VAH && delta <= 0 {
+			return types.SideSell
+		}
+	case stratypes.DownTrend:
+		if last.Close < volume.SessionVWAP && (volume.Profile.VAL == 0 || last.Close <= volume.Profile.VAL) &&
+			delta >= 0 {
+			return types.SideBuy
+		}
+	}
+	return types.SideEmpty
+}
+
+// lastDelta returns volume delta of the bin closed at the timestamp, 0 if the bin has not delta
+func lastDelta(deltas []candlecache.VolumeDelta, timestamp string) int64 {
+	if len(deltas) == 0 || deltas[len(deltas)-1].Timestamp != timestamp {
+		return 0
+	}
+	return deltas[len(deltas)-1].Delta()
+}
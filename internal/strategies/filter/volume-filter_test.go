@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestVolumeFilter_Apply(t *testing.T) {
+	const lastTS = "2020-05-01T10:05:00.000Z"
+	var (
+		cfg = &config.GlobalConfig{
+			GlobStrategies: config.StrategiesGlobConfig{
+				M5: &config.StrategiesConfig{MinRelativeVolume: 1.5},
+			},
+		}
+		volume = stratypes.Volume{
+			SessionVWAP:    9000,
+			RelativeVolume: 2,
+			Profile:        trademath.VolumeProfile{POC: 9000, VAH: 9050, VAL: 8950},
+		}
+	)
+	tests := []struct {
+		name   string
+		action stratypes.Action
+		close  float64
+		volume func(v *stratypes.Volume)
+		noVol  bool
+		want   types.Side
+	}{
+		{name: "up trend above value area", action: stratypes.UpTrend, close: 9100, want: types.SideSell},
+		{name: "up trend inside value area", action: stratypes.UpTrend, close: 9020, want: types.SideEmpty},
+		{name: "down trend below value area", action: stratypes.DownTrend, close: 8900, want: types.SideBuy},
+		{name: "down trend above session vwap", action: stratypes.DownTrend, close: 9010, want: types.SideEmpty},
+		{name: "not trend", action: stratypes.NotTrend, close: 9100, want: types.SideEmpty},
+		{
+			name: "low relative volume", action: stratypes.UpTrend, close: 9100, want: types.SideEmpty,
+			volume: func(v *stratypes.Volume) { v.RelativeVolume = 1.2 },
+		},
+		{
+			name: "buyers delta of the last bin", action: stratypes.UpTrend, close: 9100, want: types.SideEmpty,
+			volume: func(v *stratypes.Volume) {
+				v.Deltas = []candlecache.VolumeDelta{{Timestamp: lastTS, Buy: 200, Sell: 100}}
+			},
+		},
+		{
+			name: "sellers delta of the last bin", action: stratypes.UpTrend, close: 9100, want: types.SideSell,
+			volume: func(v *stratypes.Volume) {
+				v.Deltas = []candlecache.VolumeDelta{{Timestamp: lastTS, Buy: 100, Sell: 200}}
+			},
+		},
+		{
+			name: "buyers delta of the previous bin", action: stratypes.UpTrend, close: 9100, want: types.SideSell,
+			volume: func(v *stratypes.Volume) {
+				v.Deltas = []candlecache.VolumeDelta{{Timestamp: "2020-05-01T10:00:00.000Z", Buy: 200, Sell: 100}}
+			},
+		},
+		{name: "volume not calculated", action: stratypes.UpTrend, close: 9100, noVol: true, want: types.SideEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), stratypes.ActionKey, tt.action)
+			ctx = context.WithValue(ctx, stratypes.CandlesKey, []bitmex.TradeBuck{{Timestamp: lastTS, Close: tt.close}})
+			ctx = context.WithValue(ctx, stratypes.BinSizeKey, models.Bin5m)
+			if !tt.noVol {
+				vol := volume
+				if tt.volume != nil {
+					tt.volume(&vol)
+				}
+				ctx = context.WithValue(ctx, stratypes.VolumeKey, vol)
+			}
+			got := NewVolumeFilter(cfg, logrus.New()).Apply(ctx)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
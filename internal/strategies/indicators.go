@@ -0,0 +1,161 @@
+package strategies
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/stream"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// indicatorsParams returns parameters of the streaming indicators as used by the bin size strategies,
+// bollinger band and macd with moving averages not supported by streaming indicators are not calculated
+func indicatorsParams(cfg *config.StrategiesConfig) stream.Params {
+	fastMAType, slowMAType, sigMAType := cfg.GetMacdMATypes()
+	params := stream.Params{
+		EMAPeriod:        cfg.GetCandlesCount,
+		RSIPeriod:        cfg.RsiCount,
+		BBPeriod:         cfg.GetBBPeriod(),
+		BBDeviation:      cfg.GetBBDeviation(),
+		BBMAType:         cfg.GetBBMAType(),
+		MACDFastPeriod:   cfg.MacdFastCount,
+		MACDFastMAType:   fastMAType,
+		MACDSlowPeriod:   cfg.MacdSlowCount,
+		MACDSlowMAType:   slowMAType,
+		MACDSignalPeriod: cfg.MacdSigCount,
+		MACDSignalMAType: sigMAType,
+		ATRPeriod:        cfg.SeriesATRCount,
+		History:          indicatorsHistory(cfg),
+	}
+	if !stream.Supported(params.BBMAType) {
+		params.BBPeriod = 0
+	}
+	if !stream.Supported(fastMAType) || !stream.Supported(slowMAType) || !stream.Supported(sigMAType) {
+		params.MACDFastPeriod, params.MACDSlowPeriod, params.MACDSignalPeriod = 0, 0, 0
+	}
+	return params
+}
+
+// indicatorsHistory returns count of the last values used by strategies: rsi of the previous and the last candles,
+// macd of the macd divergence window and bollinger band of the last candles
+func indicatorsHistory(cfg *config.StrategiesConfig) int {
+	history := 2
+	if window := cfg.MacdSlowCount + cfg.MacdSigCount; window > history {
+		history = window
+	}
+	if cfg.BBLastCandlesCount > history {
+		history = cfg.BBLastCandlesCount
+	}
+	return history
+}
+
+// Indicators returns streaming indicators of the key updated by closed candles
+func (s *Strategies) Indicators(key candlecache.Key) (*stream.Set, bool) {
+	s.indicatorsMx.Lock()
+	defer s.indicatorsMx.Unlock()
+	set, ok := s.indicators[key]
+	return set, ok
+}
+
+// initIndicators restores indicators of the key from the saved snapshot and adds the candles after it.
+// Indicators are calculated from the candles if the snapshot is not saved, does not match config
+// or candles after the snapshot are not loaded
+func (s *Strategies) initIndicators(
+	strategiesCfg *config.StrategiesConfig, key candlecache.Key, candles []bitmex.TradeBuck,
+) error {
+	set, err := stream.NewSet(indicatorsParams(strategiesCfg))
+	if err != nil {
+		return err
+	}
+	if len(candles) != 0 {
+		first, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candles[0].Timestamp)
+		if err != nil {
+			return err
+		}
+		s.restoreIndicators(set, key, first)
+	}
+
+	s.indicatorsMx.Lock()
+	s.indicators[key] = set
+	s.indicatorsMx.Unlock()
+
+	s.updateIndicators(key, set, candles)
+	return nil
+}
+
+// restoreIndicators loads saved snapshot of the key if it is not older than the candle before the first one
+func (s *Strategies) restoreIndicators(set *stream.Set, key candlecache.Key, first time.Time) {
+	snapshot, err := s.db.GetIndicatorsSnapshot(string(key.Symbol), key.BinSize)
+	if err != nil {
+		s.log.Warnf("get %s indicators snapshot failed: %v", key, err)
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+	var state stream.SetState
+	err = json.Unmarshal([]byte(snapshot.Data), &state)
+	if err != nil {
+		s.log.Warnf("%s indicators snapshot is not valid: %v", key, err)
+		return
+	}
+	if state.Timestamp.Before(first.Add(-key.BinSize.Duration())) {
+		s.log.Infof("%s indicators snapshot is outdated: %v", key, state.Timestamp)
+		return
+	}
+	err = set.Restore(state)
+	if err != nil {
+		s.log.Infof("%s indicators snapshot is not restored: %v", key, err)
+		return
+	}
+	s.log.Infof("%s indicators restored from snapshot: %v", key, state.Timestamp)
+}
+
+// processIndicators adds closed candles of the key cache to the key indicators
+func (s *Strategies) processIndicators(key candlecache.Key) {
+	set, ok := s.Indicators(key)
+	if !ok {
+		return
+	}
+	cache, err := s.candlesCaches.GetCache(key)
+	if err != nil {
+		s.log.Warnf("candles cache of %s failed: %v", key, err)
+		return
+	}
+	s.updateIndicators(key, set, cache.GetBucketed(set.Timestamp().Add(time.Nanosecond), time.Time{}, 0))
+}
+
+// updateIndicators adds candles to the indicators and saves snapshot if any candle is added
+func (s *Strategies) updateIndicators(key candlecache.Key, set *stream.Set, candles []bitmex.TradeBuck) {
+	var updated bool
+	for _, candle := range candles {
+		ok, err := set.Update(candle)
+		if err != nil {
+			s.log.Warnf("update %s indicators failed: %v", key, err)
+			return
+		}
+		updated = updated || ok
+	}
+	if !updated {
+		return
+	}
+
+	data, err := json.Marshal(set.Snapshot())
+	if err != nil {
+		s.log.Warnf("%s indicators snapshot failed: %v", key, err)
+		return
+	}
+	err = s.db.SaveIndicatorsSnapshot(models.IndicatorsSnapshot{
+		Symbol:    string(key.Symbol),
+		BinSize:   key.BinSize,
+		Timestamp: set.Timestamp(),
+		Data:      string(data),
+	})
+	if err != nil {
+		s.log.Warnf("save %s indicators snapshot failed: %v", key, err)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"errors"
 	"time"
 
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
 	"github.com/tagirmukail/tccbot-backend/internal/config"
 
 	"github.com/markcheno/go-talib"
 
 	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/internal/utils"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
@@ -34,31 +36,19 @@ func (s *Strategies) binProcess(cfg *config.GlobalConfig, binSize string) error
 		return err
 	}
 
-	count := cfg.GlobStrategies.GetCfgByBinSize(binSize).MacdSlowCount * 2
+	count := cfg.GlobStrategies.GetCfgByBinSize(binSize).WarmupCount()
 
-	startTime, err := utils.FromTime(time.Now().UTC(), binSize, count)
+	candles, err := s.fetchCandles(cfg, binType, count)
 	if err != nil {
 		return err
 	}
 
-	candles, err := s.tradeAPI.GetBitmex().GetTradeBucketed(&bitmex.TradeGetBucketedParams{
-		Symbol:    cfg.ExchangesSettings.Bitmex.Symbol,
-		BinSize:   binSize,
-		Count:     int32(count),
-		StartTime: startTime.Format(bitmex.TradeTimeFormat),
-	})
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(cfg.ExchangesSettings.Bitmex.Symbol), binType)
+	err = s.initIndicators(cfg.GlobStrategies.GetCfgByBinSize(binSize), key, candles)
 	if err != nil {
 		return err
 	}
-	err = s.checkCloses(candles)
-	if err != nil {
-		return err
-	}
-
-	cache := s.candlesCaches.GetCache(binType)
-	if cache != nil {
-		cache.StoreBatch(candles)
-	}
+	s.processLevels(cfg, key)
 
 	closes := s.fetchCloses(candles)
 	if len(closes) < cfg.GlobStrategies.GetCfgByBinSize(binSize).MacdSlowCount {
@@ -101,17 +91,126 @@ func (s *Strategies) binProcess(cfg *config.GlobalConfig, binSize string) error
 	return nil
 }
 
+// maxTradeBucketedCount max count of candles returned by bitmex in one request
+const maxTradeBucketedCount = 1000
+
+// fetchCandles loads last count candles into the cache, candles of resampled bin size
+// are built from candles of the source bin size
+func (s *Strategies) fetchCandles(
+	cfg *config.GlobalConfig, binType models.BinSize, count int,
+) ([]bitmex.TradeBuck, error) {
+	source := binType.Source()
+	sourceCount := count
+	if binType.IsResampled() {
+		sourceCount = (count + 1) * int(binType.Duration()/source.Duration())
+	}
+
+	var candles []bitmex.TradeBuck
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(cfg.ExchangesSettings.Bitmex.Symbol), binType)
+	err := s.candlesCaches.Load(key, func(cache candlecache.Cache) error {
+		var err error
+		candles, err = s.loadCandles(cfg.ExchangesSettings.Bitmex.Symbol, source, sourceCount)
+		if err != nil {
+			return err
+		}
+		cache.StoreBatch(candles)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return candlecache.Resample(candles, binType)
+}
+
+// loadCandles returns last count candles, candles are read from db first
+// and only the missing tail is requested from bitmex
+func (s *Strategies) loadCandles(symbol string, binSize models.BinSize, count int) ([]bitmex.TradeBuck, error) {
+	now := time.Now().UTC()
+	startTime, err := utils.FromTime(now, binSize.String(), count)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := s.storedCandles(symbol, binSize, startTime, now)
+	if err != nil {
+		return nil, err
+	}
+	storedCount := len(candles)
+
+	fetchFrom, fetchCount := startTime, count
+	if len(candles) != 0 {
+		last, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candles[len(candles)-1].Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		fetchFrom, fetchCount = last.Add(binSize.Duration()), int(now.Sub(last)/binSize.Duration())
+	}
+	if len(candles)+fetchCount < count {
+		// the head is not stored, all candles are requested
+		candles, storedCount, fetchFrom, fetchCount = nil, 0, startTime, count
+	}
+	if fetchCount > maxTradeBucketedCount {
+		// candles can not be requested at once, stored candles are not used because of the gap
+		candles, storedCount, fetchCount = nil, 0, maxTradeBucketedCount
+		fetchFrom, err = utils.FromTime(now, binSize.String(), fetchCount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if fetchCount > 0 {
+		fetched, err := s.tradeAPI.GetBitmex().GetTradeBucketed(&bitmex.TradeGetBucketedParams{
+			Symbol:    symbol,
+			BinSize:   binSize.String(),
+			Count:     int32(fetchCount),
+			StartTime: fetchFrom.Format(bitmex.TradeTimeFormat),
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = s.db.SaveCandles(binSize, fetched)
+		if err != nil {
+			s.log.Warnf("save %s candles failed: %v", binSize, err)
+		}
+		candles = append(candles, fetched...)
+	}
+	s.log.Infof("%s candles loaded: %d from db, %d from bitmex", binSize, storedCount, len(candles)-storedCount)
+
+	err = s.checkCloses(candles)
+	if err != nil {
+		return nil, err
+	}
+	return candles, nil
+}
+
+// storedCandles returns candles saved in db without gaps up to the last saved candle
+func (s *Strategies) storedCandles(
+	symbol string, binSize models.BinSize, from, to time.Time,
+) ([]bitmex.TradeBuck, error) {
+	stored, err := s.db.GetCandles(symbol, binSize, from, to)
+	if err != nil {
+		return nil, err
+	}
+	candles := make([]bitmex.TradeBuck, 0, len(stored))
+	for _, candle := range stored {
+		candles = append(candles, candle.ToTradeBuck())
+	}
+	return candlecache.ContiguousTail(candles, binSize)
+}
+
 func (s *Strategies) macdSave(
 	cfg *config.GlobalConfig, timestamp time.Time, size models.BinSize, closes []float64) error {
 
+	fastMAType, slowMAType, sigMAType := cfg.GlobStrategies.GetCfgByBinSize(size.String()).GetMacdMATypes()
 	macd := s.tradeCalc.CalcMACD(
 		closes,
 		cfg.GlobStrategies.GetCfgByBinSize(size.String()).MacdFastCount,
-		talib.EMA,
+		fastMAType,
 		cfg.GlobStrategies.GetCfgByBinSize(size.String()).MacdSlowCount,
-		talib.EMA,
+		slowMAType,
 		cfg.GlobStrategies.GetCfgByBinSize(size.String()).MacdSigCount,
-		talib.WMA,
+		sigMAType,
 	)
 	_, err := s.db.SaveSignal(models.Signal{
 		BinSize:            size,
@@ -155,7 +254,7 @@ func (s *Strategies) rsiSave(
 	}
 
 	rsiValues := closes[:step]
-	rsi := s.tradeCalc.CalcRSI(rsiValues, cfg.GlobStrategies.GetCfgByBinSize(size.String()).RsiCount-1)
+	rsi := s.tradeCalc.CalcRSI(rsiValues, cfg.GlobStrategies.GetCfgByBinSize(size.String()).RsiCount)
 	_, err = s.db.SaveSignal(models.Signal{
 		N:           cfg.GlobStrategies.GetCfgByBinSize(size.String()).RsiCount,
 		BinSize:     size,
@@ -183,7 +282,8 @@ func (s *Strategies) rsiSave(
 func (s *Strategies) otherSignals(
 	cfg *config.GlobalConfig, timestamp time.Time, size models.BinSize, closes []float64, step int,
 ) error {
-	if step < cfg.GlobStrategies.GetCfgByBinSize(size.String()).GetCandlesCount {
+	strategiesCfg := cfg.GlobStrategies.GetCfgByBinSize(size.String())
+	if step < strategiesCfg.GetCandlesCount || step < strategiesCfg.BBCandlesCount() {
 		return nil
 	}
 	sigs, err := s.db.GetSignalsByTS(models.BolingerBand, size, []time.Time{timestamp})
@@ -228,14 +328,18 @@ func (s *Strategies) otherSignals(
 	if err != nil {
 		return err
 	}
+	tl, ml, bl := s.tradeCalc.CalcBB(
+		closes[step-strategiesCfg.BBCandlesCount():step],
+		strategiesCfg.GetBBPeriod(), strategiesCfg.GetBBDeviation(), strategiesCfg.GetBBMAType(),
+	)
 	_, err = s.db.SaveSignal(models.Signal{
-		N:          len(values),
+		N:          strategiesCfg.GetBBPeriod(),
 		BinSize:    size,
 		Timestamp:  timestamp,
 		SignalType: models.BolingerBand,
-		BBTL:       signals.BB.TL,
-		BBML:       signals.BB.ML,
-		BBBL:       signals.BB.BL,
+		BBTL:       tl,
+		BBML:       ml,
+		BBBL:       bl,
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,106 @@
+package strategies
+
+import (
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// parameters of support and resistance levels
+const (
+	levelsCandlesCount   = 200 // count of the last candles for swings and zones
+	levelsSwingLookback  = 5
+	levelsZoneWidth      = 0.003
+	levelsZoneMinTouches = 2
+	levelsPivotType      = trademath.ClassicPivot
+)
+
+// Levels returns support and resistance levels of the key recomputed by closed candles,
+// swing indexes are indexes of the last levelsCandlesCount candles of the key cache
+func (s *Strategies) Levels(key candlecache.Key) (trademath.Levels, bool) {
+	s.levelsMx.Lock()
+	defer s.levelsMx.Unlock()
+	levels, ok := s.levels[key]
+	return levels, ok
+}
+
+// processLevels recomputes levels of the key by the last candles of the key cache
+// and pivot points by the last closed 1d candle
+func (s *Strategies) processLevels(cfg *config.GlobalConfig, key candlecache.Key) {
+	cache, err := s.candlesCaches.GetCache(key)
+	if err != nil {
+		s.log.Warnf("candles cache of %s failed: %v", key, err)
+		return
+	}
+	candles := cache.GetBucketed(time.Time{}, time.Time{}, levelsCandlesCount)
+	if len(candles) == 0 {
+		return
+	}
+	last, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candles[len(candles)-1].Timestamp)
+	if err != nil {
+		s.log.Warnf("%s levels candle timestamp parse failed: %v", key, err)
+		return
+	}
+	if levels, ok := s.Levels(key); ok && !last.After(levels.Timestamp) {
+		return
+	}
+
+	swings := trademath.FindSwings(trademath.NewOHLCV(candles), levelsSwingLookback)
+	levels := trademath.Levels{
+		Timestamp: last,
+		Swings:    swings,
+		Zones:     trademath.ClusterZones(swings, levelsZoneWidth, levelsZoneMinTouches),
+	}
+	day, err := s.dayCandle(cfg, key, last)
+	switch {
+	case err != nil:
+		s.log.Warnf("%s 1d candle for pivot points failed: %v", key, err)
+	case day == nil:
+		s.log.Debugf("%s 1d candle for pivot points not found", key)
+	default:
+		levels.Pivots = s.tradeCalc.CalcPivotPoints(day.High, day.Low, day.Close, levelsPivotType)
+	}
+
+	s.levelsMx.Lock()
+	s.levels[key] = levels
+	s.levelsMx.Unlock()
+}
+
+// dayCandle returns 1d candle of the day before ts, 1d candles are loaded if the candle is not cached
+func (s *Strategies) dayCandle(
+	cfg *config.GlobalConfig, key candlecache.Key, ts time.Time,
+) (*bitmex.TradeBuck, error) {
+	dayKey := candlecache.NewKey(key.Exchange, key.Symbol, models.Bin1d)
+	dayClose := ts.Truncate(models.Bin1d.Duration())
+	find := func() (*bitmex.TradeBuck, error) {
+		cache, err := s.candlesCaches.GetCache(dayKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, candle := range cache.GetBucketed(dayClose, dayClose, 1) {
+			candleTS, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candle.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			if candleTS.Equal(dayClose) {
+				return &candle, nil
+			}
+		}
+		return nil, nil
+	}
+
+	candle, err := find()
+	if err != nil || candle != nil {
+		return candle, err
+	}
+	_, err = s.fetchCandles(cfg, models.Bin1d, 2)
+	if err != nil {
+		return nil, err
+	}
+	return find()
+}
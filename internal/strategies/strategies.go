@@ -2,11 +2,11 @@ package strategies
 
 import (
 	"context"
-	"os"
-	"os/signal"
+	"strings"
 	"sync"
-	"syscall"
+	"time"
 
+	"github.com/tagirmukail/tccbot-backend/internal/lifecycle"
 	"github.com/tagirmukail/tccbot-backend/internal/scheduler"
 
 	bitmextradedata "github.com/tagirmukail/tccbot-backend/internal/tradedata/bitmex"
@@ -21,8 +21,11 @@ import (
 	"github.com/tagirmukail/tccbot-backend/internal/orderproc"
 	"github.com/tagirmukail/tccbot-backend/internal/strategies/strategy"
 	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/stream"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	bitmexdata "github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
 )
 
 type Strategies struct {
@@ -31,7 +34,6 @@ type Strategies struct {
 		minBorderInProc bool
 		maxBorderInProc bool
 	}
-	wgRunner              *sync.WaitGroup
 	configurator          *config.Configurator
 	tradeAPI              tradeapi.API
 	db                    db.DatabaseManager
@@ -44,12 +46,29 @@ type Strategies struct {
 
 	candlesCaches candlecache.Caches
 
-	bbRsi strategy.Strategy
+	indicatorsMx sync.Mutex
+	indicators   map[candlecache.Key]*stream.Set
+
+	levelsMx sync.Mutex
+	levels   map[candlecache.Key]trademath.Levels
+
+	strategiesMx sync.Mutex
+	strategies   map[strategyKey]strategyInstance
+}
+
+// strategyKey key of the strategy instance, every bin size has own instances
+type strategyKey struct {
+	bin  models.BinSize
+	name string
+}
+
+type strategyInstance struct {
+	strategyType string
+	strategy     strategy.Strategy
 }
 
 // TODO перенести все параметры в отдельную структуру
 func New(
-	wgRunner *sync.WaitGroup,
 	configurator *config.Configurator,
 	tradeAPI tradeapi.API,
 	orderProc *orderproc.OrderProcessor,
@@ -59,11 +78,9 @@ func New(
 	db db.DatabaseManager,
 	log *logrus.Logger,
 	initSignals bool,
-	bbStrategy strategy.Strategy,
 	candlesCaches candlecache.Caches,
 ) *Strategies {
 	return &Strategies{
-		wgRunner:              wgRunner,
 		configurator:          configurator,
 		tradeAPI:              tradeAPI,
 		orderProc:             orderProc,
@@ -74,102 +91,113 @@ func New(
 		log:                   log,
 		tradeCalc:             trademath.Calc{},
 		initSignals:           initSignals,
-		bbRsi:                 bbStrategy,
 		candlesCaches:         candlesCaches,
+		indicators:            make(map[candlecache.Key]*stream.Set),
+		levels:                make(map[candlecache.Key]trademath.Levels),
+		strategies:            make(map[strategyKey]strategyInstance),
 	}
 }
 
-func (s *Strategies) Start() {
+// Start registers bot components in the lifecycle manager, they are stopped in the order:
+// strategies, position scheduler, trade data sender, order processor, open orders cancel, bitmex ws
+func (s *Strategies) Start(lc *lifecycle.Manager) {
 	err := s.SignalsInit()
 	if err != nil {
 		s.log.Fatalf("SignalsInit failed: %v", err)
 	}
-	s.wgRunner.Add(1)
-	go s.start(s.wgRunner)
-	if s.schedulr != nil {
-		s.wgRunner.Add(1)
-		go s.schedulr.Start(s.wgRunner)
+	cfg, err := s.configurator.GetConfig()
+	if err != nil {
+		s.log.Fatal(err)
 	}
 
-	s.wgRunner.Add(1)
-	go s.bitmexDataSender.SendToSubscribers(s.wgRunner)
-
-	s.wgRunner.Add(1)
-	go s.tradeAPI.GetBitmex().GetWS().Start(s.wgRunner)
-	s.wgRunner.Wait()
-
+	lc.Go("strategies", s.start)
 	if s.schedulr != nil {
-		_ = s.schedulr.Stop()
+		lc.Go("position_scheduler", s.schedulr.Start)
+		lc.OnStop("position_scheduler_stop", func(ctx context.Context) error {
+			return s.schedulr.Stop()
+		})
 	}
+	lc.Go("bitmex_trade_data_sender", s.bitmexDataSender.SendToSubscribers)
+	lc.Go("order_processor", s.orderProc.Start)
+	if cfg.ExchangesSettings.Bitmex.CancelOrdersOnShutdown {
+		lc.OnStop("cancel_open_orders", s.orderProc.CancelOpenOrders)
+	}
+	lc.Go("bitmex_ws", s.tradeAPI.GetBitmex().GetWS().Start)
 }
 
-func (s *Strategies) start(wg *sync.WaitGroup) { // nolint:gocognit
-	defer wg.Done()
-
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
-
+func (s *Strategies) start(ctx context.Context) {
 	s.log.Infof("process messages from bitmex started")
 	for {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			s.log.Infof("process messages stopped")
 			return
-		case data := <-s.bitmexTradeSubscriber.GetMsgChan():
-			if len(data.Data) == 0 {
-				s.log.Debug("empty data from ws")
+		case event := <-s.bitmexTradeSubscriber.GetMsgChan():
+			data, ok := event.(*bitmexdata.TradeBinEvent)
+			if !ok {
+				s.log.Warnf("processStrategies is not supported this table: %v", event.GetTable())
 				continue
 			}
-			switch data.Table {
-			case string(types.TradeBin1m):
-				if len(data.Data) != 0 {
-					for _, candle := range data.Data {
-						err := s.candlesCaches.GetCache(models.Bin1m).Store(candle)
-						if err != nil {
-							s.log.Warnf("store candle in cache failed: %v", err)
-						}
-					}
-				}
-				s.processStrategies("1m")
-			case string(types.TradeBin5m):
-				if len(data.Data) != 0 {
-					for _, candle := range data.Data {
-						err := s.candlesCaches.GetCache(models.Bin5m).Store(candle)
-						if err != nil {
-							s.log.Warnf("store candle in cache failed: %v", err)
-						}
-					}
-				}
-				s.processStrategies("5m")
-			case string(types.TradeBin1h):
-				if len(data.Data) != 0 {
-					for _, candle := range data.Data {
-						err := s.candlesCaches.GetCache(models.Bin1h).Store(candle)
-						if err != nil {
-							s.log.Warnf("store candle in cache failed: %v", err)
-						}
-					}
-				}
-				s.processStrategies("1h")
-			case string(types.TradeBin1d):
-				if len(data.Data) != 0 {
-					for _, candle := range data.Data {
-						err := s.candlesCaches.GetCache(models.Bin1d).Store(candle)
-						if err != nil {
-							s.log.Warnf("store candle in cache failed: %v", err)
-						}
-					}
-				}
-				s.processStrategies("1d")
-			default:
-				s.log.Warnf("processStrategies is not supported this trade bin: %v", data.Table)
+			if len(data.Data) == 0 {
+				s.log.Debug("empty data from ws")
 				continue
 			}
+			s.processCandles(ctx, data)
 		}
 	}
 }
 
-func (s *Strategies) processStrategies(binSize string) {
+// processCandles stores candles in the cache and runs strategies of the bin size
+// and of the resampled bin sizes closed by the candles
+func (s *Strategies) processCandles(ctx context.Context, event *bitmexdata.TradeBinEvent) {
+	bin, err := models.ToBinSize(strings.TrimPrefix(event.Table, string(types.TradeBin)))
+	if err != nil || bin.IsResampled() {
+		s.log.Warnf("processStrategies is not supported this trade bin: %v", event.Table)
+		return
+	}
+	cache, err := s.candlesCaches.GetCache(candlecache.NewKey(types.Bitmex, event.Data[0].Symbol, bin))
+	if err != nil {
+		s.log.Warnf("candles cache of trade bin %v failed: %v", event.Table, err)
+		return
+	}
+	var closed = make([]bitmex.TradeBuck, 0, len(event.Data))
+	for _, candle := range event.Data {
+		err := cache.Store(candle)
+		if err != nil {
+			s.log.Warnf("store candle in cache failed: %v", err)
+		}
+		closed = append(closed, candlecache.ToTradeBuck(candle))
+	}
+	err = s.db.SaveCandles(bin, closed)
+	if err != nil {
+		s.log.Warnf("save %s candles failed: %v", bin, err)
+	}
+
+	cfg, err := s.configurator.GetConfig()
+	if err != nil {
+		s.log.Fatal(err)
+	}
+	if cfg.GlobStrategies.GetCfgByBinSize(bin.String()) != nil {
+		s.processStrategies(ctx, bin.String())
+	}
+
+	last, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, event.Data[len(event.Data)-1].Timestamp)
+	if err != nil {
+		s.log.Warnf("candle timestamp parse failed: %v", err)
+		return
+	}
+	for _, binSize := range cfg.GlobStrategies.GetBinSizes() {
+		resampled, err := models.ToBinSize(binSize)
+		if err != nil || !resampled.IsResampled() || resampled.Source() != bin {
+			continue
+		}
+		if candlecache.CloseTime(last, resampled).Equal(last) {
+			s.processStrategies(ctx, binSize)
+		}
+	}
+}
+
+func (s *Strategies) processStrategies(ctx context.Context, binSize string) {
 	bin, err := models.ToBinSize(binSize)
 	if err != nil {
 		s.log.Warnf("to bin size error: %v", err)
@@ -180,6 +208,9 @@ func (s *Strategies) processStrategies(binSize string) {
 	if err != nil {
 		s.log.Fatal(err)
 	}
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(scfg.ExchangesSettings.Bitmex.Symbol), bin)
+	s.processIndicators(key)
+	s.processLevels(scfg, key)
 
 	strategiesConfig := scfg.GlobStrategies.GetCfgByBinSize(binSize)
 	if strategiesConfig == nil {
@@ -194,17 +225,55 @@ func (s *Strategies) processStrategies(binSize string) {
 	s.log.Infof("\n-------------------------------------\nstart strategies - bin size: %s", binSize)
 	defer s.log.Infof("finished strategies - bin size: %s\n-------------------------------------", binSize)
 
-	var currentStrategy strategy.Strategy
-
-	if strategiesConfig.EnableRSIBB {
-		currentStrategy = s.bbRsi
+	for _, instance := range strategiesConfig.GetInstances() {
+		s.executeStrategy(ctx, bin, instance)
 	}
-	// todo add macd with rsi
+}
 
-	if currentStrategy != nil {
-		err = currentStrategy.Execute(context.Background(), bin)
-		if err != nil {
-			s.log.Errorf("execute strategy failed: %v", err)
+// executeStrategy executes the strategy instance with its config,
+// error or panic of the instance does not stop other instances
+func (s *Strategies) executeStrategy(ctx context.Context, bin models.BinSize, instance config.StrategyInstance) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Errorf("strategy %s of bin size %s panicked: %v", instance.Name, bin, r)
 		}
+	}()
+
+	current, err := s.getStrategy(bin, instance)
+	if err != nil {
+		s.log.Errorf("strategy %s of bin size %s create failed: %v", instance.Name, bin, err)
+		return
+	}
+	cfg := instance.Config
+	err = current.Execute(strategy.WithConfig(ctx, &cfg), bin)
+	if err != nil {
+		s.log.Errorf("execute strategy %s of bin size %s failed: %v", instance.Name, bin, err)
+	}
+}
+
+// getStrategy returns strategy of the instance, it is created once per bin size and instance name
+// and recreated if type of the instance is changed
+func (s *Strategies) getStrategy(bin models.BinSize, instance config.StrategyInstance) (strategy.Strategy, error) {
+	s.strategiesMx.Lock()
+	defer s.strategiesMx.Unlock()
+	key := strategyKey{bin: bin, name: instance.Name}
+	if current, ok := s.strategies[key]; ok && current.strategyType == instance.Type {
+		return current.strategy, nil
+	}
+	created, err := strategy.New(instance.Type, strategy.Deps{
+		Configurator: s.configurator,
+		API:          s.tradeAPI,
+		OrderProc:    s.orderProc,
+		DB:           s.db,
+		Caches:       s.candlesCaches,
+		Log:          s.log,
+		Indicators:   s.Indicators,
+		Volume:       s.Volume,
+		Levels:       s.Levels,
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.strategies[key] = strategyInstance{strategyType: instance.Type, strategy: created}
+	return created, nil
 }
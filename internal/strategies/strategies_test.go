@@ -0,0 +1,72 @@
+package strategies
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/strategies/strategy"
+)
+
+type testStrategy struct {
+	err      error
+	panics   bool
+	executed int
+}
+
+func (s *testStrategy) Execute(ctx context.Context, size models.BinSize) error {
+	s.executed++
+	if s.panics {
+		panic("test panic")
+	}
+	return s.err
+}
+
+func init() {
+	strategy.Register("test_failed", func(deps strategy.Deps) (strategy.Strategy, error) {
+		return &testStrategy{err: errors.New("test error")}, nil
+	})
+	strategy.Register("test_panicked", func(deps strategy.Deps) (strategy.Strategy, error) {
+		return &testStrategy{panics: true}, nil
+	})
+	strategy.Register("test_ok", func(deps strategy.Deps) (strategy.Strategy, error) {
+		return &testStrategy{}, nil
+	})
+}
+
+func TestStrategies_executeStrategy(t *testing.T) {
+	s := &Strategies{log: logrus.New(), strategies: make(map[strategyKey]strategyInstance)}
+	binCfg := config.StrategiesConfig{
+		Instances: []config.StrategyInstance{
+			{Name: "failed", Type: "test_failed"},
+			{Name: "panicked", Type: "test_panicked"},
+			{Name: "unknown", Type: "test_unknown"},
+			{Name: "first", Type: "test_ok"},
+			{Name: "second", Type: "test_ok"},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		for _, instance := range binCfg.GetInstances() {
+			require.NotPanics(t, func() {
+				s.executeStrategy(context.Background(), models.Bin5m, instance)
+			})
+		}
+	}
+
+	require.Len(t, s.strategies, 4)
+	first := s.strategies[strategyKey{bin: models.Bin5m, name: "first"}].strategy.(*testStrategy)
+	second := s.strategies[strategyKey{bin: models.Bin5m, name: "second"}].strategy.(*testStrategy)
+	require.NotSame(t, first, second)
+	require.Equal(t, 2, first.executed)
+	require.Equal(t, 2, second.executed)
+	require.Equal(t, 2, s.strategies[strategyKey{bin: models.Bin5m, name: "panicked"}].strategy.(*testStrategy).executed)
+
+	s.executeStrategy(context.Background(), models.Bin5m, config.StrategyInstance{Name: "first", Type: "test_failed"})
+	require.Equal(t, "test_failed", s.strategies[strategyKey{bin: models.Bin5m, name: "first"}].strategyType)
+	require.Equal(t, 2, first.executed)
+}
@@ -0,0 +1,107 @@
+package strategy
+
+import (
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/stream"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// IndicatorsFunc returns streaming indicators of the key updated by closed candles
+type IndicatorsFunc func(key candlecache.Key) (*stream.Set, bool)
+
+// VolumeFunc returns volume indicators of the last closed candles of the key
+type VolumeFunc func(key candlecache.Key) (stratypes.Volume, error)
+
+// LevelsFunc returns support and resistance levels of the key recomputed by closed candles
+type LevelsFunc func(key candlecache.Key) (trademath.Levels, bool)
+
+// streamValues returns parameters and values of the last count candles of the streaming indicators of the key.
+// Streaming indicators are calculated by cache candles of the bin size config, ok is false if the strategy
+// uses other candles series or indicators are not updated by the last candle, then indicators are calculated
+// by the candles
+func streamValues(
+	indicators IndicatorsFunc, key candlecache.Key, cfg *config.StrategiesConfig, candles []bitmex.TradeBuck, count int,
+) (params stream.Params, values []stream.Values, ok bool) {
+	if indicators == nil || candlecache.SeriesType(cfg.Series) != candlecache.CandlesSeries || len(candles) == 0 {
+		return params, nil, false
+	}
+	set, exist := indicators(key)
+	if !exist {
+		return params, nil, false
+	}
+	last, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candles[len(candles)-1].Timestamp)
+	if err != nil || !last.Equal(set.Timestamp()) {
+		return params, nil, false
+	}
+	history := set.History()
+	if len(history) < count {
+		return params, nil, false
+	}
+	return set.Params(), history[len(history)-count:], true
+}
+
+// streamRSI returns rsi of the previous and the last candles from streaming indicators
+// if they are calculated with the rsi period of the config
+func streamRSI(
+	indicators IndicatorsFunc, key candlecache.Key, cfg *config.StrategiesConfig, candles []bitmex.TradeBuck,
+) (rsiValues, bool) {
+	params, values, ok := streamValues(indicators, key, cfg, candles, 2)
+	// rsi is ready after period changes of closes
+	if !ok || params.RSIPeriod != cfg.RsiCount || values[0].Count <= cfg.RsiCount {
+		return rsiValues{}, false
+	}
+	return rsiValues{
+		prev:    trademath.RoundFloat(values[0].RSI, 4),
+		current: trademath.RoundFloat(values[1].RSI, 4),
+	}, true
+}
+
+// streamBB returns bollinger band of the last candle from streaming indicators
+// if it is calculated with the bollinger band parameters of the config
+func streamBB(
+	indicators IndicatorsFunc, key candlecache.Key, cfg *config.StrategiesConfig, candles []bitmex.TradeBuck,
+) (trademath.BB, bool) {
+	params, values, ok := streamValues(indicators, key, cfg, candles, 1)
+	if !ok || params.BBPeriod != cfg.GetBBPeriod() || params.BBDeviation != cfg.GetBBDeviation() ||
+		params.BBMAType != cfg.GetBBMAType() || values[0].Count < cfg.BBCandlesCount() {
+		return trademath.BB{}, false
+	}
+	bb := values[0].BB
+	return trademath.BB{
+		TL: trademath.RoundFloat(bb.TL, 4),
+		ML: trademath.RoundFloat(bb.ML, 4),
+		BL: trademath.RoundFloat(bb.BL, 4),
+	}, true
+}
+
+// streamMACD returns macd of the last count candles from streaming indicators
+// if it is calculated with the macd parameters of the config
+func streamMACD(
+	indicators IndicatorsFunc, key candlecache.Key, cfg *config.StrategiesConfig, candles []bitmex.TradeBuck, count int,
+) ([]trademath.MACD, bool) {
+	params, values, ok := streamValues(indicators, key, cfg, candles, count)
+	if !ok {
+		return nil, false
+	}
+	fast, slow, sig := cfg.GetMacdMATypes()
+	if params.MACDFastPeriod != cfg.MacdFastCount || params.MACDSlowPeriod != cfg.MacdSlowCount ||
+		params.MACDSignalPeriod != cfg.MacdSigCount || params.MACDFastMAType != fast ||
+		params.MACDSlowMAType != slow || params.MACDSignalMAType != sig || values[0].Count <= cfg.MACDLookback() {
+		return nil, false
+	}
+	var result = make([]trademath.MACD, 0, len(values))
+	for _, v := range values {
+		result = append(result, trademath.MACD{
+			HistogramValue: trademath.RoundFloat(v.MACD.HistogramValue, 3),
+			Value:          trademath.RoundFloat(v.MACD.Value, 3),
+			Sig:            trademath.RoundFloat(v.MACD.Sig, 3),
+		})
+	}
+	return result, true
+}
@@ -0,0 +1,145 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/stream"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func indicatorsTestCandles(count int) []bitmex.TradeBuck {
+	start := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]bitmex.TradeBuck, 0, count)
+	for i := 0; i < count; i++ {
+		closePrice := 9000 + 100*math.Sin(float64(i)/5) + float64(i%7)
+		candles = append(candles, bitmex.TradeBuck{
+			Timestamp: start.Add(time.Duration(i) * time.Minute).Format(tradeapi.TradeBucketedTimestampLayout),
+			Close:     closePrice,
+			High:      closePrice + 5,
+			Low:       closePrice - 5,
+		})
+	}
+	return candles
+}
+
+func indicatorsTestConfig() *config.StrategiesConfig {
+	return &config.StrategiesConfig{
+		RsiCount:           14,
+		GetCandlesCount:    20,
+		BBLastCandlesCount: 4,
+		MacdFastCount:      12,
+		MacdSlowCount:      26,
+		MacdSigCount:       9,
+		MacdFastMAType:     "EMA",
+		MacdSlowMAType:     "EMA",
+		MacdSigMAType:      "EMA",
+	}
+}
+
+func indicatorsTestSet(t *testing.T, cfg *config.StrategiesConfig, candles []bitmex.TradeBuck) IndicatorsFunc {
+	fast, slow, sig := cfg.GetMacdMATypes()
+	set, err := stream.NewSet(stream.Params{
+		RSIPeriod:        cfg.RsiCount,
+		BBPeriod:         cfg.GetBBPeriod(),
+		BBDeviation:      cfg.GetBBDeviation(),
+		BBMAType:         cfg.GetBBMAType(),
+		MACDFastPeriod:   cfg.MacdFastCount,
+		MACDFastMAType:   fast,
+		MACDSlowPeriod:   cfg.MacdSlowCount,
+		MACDSlowMAType:   slow,
+		MACDSignalPeriod: cfg.MacdSigCount,
+		MACDSignalMAType: sig,
+		History:          cfg.MacdSlowCount + cfg.MacdSigCount,
+	})
+	require.NoError(t, err)
+	for _, candle := range candles {
+		_, err = set.Update(candle)
+		require.NoError(t, err)
+	}
+	return func(key candlecache.Key) (*stream.Set, bool) {
+		return set, true
+	}
+}
+
+func TestStreamIndicators(t *testing.T) {
+	var (
+		key     = candlecache.NewKey(types.Bitmex, "XBTUSD", models.Bin1m)
+		cfg     = indicatorsTestConfig()
+		candles = indicatorsTestCandles(200)
+		closes  = fetchCloses(candles)
+		calc    = trademath.Calc{}
+	)
+	indicators := indicatorsTestSet(t, cfg, candles)
+
+	rsi, ok := streamRSI(indicators, key, cfg, candles)
+	require.True(t, ok)
+	want, err := calcRSIValues(&calc, closes, cfg.RsiCount)
+	require.NoError(t, err)
+	require.Equal(t, want, rsi, "rsi of all candles")
+
+	bb, ok := streamBB(indicators, key, cfg, candles)
+	require.True(t, ok)
+	tl, ml, bl := calc.CalcBB(closes, cfg.GetBBPeriod(), cfg.GetBBDeviation(), cfg.GetBBMAType())
+	require.InDelta(t, tl, bb.TL, 0.0001)
+	require.InDelta(t, ml, bb.ML, 0.0001)
+	require.InDelta(t, bl, bb.BL, 0.0001)
+
+	window := macdWindow(cfg)
+	macds, ok := streamMACD(indicators, key, cfg, candles, window)
+	require.True(t, ok)
+	require.Len(t, macds, window)
+	fast, slow, sig := cfg.GetMacdMATypes()
+	wantMACDs := calc.CalcMACDSeries(closes, cfg.MacdFastCount, fast, cfg.MacdSlowCount, slow, cfg.MacdSigCount, sig)
+	require.Equal(t, wantMACDs[len(wantMACDs)-window:], macds)
+}
+
+func TestStreamIndicators_NotSuitable(t *testing.T) {
+	var (
+		key     = candlecache.NewKey(types.Bitmex, "XBTUSD", models.Bin1m)
+		candles = indicatorsTestCandles(100)
+	)
+	tests := []struct {
+		name       string
+		cfg        func(cfg *config.StrategiesConfig)
+		candles    []bitmex.TradeBuck
+		setCandles []bitmex.TradeBuck
+		nilFunc    bool
+	}{
+		{name: "not installed", nilFunc: true},
+		{name: "other rsi period", cfg: func(cfg *config.StrategiesConfig) { cfg.RsiCount = 7 }},
+		{name: "heikin ashi series", cfg: func(cfg *config.StrategiesConfig) { cfg.Series = "heikin_ashi" }},
+		{name: "not updated by the last candle", setCandles: candles[:99]},
+		{name: "rsi not ready", candles: candles[:15], setCandles: candles[:15]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := indicatorsTestConfig()
+			setCandles, strategyCandles := candles, candles
+			if tt.setCandles != nil {
+				setCandles = tt.setCandles
+			}
+			if tt.candles != nil {
+				strategyCandles = tt.candles
+			}
+			indicators := indicatorsTestSet(t, cfg, setCandles)
+			if tt.nilFunc {
+				indicators = nil
+			}
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			_, ok := streamRSI(indicators, key, cfg, strategyCandles)
+			require.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/orderproc"
+	"github.com/tagirmukail/tccbot-backend/internal/strategies/filter"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func init() {
+	Register(config.MACDStrategyType, func(deps Deps) (Strategy, error) {
+		s := NewMACDDivergenceStrategy(deps.Configurator, deps.OrderProc, deps.DB, deps.Caches, deps.Log)
+		s.indicators = deps.Indicators
+		s.volume = deps.Volume
+		s.levels = deps.Levels
+		return s, nil
+	})
+}
+
+// MACDDivergenceStrategy sells on bear divergence and buys on bull divergence of the macd histogram
+// and candles closes
+type MACDDivergenceStrategy struct {
+	configurator *config.Configurator
+	math         trademath.Calc
+	orderProc    *orderproc.OrderProcessor
+	log          *logrus.Logger
+	db           db.DatabaseManager
+	caches       candlecache.Caches
+	indicators   IndicatorsFunc
+	volume       VolumeFunc
+	levels       LevelsFunc
+	filters      []filter.Filter
+}
+
+// macdPivotLookback count of histogram values before and after the histogram pivot
+const macdPivotLookback = 1
+
+type macdDivergence struct {
+	bearDiverg bool
+	bullDiverg bool
+}
+
+func NewMACDDivergenceStrategy(
+	configurator *config.Configurator,
+	orderProc *orderproc.OrderProcessor,
+	db db.DatabaseManager,
+	caches candlecache.Caches,
+	log *logrus.Logger,
+	filters ...filter.Filter,
+) *MACDDivergenceStrategy {
+	return &MACDDivergenceStrategy{
+		configurator: configurator,
+		orderProc:    orderProc,
+		db:           db,
+		log:          log,
+		math:         trademath.Calc{},
+		caches:       caches,
+		filters:      filters,
+	}
+}
+
+func (s *MACDDivergenceStrategy) Execute(ctx context.Context, size models.BinSize) error {
+	s.log.Infof("start execute macd divergence strategy")
+	defer s.log.Infof("finish execute macd divergence strategy")
+
+	scfg, err := s.configurator.GetConfig()
+	if err != nil {
+		s.log.Fatal(err)
+	}
+
+	cfg := getConfig(ctx, scfg, size)
+	if cfg == nil {
+		return errors.New("cfg by bin size is empty")
+	}
+
+	s.filters = initFilters(s.filters, scfg, cfg, s.log)
+
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(scfg.ExchangesSettings.Bitmex.Symbol), size)
+	candles, err := getSeries(s.caches, key, cfg, cfg.MACDLookback()+macdWindow(cfg))
+	if err != nil {
+		return err
+	}
+
+	signals, err := s.processMACD(cfg, key, candles, size)
+	if err != nil {
+		return err
+	}
+
+	macdDiverg, err := s.processMACDSignals(size, candles[len(candles)-len(signals):], signals)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("processMACDSignals defined -->: %#v", macdDiverg)
+
+	action := stratypes.NotTrend
+	switch {
+	case macdDiverg.bearDiverg:
+		action = stratypes.UpTrend
+	case macdDiverg.bullDiverg:
+		action = stratypes.DownTrend
+	}
+
+	applySide := applyFilters(s.filters, cfg, action, candles, key, s.volume, s.levels, s.log)
+	if applySide != types.SideBuy && applySide != types.SideSell {
+		return nil
+	}
+
+	return placeBitmexOrder(s.orderProc, applySide, 0, true, s.log)
+}
+
+// macdWindow count of the last macd signals checked for divergence
+func macdWindow(cfg *config.StrategiesConfig) int {
+	return cfg.MacdSlowCount + cfg.MacdSigCount
+}
+
+// processMACD returns macd signals of the last candles of the macd window ordered from old to new candles,
+// signals are saved by source candles, signal of the source candle is the signal of its last brick
+func (s *MACDDivergenceStrategy) processMACD(
+	cfg *config.StrategiesConfig, key candlecache.Key, candles []bitmex.TradeBuck, size models.BinSize,
+) ([]*models.Signal, error) {
+	s.log.Infof("start process macd signals")
+	defer s.log.Infof("finish process macd signals")
+
+	err := checkCloses(candles)
+	if err != nil {
+		return nil, err
+	}
+	window := macdWindow(cfg)
+	if len(candles) < cfg.MACDLookback()+window {
+		return nil, fmt.Errorf("candles count %d less than needed for macd divergence %d",
+			len(candles), cfg.MACDLookback()+window)
+	}
+
+	macds := s.macdWindow(cfg, key, candles, window)
+
+	var signals = make([]*models.Signal, 0, window)
+	for i, macd := range macds {
+		candle := candles[len(candles)-window+i]
+		timestamp, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candle.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		signal := models.Signal{
+			MACDFast:           cfg.MacdFastCount,
+			MACDSlow:           cfg.MacdSlowCount,
+			MACDSig:            cfg.MacdSigCount,
+			BinSize:            size,
+			Timestamp:          timestamp,
+			SignalType:         models.MACD,
+			SignalValue:        macd.Sig,
+			MACDValue:          macd.Value,
+			MACDHistogramValue: macd.HistogramValue,
+		}
+		signals = append(signals, &signal)
+		if i+1 < len(macds) && candles[len(candles)-window+i+1].Timestamp == candle.Timestamp {
+			continue
+		}
+		_, err = s.db.SaveSignal(signal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return signals, nil
+}
+
+// macdWindow returns macd of the last window candles from streaming indicators,
+// macd is calculated by the candles if streaming indicators are not suitable
+func (s *MACDDivergenceStrategy) macdWindow(
+	cfg *config.StrategiesConfig, key candlecache.Key, candles []bitmex.TradeBuck, window int,
+) []trademath.MACD {
+	if macds, ok := streamMACD(s.indicators, key, cfg, candles, window); ok {
+		return macds
+	}
+	fastMAType, slowMAType, sigMAType := cfg.GetMacdMATypes()
+	macds := s.math.CalcMACDSeries(
+		fetchCloses(candles),
+		cfg.MacdFastCount, fastMAType,
+		cfg.MacdSlowCount, slowMAType,
+		cfg.MacdSigCount, sigMAType,
+	)
+	return macds[len(macds)-window:]
+}
+
+// processMACDSignals defines regular divergence of the histogram and candles closes of the same timestamps.
+// Both histogram pivots must be in the last run of the same histogram sign, the run must be not shorter
+// than the time frame and the second pivot must be the last pivot of the histogram
+func (s *MACDDivergenceStrategy) processMACDSignals(
+	binSize models.BinSize, candles []bitmex.TradeBuck, signals []*models.Signal,
+) (result macdDivergence, err error) {
+	err = s.validateDefineMechanism(candles, signals)
+	if err != nil {
+		return result, err
+	}
+	timeFrame := macdTimeFrameDefine(binSize)
+	if timeFrame == 0 || len(signals) < timeFrame {
+		return result, errors.New("time frame is 0 or count signals less than time frame")
+	}
+
+	closes := make([]float64, 0, len(candles))
+	for _, candle := range candles {
+		closes = append(closes, candle.Close)
+	}
+	hists := make([]float64, 0, len(signals))
+	for _, signal := range signals {
+		hists = append(hists, signal.MACDHistogramValue)
+	}
+
+	runStart := sameSignStart(hists)
+	if len(hists)-runStart < timeFrame {
+		s.log.Debugf("divergence not defined: histogram sign is the same less than time frame %d", timeFrame)
+		return result, nil
+	}
+
+	lastHist := hists[len(hists)-1]
+	divergType, pivots := trademath.RegularBearDivergence, trademath.PivotHighs
+	if lastHist < 0 {
+		divergType, pivots = trademath.RegularBullDivergence, trademath.PivotLows
+	}
+	if lastHist == 0 {
+		return result, nil
+	}
+	lastPivots := pivots(hists, macdPivotLookback, macdPivotLookback)
+	if len(lastPivots) < 2 {
+		s.log.Debugf("%s divergence not defined: histogram pivots count less than 2", divergType)
+		return result, nil
+	}
+
+	divergences, err := trademath.FindDivergences(closes, hists, trademath.DivergenceParams{
+		PivotLeft:  macdPivotLookback,
+		PivotRight: macdPivotLookback,
+	})
+	if err != nil {
+		return result, err
+	}
+	for _, d := range divergences {
+		if d.Type != divergType || d.First < runStart || d.Second != lastPivots[len(lastPivots)-1] {
+			continue
+		}
+		s.log.Debugf("%s divergence confirmed: %#v", divergType, d)
+		result.bearDiverg = divergType == trademath.RegularBearDivergence
+		result.bullDiverg = divergType == trademath.RegularBullDivergence
+		return result, nil
+	}
+	s.log.Debugf("%s divergence not confirmed by signals and candles", divergType)
+	return result, nil
+}
+
+// sameSignStart returns index of the first value of the last run of values with the same sign
+func sameSignStart(values []float64) int {
+	last := values[len(values)-1]
+	for i := len(values) - 2; i >= 0; i-- {
+		if (values[i] > 0) != (last > 0) || (values[i] < 0) != (last < 0) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (s *MACDDivergenceStrategy) validateDefineMechanism(
+	candles []bitmex.TradeBuck, signals []*models.Signal,
+) error {
+	if len(signals) == 0 || len(candles) == 0 {
+		return errors.New("empty signals or candles")
+	}
+	if len(signals) != len(candles) {
+		return errors.New("signals count not equal candles count")
+	}
+
+	return nil
+}
+
+// macdTimeFrameDefine returns min count of the last signals with the same histogram sign
+func macdTimeFrameDefine(size models.BinSize) int {
+	switch size {
+	case models.Bin1m, models.Bin5m:
+		return 6
+	case models.Bin15m, models.Bin30m:
+		return 5
+	case models.Bin1h, models.Bin4h:
+		return 4
+	case models.Bin1d, models.Bin1w:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestMACDDivergenceStrategy_processMACDSignals(t *testing.T) {
+	var (
+		toSignals = func(hists ...float64) []*models.Signal {
+			var result []*models.Signal
+			for _, hist := range hists {
+				result = append(result, &models.Signal{SignalType: models.MACD, MACDHistogramValue: hist})
+			}
+			return result
+		}
+		toCandles = func(closes ...float64) []bitmex.TradeBuck {
+			var result []bitmex.TradeBuck
+			for _, c := range closes {
+				result = append(result, bitmex.TradeBuck{Close: c})
+			}
+			return result
+		}
+	)
+	tests := []struct {
+		name    string
+		candles []bitmex.TradeBuck
+		signals []*models.Signal
+		want    macdDivergence
+		wantErr bool
+	}{
+		{
+			name:    "bear divergence",
+			candles: toCandles(100, 101, 102, 103, 105, 104, 103, 102),
+			signals: toSignals(-1, 2, 5, 3, 4, 2, 1, 0.5),
+			want:    macdDivergence{bearDiverg: true},
+		},
+		{
+			name:    "bull divergence",
+			candles: toCandles(100, 99, 98, 97, 95, 96, 97, 98),
+			signals: toSignals(1, -2, -5, -3, -4, -2, -1, -0.5),
+			want:    macdDivergence{bullDiverg: true},
+		},
+		{
+			name:    "bear divergence not confirmed by candles",
+			candles: toCandles(100, 101, 104, 103, 102, 101, 100, 99),
+			signals: toSignals(-1, 2, 5, 3, 4, 2, 1, 0.5),
+		},
+		{
+			name:    "histogram sign changed less than time frame ago",
+			candles: toCandles(100, 101, 102, 103, 105, 104, 103, 102),
+			signals: toSignals(1, 2, 5, 3, -4, -2, -1, -0.5),
+		},
+		{
+			name:    "histogram never crossed zero",
+			candles: toCandles(100, 102, 103, 105, 104, 103, 102, 101),
+			signals: toSignals(2, 5, 3, 4, 2, 1, 0.5, 0.3),
+			want:    macdDivergence{bearDiverg: true},
+		},
+		{
+			name:    "bull pivots before histogram crossed zero",
+			candles: toCandles(100, 99, 98, 97, 95, 96, 97, 98, 99, 100, 101),
+			signals: toSignals(-2, -5, -3, -4, -2, 1, 2, 3, 4, 5, 6),
+		},
+		{
+			name:    "hidden divergence is skipped",
+			candles: toCandles(100, 101, 104, 103, 102, 101, 100, 99),
+			signals: toSignals(-1, 2, 4, 3, 5, 2, 1, 0.5),
+		},
+		{
+			name:    "signals count not equal candles count",
+			candles: toCandles(100, 101),
+			signals: toSignals(-1, 2, 5),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MACDDivergenceStrategy{log: logrus.New()}
+			got, err := s.processMACDSignals(models.Bin5m, tt.candles, tt.signals)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
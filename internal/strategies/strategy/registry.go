@@ -0,0 +1,87 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/orderproc"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+)
+
+// Deps dependencies of strategies created by factories
+type Deps struct {
+	Configurator *config.Configurator
+	API          tradeapi.API
+	OrderProc    *orderproc.OrderProcessor
+	DB           db.DatabaseManager
+	Caches       candlecache.Caches
+	Log          *logrus.Logger
+	Indicators   IndicatorsFunc
+	Volume       VolumeFunc
+	Levels       LevelsFunc
+}
+
+// Factory creates new strategy instance with its own state
+type Factory func(deps Deps) (Strategy, error)
+
+var (
+	registryMx sync.Mutex
+	registry   = make(map[string]Factory)
+)
+
+// Register registers factory of the strategy type, it is called from init of the strategy file.
+// Registration of the same type twice panics
+func Register(strategyType string, factory Factory) {
+	registryMx.Lock()
+	defer registryMx.Unlock()
+	if _, ok := registry[strategyType]; ok {
+		panic(fmt.Sprintf("strategy type %s already registered", strategyType))
+	}
+	registry[strategyType] = factory
+}
+
+// New creates strategy of the registered type
+func New(strategyType string, deps Deps) (Strategy, error) {
+	registryMx.Lock()
+	factory, ok := registry[strategyType]
+	registryMx.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("strategy type %s not registered", strategyType)
+	}
+	return factory(deps)
+}
+
+// Types returns sorted registered strategy types
+func Types() []string {
+	registryMx.Lock()
+	defer registryMx.Unlock()
+	result := make([]string, 0, len(registry))
+	for strategyType := range registry {
+		result = append(result, strategyType)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// WithConfig returns context with strategies config of the executed strategy instance
+func WithConfig(ctx context.Context, cfg *config.StrategiesConfig) context.Context {
+	return context.WithValue(ctx, stratypes.StrategiesConfigKey, cfg)
+}
+
+// getConfig returns strategies config of the strategy instance from the context,
+// strategies config of the bin size if the context has not config
+func getConfig(ctx context.Context, scfg *config.GlobalConfig, size models.BinSize) *config.StrategiesConfig {
+	if cfg, ok := ctx.Value(stratypes.StrategiesConfigKey).(*config.StrategiesConfig); ok && cfg != nil {
+		return cfg
+	}
+	return scfg.GlobStrategies.GetCfgByBinSize(size.String())
+}
@@ -0,0 +1,53 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+)
+
+type testStrategy struct {
+	executed int
+}
+
+func (s *testStrategy) Execute(ctx context.Context, size models.BinSize) error {
+	s.executed++
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	Register("test_registry", func(deps Deps) (Strategy, error) {
+		return &testStrategy{}, nil
+	})
+	require.Contains(t, Types(), "test_registry")
+	require.Contains(t, Types(), config.BBRSIStrategyType)
+
+	first, err := New("test_registry", Deps{})
+	require.NoError(t, err)
+	second, err := New("test_registry", Deps{})
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	_, err = New("unknown", Deps{})
+	require.Error(t, err)
+
+	require.Panics(t, func() {
+		Register("test_registry", func(deps Deps) (Strategy, error) {
+			return &testStrategy{}, nil
+		})
+	})
+}
+
+func TestGetConfig(t *testing.T) {
+	binCfg := &config.StrategiesConfig{RsiCount: 14}
+	scfg := &config.GlobalConfig{GlobStrategies: config.StrategiesGlobConfig{M5: binCfg}}
+	require.Same(t, binCfg, getConfig(context.Background(), scfg, models.Bin5m))
+
+	instanceCfg := &config.StrategiesConfig{RsiCount: 7}
+	ctx := WithConfig(context.Background(), instanceCfg)
+	require.Same(t, instanceCfg, getConfig(ctx, scfg, models.Bin5m))
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"time"
 
-	"github.com/markcheno/go-talib"
 	"github.com/sirupsen/logrus"
 
 	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
@@ -21,6 +20,16 @@ import (
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
 )
 
+func init() {
+	Register(config.BBRSIStrategyType, func(deps Deps) (Strategy, error) {
+		s := NewBBRSIStrategy(deps.Configurator, deps.API, deps.OrderProc, deps.DB, deps.Caches, deps.Log)
+		s.indicators = deps.Indicators
+		s.volume = deps.Volume
+		s.levels = deps.Levels
+		return s, nil
+	})
+}
+
 type BBRSIStrategy struct {
 	configurator *config.Configurator
 	api          tradeapi.API
@@ -29,6 +38,9 @@ type BBRSIStrategy struct {
 	log          *logrus.Logger
 	db           db.DatabaseManager
 	caches       candlecache.Caches
+	indicators   IndicatorsFunc
+	volume       VolumeFunc
+	levels       LevelsFunc
 	filters      []filter.Filter
 }
 
@@ -53,7 +65,7 @@ func NewBBRSIStrategy(
 	}
 }
 
-func (s *BBRSIStrategy) Execute(_ context.Context, size models.BinSize) error {
+func (s *BBRSIStrategy) Execute(ctx context.Context, size models.BinSize) error {
 	s.log.Infof("start execute bb rsi strategy")
 	defer s.log.Infof("finish execute bb rsi strategy")
 
@@ -62,29 +74,25 @@ func (s *BBRSIStrategy) Execute(_ context.Context, size models.BinSize) error {
 		s.log.Fatal(err)
 	}
 
-	cfg := scfg.GlobStrategies.GetCfgByBinSize(size.String())
+	cfg := getConfig(ctx, scfg, size)
 	if cfg == nil {
 		return errors.New("cfg by bin size is empty")
 	}
 
-	if cfg.CandlesFilterEnable && len(s.filters) == 0 {
-		s.filters = append(s.filters, filter.NewCandlesFilter(scfg, s.log))
-	}
-	if cfg.TrendFilterEnable && len(s.filters) == 0 {
-		s.filters = append(s.filters, filter.NewTrendFilter(scfg, s.log))
-	}
+	s.filters = initFilters(s.filters, scfg, cfg, s.log)
 
-	candles, err := s.getCandles(scfg, size)
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(scfg.ExchangesSettings.Bitmex.Symbol), size)
+	candles, err := s.getCandles(cfg, key)
 	if err != nil {
 		return err
 	}
 
-	rsi, err := s.processRsi(scfg, candles, size)
+	rsi, err := s.processRsi(cfg, key, candles, size)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.processBB(scfg, candles, size)
+	_, err = s.processBB(cfg, key, candles, size)
 	if err != nil {
 		return err
 	}
@@ -96,7 +104,7 @@ func (s *BBRSIStrategy) Execute(_ context.Context, size models.BinSize) error {
 		action        stratypes.Action
 	)
 	if rsi.Value >= float64(cfg.RsiMaxBorder) || rsi.Value <= float64(cfg.RsiMinBorder) {
-		lastCandles = s.fetchLastCandlesForBB(scfg, size.String(), candles)
+		lastCandles = s.fetchLastCandlesForBB(cfg, candles)
 		if len(lastCandles) == 0 {
 			err := errors.New("processBB last candles fo BB signal is empty")
 			s.log.Debug(err)
@@ -116,52 +124,26 @@ func (s *BBRSIStrategy) Execute(_ context.Context, size models.BinSize) error {
 		action = s.processTrend(size, lastCandles, lastSignals)
 	}
 
-	applySide := s.ApplyFilters(action, candles, size)
+	applySide := s.ApplyFilters(cfg, action, candles, key)
 	if applySide != types.SideBuy && applySide != types.SideSell {
 		return nil
 	}
 
-	return placeBitmexOrder(s.orderProc, applySide, true, s.log)
+	return placeBitmexOrder(s.orderProc, applySide, 0, true, s.log)
 }
 
 func (s *BBRSIStrategy) ApplyFilters(
-	action stratypes.Action, candles []bitmex.TradeBuck, size models.BinSize,
+	cfg *config.StrategiesConfig, action stratypes.Action, candles []bitmex.TradeBuck, key candlecache.Key,
 ) types.Side {
-	if len(s.filters) == 0 {
-		s.log.Warnf("filters not installed")
-		switch action {
-		case stratypes.UpTrend:
-			return types.SideSell
-		case stratypes.DownTrend:
-			return types.SideBuy
-		default:
-			return types.SideEmpty
-		}
-	}
-	ctx := context.WithValue(context.Background(), stratypes.ActionKey, action)
-	ctx = context.WithValue(ctx, stratypes.CandlesKey, candles)
-	ctx = context.WithValue(ctx, stratypes.BinSizeKey, size)
-	applySide := types.SideEmpty
-	for _, f := range s.filters {
-		applySide = f.Apply(ctx)
-		if applySide == types.SideEmpty {
-			return applySide
-		}
-	}
-	return applySide
+	return applyFilters(s.filters, cfg, action, candles, key, s.volume, s.levels, s.log)
 }
 
 func (s *BBRSIStrategy) processRsi(
-	scfg *config.GlobalConfig, candles []bitmex.TradeBuck, size models.BinSize,
+	cfg *config.StrategiesConfig, key candlecache.Key, candles []bitmex.TradeBuck, size models.BinSize,
 ) (rsi trademath.RSI, err error) {
 	s.log.Infof("start process rsi signal")
 	defer s.log.Infof("finish process rsi signal")
 
-	cfg := scfg.GlobStrategies.GetCfgByBinSize(size.String())
-	if cfg == nil {
-		return rsi, errors.New("cfg by bin size is empty")
-	}
-
 	err = checkCloses(candles)
 	if err != nil {
 		return rsi, err
@@ -179,7 +161,11 @@ func (s *BBRSIStrategy) processRsi(
 		return rsi, err
 	}
 
-	rsi = s.math.CalcRSI(closes, cfg.RsiCount)
+	if streamed, ok := streamRSI(s.indicators, key, cfg, candles); ok {
+		rsi.Value = streamed.current
+	} else {
+		rsi = s.math.CalcRSI(closes, cfg.RsiCount)
+	}
 	_, err = s.db.SaveSignal(models.Signal{
 		N:           cfg.RsiCount,
 		BinSize:     size,
@@ -194,12 +180,8 @@ func (s *BBRSIStrategy) processRsi(
 }
 
 func (s *BBRSIStrategy) processBB(
-	scfg *config.GlobalConfig, candles []bitmex.TradeBuck, size models.BinSize,
+	cfg *config.StrategiesConfig, key candlecache.Key, candles []bitmex.TradeBuck, size models.BinSize,
 ) (bb trademath.BB, err error) {
-	cfg := scfg.GlobStrategies.GetCfgByBinSize(size.String())
-	if cfg == nil {
-		return bb, errors.New("cfg by bin size is empty")
-	}
 	if len(candles) < cfg.BBLastCandlesCount {
 		s.log.Debug("processBBStrategyCandles there are fewer candles than necessary for the signal bolinger band")
 		return bb, errors.New("there are fewer candles than necessary for the signal bolinger band")
@@ -217,23 +199,23 @@ func (s *BBRSIStrategy) processBB(
 		s.log.Debugf("processBBStrategyCandles last candle timestamp parse error: %v", err)
 		return bb, err
 	}
-	tl, ml, bl := s.math.CalcBB(closes, talib.EMA)
+	bb, ok := streamBB(s.indicators, key, cfg, candles)
+	if !ok {
+		bb.TL, bb.ML, bb.BL = s.math.CalcBB(closes, cfg.GetBBPeriod(), cfg.GetBBDeviation(), cfg.GetBBMAType())
+	}
 	_, err = s.db.SaveSignal(models.Signal{
-		N:          cfg.GetCandlesCount,
+		N:          cfg.GetBBPeriod(),
 		BinSize:    size,
 		Timestamp:  lastCandleTS,
 		SignalType: models.BolingerBand,
-		BBTL:       tl,
-		BBML:       ml,
-		BBBL:       bl,
+		BBTL:       bb.TL,
+		BBML:       bb.ML,
+		BBBL:       bb.BL,
 	})
 	if err != nil {
 		s.log.Debugf("saveSignals db.SaveSignal bolinger band error: %v", err)
 		return bb, err
 	}
-	bb.TL = tl
-	bb.ML = ml
-	bb.BL = bl
 	return bb, nil
 }
 
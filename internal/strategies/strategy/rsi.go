@@ -0,0 +1,240 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/orderproc"
+	"github.com/tagirmukail/tccbot-backend/internal/strategies/filter"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+const rsiMidline = 50
+
+func init() {
+	Register(config.RSIStrategyType, func(deps Deps) (Strategy, error) {
+		s := NewRSIStrategy(deps.Configurator, deps.OrderProc, deps.DB, deps.Caches, deps.Log)
+		s.indicators = deps.Indicators
+		s.volume = deps.Volume
+		s.levels = deps.Levels
+		return s, nil
+	})
+}
+
+// RSIStrategy sells on overbought and buys on oversold rsi, closes the position on rsi midline cross
+type RSIStrategy struct {
+	configurator *config.Configurator
+	math         trademath.Calc
+	orderProc    *orderproc.OrderProcessor
+	log          *logrus.Logger
+	db           db.DatabaseManager
+	caches       candlecache.Caches
+	indicators   IndicatorsFunc
+	volume       VolumeFunc
+	levels       LevelsFunc
+	filters      []filter.Filter
+}
+
+// rsiValues rsi of the previous and the last candles
+type rsiValues struct {
+	prev    float64
+	current float64
+}
+
+func NewRSIStrategy(
+	configurator *config.Configurator,
+	orderProc *orderproc.OrderProcessor,
+	db db.DatabaseManager,
+	caches candlecache.Caches,
+	log *logrus.Logger,
+	filters ...filter.Filter,
+) *RSIStrategy {
+	return &RSIStrategy{
+		configurator: configurator,
+		orderProc:    orderProc,
+		db:           db,
+		log:          log,
+		math:         trademath.Calc{},
+		caches:       caches,
+		filters:      filters,
+	}
+}
+
+func (s *RSIStrategy) Execute(ctx context.Context, size models.BinSize) error {
+	s.log.Infof("start execute rsi strategy")
+	defer s.log.Infof("finish execute rsi strategy")
+
+	scfg, err := s.configurator.GetConfig()
+	if err != nil {
+		s.log.Fatal(err)
+	}
+
+	cfg := getConfig(ctx, scfg, size)
+	if cfg == nil {
+		return errors.New("cfg by bin size is empty")
+	}
+
+	s.filters = initFilters(s.filters, scfg, cfg, s.log)
+
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(scfg.ExchangesSettings.Bitmex.Symbol), size)
+	candles, err := getSeries(s.caches, key, cfg, rsiCandlesCount(cfg))
+	if err != nil {
+		return err
+	}
+
+	rsi, err := s.processRsi(cfg, key, candles, size)
+	if err != nil {
+		return err
+	}
+
+	if cfg.RsiExitMidline {
+		err = s.exit(rsi)
+		if err != nil {
+			return err
+		}
+	}
+
+	applySide := applyFilters(s.filters, cfg, rsiAction(cfg, rsi), candles, key, s.volume, s.levels, s.log)
+	if applySide != types.SideBuy && applySide != types.SideSell {
+		return nil
+	}
+
+	var qty int64
+	if cfg.RsiTradeCoef > 0 {
+		qty, err = s.orderProc.OrderQty(applySide, cfg.RsiTradeCoef, true)
+		if err != nil {
+			return err
+		}
+	}
+	return placeBitmexOrder(s.orderProc, applySide, float64(qty), true, s.log)
+}
+
+// rsiCandlesCount count of candles for rsi of the previous and the last candles
+func rsiCandlesCount(cfg *config.StrategiesConfig) int {
+	if cfg.RsiCount > cfg.GetCandlesCount {
+		return cfg.RsiCount*2 + 1
+	}
+	return cfg.GetCandlesCount*2 + 1
+}
+
+// processRsi saves rsi signal of the last candle and returns rsi of the previous and the last candles
+func (s *RSIStrategy) processRsi(
+	cfg *config.StrategiesConfig, key candlecache.Key, candles []bitmex.TradeBuck, size models.BinSize,
+) (rsi rsiValues, err error) {
+	rsi, err = lastRSIValues(&s.math, s.indicators, key, cfg, candles)
+	if err != nil {
+		return rsi, err
+	}
+
+	timestamp, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candles[len(candles)-1].Timestamp)
+	if err != nil {
+		return rsi, err
+	}
+
+	_, err = s.db.SaveSignal(models.Signal{
+		N:           cfg.RsiCount,
+		BinSize:     size,
+		Timestamp:   timestamp,
+		SignalType:  models.RSI,
+		SignalValue: rsi.current,
+	})
+	if err != nil {
+		return rsi, err
+	}
+	s.log.Debugf("rsi strategy prev rsi: %v, rsi: %v", rsi.prev, rsi.current)
+	return rsi, nil
+}
+
+// lastRSIValues returns rsi of the previous and the last candles from streaming indicators,
+// rsi is calculated by the candles if streaming indicators are not suitable
+func lastRSIValues(
+	calc *trademath.Calc, indicators IndicatorsFunc, key candlecache.Key, cfg *config.StrategiesConfig,
+	candles []bitmex.TradeBuck,
+) (rsiValues, error) {
+	if rsi, ok := streamRSI(indicators, key, cfg, candles); ok {
+		return rsi, nil
+	}
+	err := checkCloses(candles)
+	if err != nil {
+		return rsiValues{}, err
+	}
+	return calcRSIValues(calc, fetchCloses(candles), cfg.RsiCount)
+}
+
+// calcRSIValues returns rsi of the previous and the last closes
+func calcRSIValues(calc *trademath.Calc, closes []float64, count int) (rsiValues, error) {
+	if len(closes) <= count+1 {
+		return rsiValues{}, fmt.Errorf("closes count %d not enough for rsi count %d", len(closes), count)
+	}
+	return rsiValues{
+		prev:    calc.CalcRSI(closes[:len(closes)-1], count).Value,
+		current: calc.CalcRSI(closes, count).Value,
+	}, nil
+}
+
+// exit closes the current position if rsi crossed the midline
+func (s *RSIStrategy) exit(rsi rsiValues) error {
+	position, ok := s.orderProc.GetPosition()
+	if !ok {
+		return nil
+	}
+	side := rsiExitSide(position.CurrentQty, rsi)
+	if side == types.SideEmpty {
+		return nil
+	}
+	qty := position.CurrentQty
+	if qty < 0 {
+		qty = -qty
+	}
+	s.log.Infof("rsi crossed midline, close position %d by %s order", position.CurrentQty, side)
+	return placeBitmexOrder(s.orderProc, side, float64(qty), false, s.log)
+}
+
+// rsiAction returns up trend for overbought rsi and down trend for oversold rsi,
+// with cross confirmation rsi must cross back through the border
+func rsiAction(cfg *config.StrategiesConfig, rsi rsiValues) stratypes.Action {
+	maxBorder, minBorder := float64(cfg.RsiMaxBorder), float64(cfg.RsiMinBorder)
+	if cfg.RsiCrossConfirm {
+		switch {
+		case rsi.prev >= maxBorder && rsi.current < maxBorder:
+			return stratypes.UpTrend
+		case rsi.prev <= minBorder && rsi.current > minBorder:
+			return stratypes.DownTrend
+		default:
+			return stratypes.NotTrend
+		}
+	}
+	switch {
+	case rsi.current >= maxBorder:
+		return stratypes.UpTrend
+	case rsi.current <= minBorder:
+		return stratypes.DownTrend
+	default:
+		return stratypes.NotTrend
+	}
+}
+
+// rsiExitSide returns side of the order closing long position when rsi crossed the midline up
+// and short position when rsi crossed the midline down
+func rsiExitSide(positionQty int64, rsi rsiValues) types.Side {
+	switch {
+	case positionQty > 0 && rsi.prev < rsiMidline && rsi.current >= rsiMidline:
+		return types.SideSell
+	case positionQty < 0 && rsi.prev > rsiMidline && rsi.current <= rsiMidline:
+		return types.SideBuy
+	default:
+		return types.SideEmpty
+	}
+}
@@ -0,0 +1,54 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+)
+
+func TestRsiAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm bool
+		rsi     rsiValues
+		want    stratypes.Action
+	}{
+		{name: "overbought", rsi: rsiValues{prev: 65, current: 72}, want: stratypes.UpTrend},
+		{name: "oversold", rsi: rsiValues{prev: 35, current: 30}, want: stratypes.DownTrend},
+		{name: "between borders", rsi: rsiValues{prev: 72, current: 68}, want: stratypes.NotTrend},
+		{name: "overbought not confirmed", confirm: true, rsi: rsiValues{prev: 65, current: 72}, want: stratypes.NotTrend},
+		{name: "overbought confirmed", confirm: true, rsi: rsiValues{prev: 72, current: 68}, want: stratypes.UpTrend},
+		{name: "oversold not confirmed", confirm: true, rsi: rsiValues{prev: 28, current: 25}, want: stratypes.NotTrend},
+		{name: "oversold confirmed", confirm: true, rsi: rsiValues{prev: 30, current: 33}, want: stratypes.DownTrend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.StrategiesConfig{RsiMinBorder: 30, RsiMaxBorder: 70, RsiCrossConfirm: tt.confirm}
+			require.Equal(t, tt.want, rsiAction(cfg, tt.rsi))
+		})
+	}
+}
+
+func TestRsiExitSide(t *testing.T) {
+	tests := []struct {
+		name        string
+		positionQty int64
+		rsi         rsiValues
+		want        types.Side
+	}{
+		{name: "long crossed up", positionQty: 100, rsi: rsiValues{prev: 45, current: 52}, want: types.SideSell},
+		{name: "long crossed down", positionQty: 100, rsi: rsiValues{prev: 55, current: 48}, want: types.SideEmpty},
+		{name: "short crossed down", positionQty: -100, rsi: rsiValues{prev: 55, current: 50}, want: types.SideBuy},
+		{name: "short not crossed", positionQty: -100, rsi: rsiValues{prev: 65, current: 55}, want: types.SideEmpty},
+		{name: "empty position", rsi: rsiValues{prev: 45, current: 52}, want: types.SideEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, rsiExitSide(tt.positionQty, tt.rsi))
+		})
+	}
+}
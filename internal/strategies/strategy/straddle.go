@@ -0,0 +1,420 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/orderproc"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func init() {
+	Register(config.StraddleStrategyType, func(deps Deps) (Strategy, error) {
+		s := NewStraddleStrategy(deps.Configurator, deps.OrderProc, deps.Caches, deps.Log)
+		s.indicators = deps.Indicators
+		return s, nil
+	})
+}
+
+// StraddleStrategy places linked passive buy and sell orders around the mid price when rsi signal fires,
+// order of the signal side is larger. Orders are amended as the price moves until both of them are closed
+type StraddleStrategy struct {
+	configurator *config.Configurator
+	math         trademath.Calc
+	orderProc    straddleOrderProc
+	log          *logrus.Logger
+	caches       candlecache.Caches
+	indicators   IndicatorsFunc
+	pair         straddlePair
+}
+
+// straddleOrderProc orders, instrument and position of the order processor used by the straddle strategy
+type straddleOrderProc interface {
+	GetActiveOrders(symbol string) ([]bitmex.OrderCopied, error)
+	GetInstrument() (bitmex.Instrument, error)
+	GetContract() (trademath.Contract, error)
+	GetPosition() (*bitmex.Position, bool)
+	OrderQty(side types.Side, coef float64, passive bool) (int64, error)
+	PlaceLimitOrder(side types.Side, qty int64, price money.Decimal, clOrdID string) (bitmex.OrderCopied, error)
+	AmendOrder(params *bitmex.OrderAmendParams) (bitmex.OrderCopied, error)
+	CancelOrder(orderID, text string) error
+}
+
+// straddleClOrdIDPrefix prefix of client order ids of straddle orders, the id is straddle-<pair id>-<side>
+const straddleClOrdIDPrefix = "straddle-"
+
+// straddlePair buy and sell orders placed together, order id is empty if the order is not active
+type straddlePair struct {
+	id   string
+	buy  bitmex.OrderCopied
+	sell bitmex.OrderCopied
+}
+
+func (p *straddlePair) empty() bool {
+	return p.buy.OrderID == "" && p.sell.OrderID == ""
+}
+
+// order returns order of the side
+func (p *straddlePair) order(side types.Side) *bitmex.OrderCopied {
+	if side == types.SideBuy {
+		return &p.buy
+	}
+	return &p.sell
+}
+
+func NewStraddleStrategy(
+	configurator *config.Configurator,
+	orderProc *orderproc.OrderProcessor,
+	caches candlecache.Caches,
+	log *logrus.Logger,
+) *StraddleStrategy {
+	return &StraddleStrategy{
+		configurator: configurator,
+		orderProc:    orderProc,
+		log:          log,
+		math:         trademath.Calc{},
+		caches:       caches,
+	}
+}
+
+func (s *StraddleStrategy) Execute(ctx context.Context, size models.BinSize) error {
+	s.log.Infof("start execute straddle strategy")
+	defer s.log.Infof("finish execute straddle strategy")
+
+	scfg, err := s.configurator.GetConfig()
+	if err != nil {
+		s.log.Fatal(err)
+	}
+
+	cfg := getConfig(ctx, scfg, size)
+	if cfg == nil {
+		return errors.New("cfg by bin size is empty")
+	}
+
+	err = s.syncPair(scfg.ExchangesSettings.Bitmex.Symbol)
+	if err != nil {
+		return err
+	}
+	inst, err := s.orderProc.GetInstrument()
+	if err != nil {
+		return err
+	}
+	contract, err := s.orderProc.GetContract()
+	if err != nil {
+		return err
+	}
+	var positionQty int64
+	if position, ok := s.orderProc.GetPosition(); ok {
+		positionQty = position.CurrentQty
+	}
+	limit := int64(scfg.ExchangesSettings.Bitmex.LimitContractsCount)
+
+	if !s.pair.empty() {
+		return s.requote(cfg, contract, inst, positionQty, limit)
+	}
+
+	side, err := s.signalSide(scfg, cfg, size)
+	if err != nil {
+		return err
+	}
+	if side == types.SideEmpty {
+		return nil
+	}
+	coef := cfg.StraddleTradeCoef
+	if coef == 0 {
+		coef = scfg.ExchangesSettings.Bitmex.BuyOrderCoef
+		if side == types.SideSell {
+			coef = scfg.ExchangesSettings.Bitmex.SellOrderCoef
+		}
+	}
+	total, err := s.orderProc.OrderQty(side, coef, true)
+	if err != nil {
+		return err
+	}
+	return s.placePair(cfg, contract, inst, side, total, positionQty, limit)
+}
+
+// signalSide returns buy side for oversold rsi and sell side for overbought rsi
+func (s *StraddleStrategy) signalSide(
+	scfg *config.GlobalConfig, cfg *config.StrategiesConfig, size models.BinSize,
+) (types.Side, error) {
+	key := candlecache.NewKey(types.Bitmex, types.Symbol(scfg.ExchangesSettings.Bitmex.Symbol), size)
+	candles, err := getSeries(s.caches, key, cfg, rsiCandlesCount(cfg))
+	if err != nil {
+		return types.SideEmpty, err
+	}
+	rsi, err := lastRSIValues(&s.math, s.indicators, key, cfg, candles)
+	if err != nil {
+		return types.SideEmpty, err
+	}
+	switch rsiAction(cfg, rsi) {
+	case stratypes.UpTrend:
+		return types.SideSell, nil
+	case stratypes.DownTrend:
+		return types.SideBuy, nil
+	default:
+		return types.SideEmpty, nil
+	}
+}
+
+// syncPair updates orders of the pair by active orders, closed orders are removed from the pair.
+// Empty pair is restored from active straddle orders, so orders placed before restart are not orphaned
+func (s *StraddleStrategy) syncPair(symbol string) error {
+	orders, err := s.orderProc.GetActiveOrders(symbol)
+	if err != nil {
+		return err
+	}
+	if s.pair.empty() {
+		s.pair = restorePair(orders)
+		if !s.pair.empty() {
+			s.log.Infof("straddle %s restored: buy order %q, sell order %q", s.pair.id, s.pair.buy.OrderID, s.pair.sell.OrderID)
+		}
+		return nil
+	}
+	active := make(map[string]bitmex.OrderCopied, len(orders))
+	for _, ord := range orders {
+		active[ord.OrderID] = ord
+	}
+	for _, side := range []types.Side{types.SideBuy, types.SideSell} {
+		ord := s.pair.order(side)
+		if ord.OrderID == "" {
+			continue
+		}
+		if actual, ok := active[ord.OrderID]; ok {
+			*ord = actual
+			continue
+		}
+		s.log.Infof("straddle %s %s order %s closed", s.pair.id, side, ord.OrderID)
+		*ord = bitmex.OrderCopied{}
+	}
+	return nil
+}
+
+// placePair places buy and sell orders of the new pair, the order of the signal side is placed first
+func (s *StraddleStrategy) placePair(
+	cfg *config.StrategiesConfig, contract trademath.Contract, inst bitmex.Instrument,
+	signal types.Side, total, positionQty, limit int64,
+) error {
+	buyQty, sellQty := straddleQty(total, cfg.GetStraddleSkew(), signal, positionQty, limit, contract.LotSize)
+	buyPrice, sellPrice := straddlePrices(contract, inst, cfg.StraddleSpreadTicks)
+	s.pair = straddlePair{id: strconv.FormatInt(time.Now().UnixNano(), 36)}
+
+	sides := []types.Side{types.SideBuy, types.SideSell}
+	if signal == types.SideSell {
+		sides = []types.Side{types.SideSell, types.SideBuy}
+	}
+	for _, side := range sides {
+		qty, price := buyQty, buyPrice
+		if side == types.SideSell {
+			qty, price = sellQty, sellPrice
+		}
+		if qty <= 0 {
+			s.log.Infof("straddle %s %s order skipped, contracts limit %d reached", s.pair.id, side, limit)
+			continue
+		}
+		ord, err := s.orderProc.PlaceLimitOrder(side, qty, price, straddleClOrdID(s.pair.id, side))
+		if err != nil {
+			err = fmt.Errorf("place straddle %s %s order: %v", s.pair.id, side, err)
+			return s.cancelPair(err)
+		}
+		s.log.Infof("straddle %s %s order placed: qty %d, price %s", s.pair.id, side, qty, price)
+		*s.pair.order(side) = ord
+	}
+	return nil
+}
+
+// cancelPair cancels placed orders of the pair after the failed placing, so the pair is not left with one leg.
+// Order which failed to cancel is kept in the pair and is synced by the next execution
+func (s *StraddleStrategy) cancelPair(placeErr error) error {
+	for _, side := range []types.Side{types.SideBuy, types.SideSell} {
+		ord := s.pair.order(side)
+		if ord.OrderID == "" {
+			continue
+		}
+		err := s.orderProc.CancelOrder(ord.OrderID, "straddle pair is not placed")
+		if err != nil {
+			return fmt.Errorf("%v, cancel straddle %s %s order %s: %v", placeErr, s.pair.id, side, ord.OrderID, err)
+		}
+		s.log.Infof("straddle %s %s order %s canceled, pair is not placed", s.pair.id, side, ord.OrderID)
+		*ord = bitmex.OrderCopied{}
+	}
+	return placeErr
+}
+
+// requote amends active orders of the pair if the price moved or exposure of the order exceeds the limit
+func (s *StraddleStrategy) requote(
+	cfg *config.StrategiesConfig, contract trademath.Contract, inst bitmex.Instrument, positionQty, limit int64,
+) error {
+	buyPrice, sellPrice := straddlePrices(contract, inst, cfg.StraddleSpreadTicks)
+	buyRoom, sellRoom := straddleRoom(positionQty, limit, contract.LotSize)
+	requoteStep := contract.TickSize.Mul(money.FromInt(int64(cfg.GetStraddleRequoteTicks())))
+
+	for _, side := range []types.Side{types.SideBuy, types.SideSell} {
+		ord := s.pair.order(side)
+		if ord.OrderID == "" {
+			continue
+		}
+		price, room := buyPrice, buyRoom
+		if side == types.SideSell {
+			price, room = sellPrice, sellRoom
+		}
+		if room <= 0 {
+			err := s.orderProc.CancelOrder(ord.OrderID, "straddle contracts limit reached")
+			if err != nil {
+				return err
+			}
+			s.log.Infof("straddle %s %s order %s canceled, contracts limit %d reached", s.pair.id, side, ord.OrderID, limit)
+			*ord = bitmex.OrderCopied{}
+			continue
+		}
+
+		params := &bitmex.OrderAmendParams{OrderID: ord.OrderID, Text: "amend order - straddle requote"}
+		if price.Sub(money.FromFloat(ord.Price)).Abs().Cmp(requoteStep) >= 0 {
+			params.Price = price.Float64()
+		}
+		if ord.LeavesQty > room {
+			params.LeavesQuantity = int32(room)
+		}
+		if params.Price == 0 && params.LeavesQuantity == 0 {
+			continue
+		}
+		amended, err := s.orderProc.AmendOrder(params)
+		if err != nil {
+			return err
+		}
+		s.log.Infof("straddle %s %s order %s amended: price %v, leaves qty %d",
+			s.pair.id, side, amended.OrderID, amended.Price, amended.LeavesQty)
+		*ord = amended
+	}
+	return nil
+}
+
+func straddleClOrdID(pairID string, side types.Side) string {
+	return fmt.Sprintf("%s%s-%s", straddleClOrdIDPrefix, pairID, side)
+}
+
+// parseStraddleClOrdID returns pair id and side of the straddle order, ok is false for other orders
+func parseStraddleClOrdID(clOrdID string) (pairID string, side types.Side, ok bool) {
+	if !strings.HasPrefix(clOrdID, straddleClOrdIDPrefix) {
+		return "", types.SideEmpty, false
+	}
+	rest := strings.TrimPrefix(clOrdID, straddleClOrdIDPrefix)
+	i := strings.LastIndexByte(rest, '-')
+	if i <= 0 {
+		return "", types.SideEmpty, false
+	}
+	pairID, side = rest[:i], types.Side(rest[i+1:])
+	if side != types.SideBuy && side != types.SideSell {
+		return "", types.SideEmpty, false
+	}
+	return pairID, side, true
+}
+
+// restorePair returns the pair of the last placed straddle order among active orders,
+// orders are sorted by time of placing
+func restorePair(orders []bitmex.OrderCopied) straddlePair {
+	var pair straddlePair
+	for i := len(orders) - 1; i >= 0; i-- {
+		pairID, side, ok := parseStraddleClOrdID(orders[i].ClOrdID)
+		if !ok || (pair.id != "" && pair.id != pairID) {
+			continue
+		}
+		pair.id = pairID
+		if pair.order(side).OrderID == "" {
+			*pair.order(side) = orders[i]
+		}
+	}
+	return pair
+}
+
+// straddlePrices returns prices of the buy and sell orders spreadTicks away from the mid price,
+// buy price is not greater than bid price and sell price is not lower than ask price, so both orders are passive
+func straddlePrices(contract trademath.Contract, inst bitmex.Instrument, spreadTicks int) (buy, sell money.Decimal) {
+	bid, ask := money.FromFloat(inst.BidPrice), money.FromFloat(inst.AskPrice)
+	mid := money.FromFloat(inst.MidPrice)
+	if mid.IsZero() {
+		mid = bid.Add(ask).Quo(money.FromInt(2))
+	}
+	offset := contract.TickSize.Mul(money.FromInt(int64(spreadTicks)))
+
+	buy = mid.Sub(offset).TruncStep(contract.TickSize)
+	if bid.Sign() > 0 && buy.Cmp(bid) > 0 {
+		buy = bid
+	}
+	sell = mid.Add(offset).TruncStep(contract.TickSize)
+	if sell.Cmp(mid.Add(offset)) < 0 {
+		sell = sell.Add(contract.TickSize)
+	}
+	if ask.Sign() > 0 && sell.Cmp(ask) < 0 {
+		sell = ask
+	}
+	return buy, sell
+}
+
+// straddleQty splits total contracts between buy and sell orders, the signal side gets skew part of total.
+// Signal quantity is rounded up to the lot size, so the signal side is never smaller than the other side,
+// quantities are limited so that the position with the filled order does not exceed limit contracts
+func straddleQty(total int64, skew float64, signal types.Side, positionQty, limit, lotSize int64) (buy, sell int64) {
+	part := money.FromInt(total).Mul(money.FromFloat(skew))
+	signalQty := part.Int64()
+	if money.FromInt(signalQty).Cmp(part) < 0 {
+		signalQty++
+	}
+	signalQty = roundUpToLot(signalQty, lotSize)
+	if signalQty > total {
+		signalQty = roundDownToLot(total, lotSize)
+	}
+	otherQty := roundDownToLot(total-signalQty, lotSize)
+	buy, sell = signalQty, otherQty
+	if signal == types.SideSell {
+		buy, sell = otherQty, signalQty
+	}
+
+	buyRoom, sellRoom := straddleRoom(positionQty, limit, lotSize)
+	if buy > buyRoom {
+		buy = buyRoom
+	}
+	if sell > sellRoom {
+		sell = sellRoom
+	}
+	return buy, sell
+}
+
+// straddleRoom returns contracts which can be bought and sold without exceeding the limit by the position
+func straddleRoom(positionQty, limit, lotSize int64) (buy, sell int64) {
+	buy, sell = roundDownToLot(limit-positionQty, lotSize), roundDownToLot(limit+positionQty, lotSize)
+	if buy < 0 {
+		buy = 0
+	}
+	if sell < 0 {
+		sell = 0
+	}
+	return buy, sell
+}
+
+func roundDownToLot(qty, lotSize int64) int64 {
+	if lotSize <= 1 || qty <= 0 {
+		return qty
+	}
+	return qty / lotSize * lotSize
+}
+
+func roundUpToLot(qty, lotSize int64) int64 {
+	if lotSize <= 1 || qty <= 0 {
+		return qty
+	}
+	return (qty + lotSize - 1) / lotSize * lotSize
+}
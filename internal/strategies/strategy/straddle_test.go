@@ -0,0 +1,405 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestStraddlePrices(t *testing.T) {
+	tests := []struct {
+		name        string
+		inst        bitmex.Instrument
+		spreadTicks int
+		wantBuy     money.Decimal
+		wantSell    money.Decimal
+	}{
+		{
+			name:        "around mid",
+			inst:        bitmex.Instrument{BidPrice: 9000, MidPrice: 9000.25, AskPrice: 9000.5},
+			spreadTicks: 4,
+			wantBuy:     money.FromInt(8998),
+			wantSell:    money.New(90025, -1),
+		},
+		{
+			name:     "not crossing the spread",
+			inst:     bitmex.Instrument{BidPrice: 9000, MidPrice: 9000.25, AskPrice: 9000.5},
+			wantBuy:  money.FromInt(9000),
+			wantSell: money.New(90005, -1),
+		},
+		{
+			name:        "mid by bid and ask",
+			inst:        bitmex.Instrument{BidPrice: 9000, AskPrice: 9001},
+			spreadTicks: 2,
+			wantBuy:     money.New(89995, -1),
+			wantSell:    money.New(90015, -1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buy, sell := straddlePrices(trademath.XBTUSD, tt.inst, tt.spreadTicks)
+			require.Equal(t, tt.wantBuy, buy)
+			require.Equal(t, tt.wantSell, sell)
+		})
+	}
+}
+
+func TestStraddleQty(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int64
+		skew        float64
+		signal      types.Side
+		positionQty int64
+		limit       int64
+		lotSize     int64
+		wantBuy     int64
+		wantSell    int64
+	}{
+		{
+			name: "not skewed", total: 1000, skew: 0.5, signal: types.SideBuy,
+			limit: 5000, lotSize: 100, wantBuy: 500, wantSell: 500,
+		},
+		{
+			name: "buy skewed", total: 1000, skew: 0.7, signal: types.SideBuy,
+			limit: 5000, lotSize: 100, wantBuy: 700, wantSell: 300,
+		},
+		{
+			name: "sell skewed", total: 1000, skew: 0.75, signal: types.SideSell,
+			limit: 5000, lotSize: 100, wantBuy: 200, wantSell: 800,
+		},
+		{
+			name: "long position limited", total: 1000, skew: 0.7, signal: types.SideBuy, positionQty: 4800, limit: 5000,
+			lotSize: 100, wantBuy: 200, wantSell: 300,
+		},
+		{
+			name: "short position over limit", total: 1000, skew: 0.7, signal: types.SideSell, positionQty: -5100, limit: 5000,
+			lotSize: 100, wantBuy: 300, wantSell: 0,
+		},
+		{name: "one lot", total: 100, skew: 0.7, signal: types.SideBuy, limit: 5000, lotSize: 100, wantBuy: 100, wantSell: 0},
+		{
+			name: "one lot sell", total: 100, skew: 0.7, signal: types.SideSell,
+			limit: 5000, lotSize: 100, wantBuy: 0, wantSell: 100,
+		},
+		{name: "two lots", total: 200, skew: 0.7, signal: types.SideSell, limit: 5000, lotSize: 100, wantBuy: 0, wantSell: 200},
+		{
+			name: "two lots not skewed", total: 200, skew: 0.5, signal: types.SideBuy,
+			limit: 5000, lotSize: 100, wantBuy: 100, wantSell: 100,
+		},
+		{
+			name: "not lot multiple", total: 150, skew: 0.7, signal: types.SideBuy,
+			limit: 5000, lotSize: 100, wantBuy: 100, wantSell: 0,
+		},
+		{name: "lot size 1", total: 15, skew: 0.6, signal: types.SideSell, limit: 100, lotSize: 1, wantBuy: 6, wantSell: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buy, sell := straddleQty(tt.total, tt.skew, tt.signal, tt.positionQty, tt.limit, tt.lotSize)
+			require.Equal(t, tt.wantBuy, buy)
+			require.Equal(t, tt.wantSell, sell)
+		})
+	}
+}
+
+func TestParseStraddleClOrdID(t *testing.T) {
+	tests := []struct {
+		clOrdID    string
+		wantPairID string
+		wantSide   types.Side
+		wantOk     bool
+	}{
+		{clOrdID: straddleClOrdID("kx1a2b", types.SideBuy), wantPairID: "kx1a2b", wantSide: types.SideBuy, wantOk: true},
+		{clOrdID: straddleClOrdID("kx1a2b", types.SideSell), wantPairID: "kx1a2b", wantSide: types.SideSell, wantOk: true},
+		{clOrdID: "straddle-kx1a2b-Empty", wantSide: types.SideEmpty},
+		{clOrdID: "straddle--Buy", wantSide: types.SideEmpty},
+		{clOrdID: "rsi-kx1a2b-Buy", wantSide: types.SideEmpty},
+		{clOrdID: "", wantSide: types.SideEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clOrdID, func(t *testing.T) {
+			pairID, side, ok := parseStraddleClOrdID(tt.clOrdID)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.wantPairID, pairID)
+			require.Equal(t, tt.wantSide, side)
+		})
+	}
+}
+
+func TestRestorePair(t *testing.T) {
+	tests := []struct {
+		name     string
+		orders   []bitmex.OrderCopied
+		wantID   string
+		wantBuy  string
+		wantSell string
+	}{
+		{name: "no orders"},
+		{name: "not straddle orders", orders: []bitmex.OrderCopied{{OrderID: "1", ClOrdID: "manual"}}},
+		{
+			name: "both orders",
+			orders: []bitmex.OrderCopied{
+				{OrderID: "1", ClOrdID: "straddle-a-Buy"},
+				{OrderID: "2", ClOrdID: "straddle-a-Sell"},
+			},
+			wantID: "a", wantBuy: "1", wantSell: "2",
+		},
+		{
+			name:   "one order left",
+			orders: []bitmex.OrderCopied{{OrderID: "1", ClOrdID: "manual"}, {OrderID: "2", ClOrdID: "straddle-a-Sell"}},
+			wantID: "a", wantSell: "2",
+		},
+		{
+			name: "last pair",
+			orders: []bitmex.OrderCopied{
+				{OrderID: "1", ClOrdID: "straddle-a-Buy"},
+				{OrderID: "2", ClOrdID: "straddle-b-Buy"},
+				{OrderID: "3", ClOrdID: "straddle-a-Sell"},
+				{OrderID: "4", ClOrdID: "straddle-b-Sell"},
+			},
+			wantID: "b", wantBuy: "2", wantSell: "4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair := restorePair(tt.orders)
+			require.Equal(t, tt.wantID, pair.id)
+			require.Equal(t, tt.wantBuy, pair.buy.OrderID)
+			require.Equal(t, tt.wantSell, pair.sell.OrderID)
+		})
+	}
+}
+
+// straddleOrderProcStub order processor of the straddle tests, it records placed, amended and canceled orders
+type straddleOrderProcStub struct {
+	active    []bitmex.OrderCopied
+	placeErr  map[types.Side]error
+	cancelErr error
+	placed    []string
+	amended   []bitmex.OrderAmendParams
+	canceled  []string
+}
+
+func (p *straddleOrderProcStub) GetActiveOrders(string) ([]bitmex.OrderCopied, error) {
+	return p.active, nil
+}
+
+func (p *straddleOrderProcStub) GetInstrument() (bitmex.Instrument, error) {
+	return bitmex.Instrument{}, nil
+}
+
+func (p *straddleOrderProcStub) GetContract() (trademath.Contract, error) {
+	return trademath.XBTUSD, nil
+}
+
+func (p *straddleOrderProcStub) GetPosition() (*bitmex.Position, bool) {
+	return nil, false
+}
+
+func (p *straddleOrderProcStub) OrderQty(types.Side, float64, bool) (int64, error) {
+	return 0, nil
+}
+
+func (p *straddleOrderProcStub) PlaceLimitOrder(
+	side types.Side, qty int64, price money.Decimal, clOrdID string,
+) (bitmex.OrderCopied, error) {
+	if err := p.placeErr[side]; err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	p.placed = append(p.placed, clOrdID)
+	return bitmex.OrderCopied{
+		OrderID: "id-" + string(side), ClOrdID: clOrdID, Side: string(side),
+		OrderQty: qty, LeavesQty: qty, Price: price.Float64(),
+	}, nil
+}
+
+func (p *straddleOrderProcStub) AmendOrder(params *bitmex.OrderAmendParams) (bitmex.OrderCopied, error) {
+	p.amended = append(p.amended, *params)
+	return bitmex.OrderCopied{OrderID: params.OrderID, Price: params.Price, LeavesQty: int64(params.LeavesQuantity)}, nil
+}
+
+func (p *straddleOrderProcStub) CancelOrder(orderID, _ string) error {
+	if p.cancelErr != nil {
+		return p.cancelErr
+	}
+	p.canceled = append(p.canceled, orderID)
+	return nil
+}
+
+func TestStraddleStrategy_requote(t *testing.T) {
+	// orders of the pair are quoted at 8998 and 9002.5 by the instrument with 4 ticks spread
+	var (
+		inst = bitmex.Instrument{BidPrice: 9000, MidPrice: 9000.25, AskPrice: 9000.5}
+		cfg  = &config.StrategiesConfig{StraddleSpreadTicks: 4}
+	)
+	tests := []struct {
+		name         string
+		pair         straddlePair
+		positionQty  int64
+		wantAmended  []bitmex.OrderAmendParams
+		wantCanceled []string
+		wantBuy      string
+		wantSell     string
+	}{
+		{
+			name: "price moved",
+			pair: straddlePair{
+				buy:  bitmex.OrderCopied{OrderID: "1", Price: 8990, LeavesQty: 300},
+				sell: bitmex.OrderCopied{OrderID: "2", Price: 9002.5, LeavesQty: 200},
+			},
+			wantAmended: []bitmex.OrderAmendParams{{OrderID: "1", Price: 8998, Text: "amend order - straddle requote"}},
+			wantBuy:     "1", wantSell: "2",
+		},
+		{
+			name: "price moved less than requote step",
+			pair: straddlePair{
+				buy:  bitmex.OrderCopied{OrderID: "1", Price: 8998, LeavesQty: 300},
+				sell: bitmex.OrderCopied{OrderID: "2", Price: 9002.5, LeavesQty: 200},
+			},
+			wantBuy: "1", wantSell: "2",
+		},
+		{
+			name: "leaves qty limited",
+			pair: straddlePair{
+				buy:  bitmex.OrderCopied{OrderID: "1", Price: 8998, LeavesQty: 300},
+				sell: bitmex.OrderCopied{OrderID: "2", Price: 9002.5, LeavesQty: 200},
+			},
+			positionQty: 4800,
+			wantAmended: []bitmex.OrderAmendParams{
+				{OrderID: "1", LeavesQuantity: 200, Text: "amend order - straddle requote"},
+			},
+			wantBuy: "1", wantSell: "2",
+		},
+		{
+			name: "limit reached cancels the leg",
+			pair: straddlePair{
+				buy:  bitmex.OrderCopied{OrderID: "1", Price: 8990, LeavesQty: 300},
+				sell: bitmex.OrderCopied{OrderID: "2", Price: 9002.5, LeavesQty: 200},
+			},
+			positionQty:  -5000,
+			wantCanceled: []string{"2"},
+			wantAmended:  []bitmex.OrderAmendParams{{OrderID: "1", Price: 8998, Text: "amend order - straddle requote"}},
+			wantBuy:      "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := &straddleOrderProcStub{}
+			s := &StraddleStrategy{orderProc: proc, log: logrus.New(), pair: tt.pair}
+			err := s.requote(cfg, trademath.XBTUSD, inst, tt.positionQty, 5000)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAmended, proc.amended)
+			require.Equal(t, tt.wantCanceled, proc.canceled)
+			require.Equal(t, tt.wantBuy, s.pair.buy.OrderID)
+			require.Equal(t, tt.wantSell, s.pair.sell.OrderID)
+		})
+	}
+}
+
+func TestStraddleStrategy_syncPair(t *testing.T) {
+	tests := []struct {
+		name     string
+		pair     straddlePair
+		active   []bitmex.OrderCopied
+		wantID   string
+		wantBuy  bitmex.OrderCopied
+		wantSell bitmex.OrderCopied
+	}{
+		{
+			name: "closed leg dropped",
+			pair: straddlePair{
+				id:   "a",
+				buy:  bitmex.OrderCopied{OrderID: "1", ClOrdID: "straddle-a-Buy", LeavesQty: 300},
+				sell: bitmex.OrderCopied{OrderID: "2", ClOrdID: "straddle-a-Sell", LeavesQty: 200},
+			},
+			active:   []bitmex.OrderCopied{{OrderID: "2", ClOrdID: "straddle-a-Sell", LeavesQty: 100}},
+			wantID:   "a",
+			wantSell: bitmex.OrderCopied{OrderID: "2", ClOrdID: "straddle-a-Sell", LeavesQty: 100},
+		},
+		{
+			name: "both legs closed",
+			pair: straddlePair{
+				id:   "a",
+				buy:  bitmex.OrderCopied{OrderID: "1", ClOrdID: "straddle-a-Buy"},
+				sell: bitmex.OrderCopied{OrderID: "2", ClOrdID: "straddle-a-Sell"},
+			},
+			active: []bitmex.OrderCopied{{OrderID: "3", ClOrdID: "manual"}},
+			wantID: "a",
+		},
+		{
+			name:    "empty pair restored",
+			active:  []bitmex.OrderCopied{{OrderID: "1", ClOrdID: "straddle-b-Buy"}},
+			wantID:  "b",
+			wantBuy: bitmex.OrderCopied{OrderID: "1", ClOrdID: "straddle-b-Buy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StraddleStrategy{orderProc: &straddleOrderProcStub{active: tt.active}, log: logrus.New(), pair: tt.pair}
+			require.NoError(t, s.syncPair("XBTUSD"))
+			require.Equal(t, tt.wantID, s.pair.id)
+			require.Equal(t, tt.wantBuy, s.pair.buy)
+			require.Equal(t, tt.wantSell, s.pair.sell)
+		})
+	}
+}
+
+func TestStraddleStrategy_placePair(t *testing.T) {
+	var (
+		inst = bitmex.Instrument{BidPrice: 9000, MidPrice: 9000.25, AskPrice: 9000.5}
+		cfg  = &config.StrategiesConfig{StraddleSpreadTicks: 4}
+	)
+	tests := []struct {
+		name         string
+		signal       types.Side
+		placeErr     map[types.Side]error
+		cancelErr    error
+		wantErr      string
+		wantCanceled []string
+		wantBuy      string
+		wantSell     string
+	}{
+		{name: "placed", signal: types.SideSell, wantBuy: "id-Buy", wantSell: "id-Sell"},
+		{
+			name: "second leg failed, first leg canceled", signal: types.SideSell,
+			placeErr:     map[types.Side]error{types.SideBuy: errors.New("rejected")},
+			wantErr:      "rejected",
+			wantCanceled: []string{"id-Sell"},
+		},
+		{
+			name: "first leg failed", signal: types.SideBuy,
+			placeErr: map[types.Side]error{types.SideBuy: errors.New("rejected")},
+			wantErr:  "rejected",
+		},
+		{
+			name: "cancel of the first leg failed", signal: types.SideBuy,
+			placeErr:  map[types.Side]error{types.SideSell: errors.New("rejected")},
+			cancelErr: errors.New("timeout"),
+			wantErr:   "timeout",
+			wantBuy:   "id-Buy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := &straddleOrderProcStub{placeErr: tt.placeErr, cancelErr: tt.cancelErr}
+			s := &StraddleStrategy{orderProc: proc, log: logrus.New()}
+			err := s.placePair(cfg, trademath.XBTUSD, inst, tt.signal, 1000, 0, 5000)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantCanceled, proc.canceled)
+			require.Equal(t, tt.wantBuy, s.pair.buy.OrderID)
+			require.Equal(t, tt.wantSell, s.pair.sell.OrderID)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -11,7 +12,8 @@ import (
 
 	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
 
-	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/strategies/filter"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/internal/utils"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
@@ -38,20 +40,17 @@ func fetchCloses(candles []bitmex.TradeBuck) []float64 {
 	return result
 }
 
-func (s *BBRSIStrategy) getCandles(scfg *config.GlobalConfig, binSize models.BinSize) ([]bitmex.TradeBuck, error) {
-	cfg := scfg.GlobStrategies.GetCfgByBinSize(binSize.String())
+func (s *BBRSIStrategy) getCandles(cfg *config.StrategiesConfig, key candlecache.Key) ([]bitmex.TradeBuck, error) {
 	var count int
 	if cfg.RsiCount > cfg.GetCandlesCount {
 		count = cfg.RsiCount * 2
 	} else {
 		count = cfg.GetCandlesCount * 2
 	}
-	startTime, err := utils.FromTime(time.Now().UTC(), binSize.String(), count)
-	if err != nil {
-		return nil, err
+	if cfg.BBCandlesCount() > count {
+		count = cfg.BBCandlesCount()
 	}
-	candles := s.caches.GetCache(binSize).GetBucketed(startTime, time.Time{}, count)
-	return candles, nil
+	return getSeries(s.caches, key, cfg, count)
 }
 
 func fetchTSFromCandles(candles []bitmex.TradeBuck) ([]time.Time, error) {
@@ -66,10 +65,13 @@ func fetchTSFromCandles(candles []bitmex.TradeBuck) ([]time.Time, error) {
 	return result, nil
 }
 
+// fetchLastCandlesForBB returns the last bricks of the last bb_last_candles_count source candles,
+// bollinger band signals are saved by source candles timestamps
 func (s *BBRSIStrategy) fetchLastCandlesForBB(
-	scfg *config.GlobalConfig, binSize string, candles []bitmex.TradeBuck,
+	cfg *config.StrategiesConfig, candles []bitmex.TradeBuck,
 ) []bitmex.TradeBuck {
-	lastIndx := len(candles) - scfg.GlobStrategies.GetCfgByBinSize(binSize).BBLastCandlesCount
+	candles = lastBricks(candles)
+	lastIndx := len(candles) - cfg.BBLastCandlesCount
 	if lastIndx < 0 {
 		return nil
 	}
@@ -77,21 +79,123 @@ func (s *BBRSIStrategy) fetchLastCandlesForBB(
 	return result
 }
 
-func getCandles( // nolint:unused,deadcode
-	caches candlecache.Caches, binSize models.BinSize, count int,
+func getCandles(
+	caches candlecache.Caches, key candlecache.Key, count int,
 ) ([]bitmex.TradeBuck, error) {
-	startTime, err := utils.FromTime(time.Now().UTC(), binSize.String(), count)
+	startTime, err := utils.FromTime(time.Now().UTC(), key.BinSize.String(), count)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := caches.GetCache(key)
 	if err != nil {
 		return nil, err
 	}
-	candles := caches.GetCache(binSize).GetBucketed(startTime, time.Time{}, count)
+	candles := cache.GetBucketed(startTime, time.Time{}, count)
 	return candles, nil
 }
 
+// getSeries returns candles series of the strategy config with at least count candles if the cache has enough
+// candles. Renko bricks and range bars are fewer than candles, so candles are fetched until the series is long enough
+func getSeries(
+	caches candlecache.Caches, key candlecache.Key, cfg *config.StrategiesConfig, count int,
+) ([]bitmex.TradeBuck, error) {
+	for fetch := count; ; fetch *= 2 {
+		candles, err := getCandles(caches, key, fetch)
+		if err != nil {
+			return nil, err
+		}
+		series, err := candlecache.Series(
+			candles, candlecache.SeriesType(cfg.Series), cfg.SeriesBrickSize, cfg.SeriesATRCount,
+		)
+		if err != nil || len(series) >= count || len(candles) < fetch {
+			return series, err
+		}
+	}
+}
+
+// lastBricks returns the last brick of every source candle, bricks and bars have timestamp of the candle
+// which completed them, so signals are keyed by source candles
+func lastBricks(candles []bitmex.TradeBuck) []bitmex.TradeBuck {
+	var result = make([]bitmex.TradeBuck, 0, len(candles))
+	for i, candle := range candles {
+		if i+1 < len(candles) && candles[i+1].Timestamp == candle.Timestamp {
+			continue
+		}
+		result = append(result, candle)
+	}
+	return result
+}
+
+// initFilters installs candles, trend, volume or levels filter enabled by the config if filters are not installed
+func initFilters(
+	filters []filter.Filter, scfg *config.GlobalConfig, cfg *config.StrategiesConfig, log *logrus.Logger,
+) []filter.Filter {
+	if cfg.CandlesFilterEnable && len(filters) == 0 {
+		filters = append(filters, filter.NewCandlesFilter(scfg, log))
+	}
+	if cfg.TrendFilterEnable && len(filters) == 0 {
+		filters = append(filters, filter.NewTrendFilter(scfg, log))
+	}
+	if cfg.VolumeFilterEnable && len(filters) == 0 {
+		filters = append(filters, filter.NewVolumeFilter(scfg, log))
+	}
+	if cfg.LevelsFilterEnable && len(filters) == 0 {
+		filters = append(filters, filter.NewLevelsFilter(scfg, log))
+	}
+	return filters
+}
+
+// applyFilters returns side of the order confirmed by all filters, filters use strategies config
+// of the strategy instance, volume and levels of the key if they are installed. Without filters up trend is sell
+// and down trend is buy
+func applyFilters(
+	filters []filter.Filter, cfg *config.StrategiesConfig, action stratypes.Action, candles []bitmex.TradeBuck,
+	key candlecache.Key, volume VolumeFunc, levels LevelsFunc, log *logrus.Logger,
+) types.Side {
+	if len(filters) == 0 {
+		log.Warnf("filters not installed")
+		switch action {
+		case stratypes.UpTrend:
+			return types.SideSell
+		case stratypes.DownTrend:
+			return types.SideBuy
+		default:
+			return types.SideEmpty
+		}
+	}
+	ctx := context.WithValue(context.Background(), stratypes.ActionKey, action)
+	ctx = context.WithValue(ctx, stratypes.CandlesKey, candles)
+	ctx = context.WithValue(ctx, stratypes.BinSizeKey, key.BinSize)
+	ctx = WithConfig(ctx, cfg)
+	if volume != nil {
+		vol, err := volume(key)
+		if err != nil {
+			log.Warnf("volume of %s failed: %v", key, err)
+		} else {
+			ctx = context.WithValue(ctx, stratypes.VolumeKey, vol)
+		}
+	}
+	if levels != nil {
+		if lvls, ok := levels(key); ok {
+			ctx = context.WithValue(ctx, stratypes.LevelsKey, lvls)
+		}
+	}
+	applySide := types.SideEmpty
+	for _, f := range filters {
+		applySide = f.Apply(ctx)
+		if applySide == types.SideEmpty {
+			return applySide
+		}
+	}
+	return applySide
+}
+
+// placeBitmexOrder places order of the side, quantity of the order is calculated by order coefficient
+// of the side if amount is zero
 func placeBitmexOrder(
-	orderProc *orderproc.OrderProcessor, side types.Side, passive bool, log *logrus.Logger,
+	orderProc *orderproc.OrderProcessor, side types.Side, amount float64, passive bool, log *logrus.Logger,
 ) error {
-	ord, err := orderProc.PlaceOrder(types.Bitmex, side, 0, passive)
+	ord, err := orderProc.PlaceOrder(types.Bitmex, side, amount, passive)
 	if err != nil {
 		log.Warnf("orderProc.PlaceOrder failed: %v", err)
 		return err
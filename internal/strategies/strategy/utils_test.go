@@ -0,0 +1,73 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/config"
+	"github.com/tagirmukail/tccbot-backend/internal/db/models"
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestGetSeries(t *testing.T) {
+	var (
+		key     = candlecache.NewKey(types.Bitmex, types.XBTUSD, models.Bin1m)
+		last    = time.Now().UTC().Truncate(time.Minute)
+		candles = make([]bitmex.TradeBuck, 0, 500)
+	)
+	for i := 500; i > 0; i-- {
+		closePrice := 9000 + float64(500-i)
+		candles = append(candles, bitmex.TradeBuck{
+			Symbol:    string(types.XBTUSD),
+			Timestamp: last.Add(-time.Duration(i-1) * time.Minute).Format(tradeapi.TradeBucketedTimestampLayout),
+			Open:      closePrice,
+			High:      closePrice,
+			Low:       closePrice,
+			Close:     closePrice,
+		})
+	}
+	caches := candlecache.NewRegistry(500, logrus.New())
+	require.NoError(t, caches.Register(key, 500))
+	cache, err := caches.GetCache(key)
+	require.NoError(t, err)
+	cache.StoreBatch(candles)
+
+	tests := []struct {
+		name    string
+		cfg     config.StrategiesConfig
+		count   int
+		wantLen int
+	}{
+		{name: "candles", count: 20, wantLen: 20},
+		{name: "renko bricks of more candles", cfg: config.StrategiesConfig{Series: "renko", SeriesBrickSize: 10}, count: 20},
+		{name: "not enough candles", cfg: config.StrategiesConfig{Series: "renko", SeriesBrickSize: 10}, count: 60, wantLen: 49},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := getSeries(caches, key, &tt.cfg, tt.count)
+			require.NoError(t, err)
+			if tt.wantLen != 0 {
+				require.Len(t, series, tt.wantLen)
+				return
+			}
+			require.GreaterOrEqual(t, len(series), tt.count)
+		})
+	}
+}
+
+func TestLastBricks(t *testing.T) {
+	bricks := []bitmex.TradeBuck{
+		{Timestamp: "2020-05-01T10:01:00.000Z", Close: 1},
+		{Timestamp: "2020-05-01T10:03:00.000Z", Close: 2},
+		{Timestamp: "2020-05-01T10:03:00.000Z", Close: 3},
+		{Timestamp: "2020-05-01T10:04:00.000Z", Close: 4},
+		{Timestamp: "2020-05-01T10:04:00.000Z", Close: 5},
+	}
+	require.Equal(t, []bitmex.TradeBuck{bricks[0], bricks[2], bricks[4]}, lastBricks(bricks))
+}
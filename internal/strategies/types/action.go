@@ -26,4 +26,7 @@ const (
 	ActionKey
 	CandlesKey
 	BinSizeKey
+	StrategiesConfigKey
+	VolumeKey
+	LevelsKey
 )
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+)
+
+// Volume volume indicators of the last closed candles of the bin size
+type Volume struct {
+	VWAP           float64
+	SessionVWAP    float64 // vwap of the day session of the last candle
+	OBV            float64
+	RelativeVolume float64 // volume of the last candle relative to the average volume of the candles before it
+	Profile        trademath.VolumeProfile
+	// Deltas volume deltas of the bins closed after the first received trade, empty without trades stream
+	Deltas []candlecache.VolumeDelta
+}
@@ -0,0 +1,64 @@
+package strategies
+
+import (
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/internal/candlecache"
+	stratypes "github.com/tagirmukail/tccbot-backend/internal/strategies/types"
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+)
+
+// parameters of volume indicators
+const (
+	volumeCandlesCount     = 200 // count of the last candles for vwap, obv and volume profile
+	volumeRelativePeriod   = 20
+	volumeProfileRowsCount = 50 // count of price rows over the price range of the candles
+	volumeValueArea        = 0.7
+)
+
+// Volume returns volume indicators of the last candles of the key cache and volume deltas of the same bins,
+// session vwap is calculated by cached candles of the session
+func (s *Strategies) Volume(key candlecache.Key) (stratypes.Volume, error) {
+	cache, err := s.candlesCaches.GetCache(key)
+	if err != nil {
+		return stratypes.Volume{}, err
+	}
+	candles := cache.GetBucketed(time.Time{}, time.Time{}, volumeCandlesCount)
+	if len(candles) == 0 {
+		return stratypes.Volume{}, nil
+	}
+	sessionVWAP, err := s.tradeCalc.CalcSessionVWAP(candles)
+	if err != nil {
+		return stratypes.Volume{}, err
+	}
+	o := trademath.NewOHLCV(candles)
+	volume := stratypes.Volume{
+		VWAP:           s.tradeCalc.CalcVWAP(candles),
+		SessionVWAP:    sessionVWAP,
+		OBV:            s.tradeCalc.CalcOBV(o),
+		RelativeVolume: s.tradeCalc.CalcRelativeVolume(o, volumeRelativePeriod),
+		Deltas:         cache.GetVolumeDeltas(len(candles)),
+	}
+	if rowSize := volumeProfileRowSize(o); rowSize > 0 {
+		volume.Profile, err = s.tradeCalc.CalcVolumeProfile(o, rowSize, volumeValueArea)
+		if err != nil {
+			return stratypes.Volume{}, err
+		}
+	}
+	return volume, nil
+}
+
+// volumeProfileRowSize returns size of the price row, price range of the candles is split
+// into volumeProfileRowsCount rows
+func volumeProfileRowSize(o trademath.OHLCV) float64 {
+	high, low := o.High[0], o.Low[0]
+	for i := range o.High {
+		if o.High[i] > high {
+			high = o.High[i]
+		}
+		if o.Low[i] < low {
+			low = o.Low[i]
+		}
+	}
+	return (high - low) / volumeProfileRowsCount
+}
@@ -1,10 +1,9 @@
 package bitmextradedata
 
 import (
-	"os"
-	"os/signal"
+	"context"
 	"sync"
-	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -12,6 +11,8 @@ import (
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
 )
 
+const statsPeriod = time.Minute
+
 type Sender struct {
 	messages    chan *data.BitmexData
 	subscribers []*Subscriber
@@ -26,26 +27,86 @@ func New(messages chan *data.BitmexData, log *logrus.Logger, subscribers ...*Sub
 	}
 }
 
-func (s *Sender) SendToSubscribers(wg *sync.WaitGroup) {
+// SendToSubscribers fans out incoming messages to the subscribers queues until ctx is done,
+// a slow subscriber does not stall others unless its overflow policy is Block
+func (s *Sender) SendToSubscribers(ctx context.Context) {
 	s.log.Infof("bitmex trade data sender started")
+	defer s.log.Infof("bitmex trade data sender finished")
+
+	stop := make(chan struct{})
+	pumps := &sync.WaitGroup{}
+	for _, subs := range s.subscribers {
+		pumps.Add(1)
+		go subs.pump(pumps, stop)
+	}
 	defer func() {
-		s.log.Infof("bitmex trade data sender finished")
-		wg.Done()
+		close(stop)
+		pumps.Wait()
+		s.drain()
 	}()
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
+
+	statsTick := time.NewTicker(statsPeriod)
+	defer statsTick.Stop()
+	var prev = make(map[*Subscriber]Stats, len(s.subscribers))
 
 	for {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			return
-		case msg := <-s.messages:
-			for _, subs := range s.subscribers {
-				if subs.isSubscriberTheme(types.Theme(msg.Table)) {
-					subs.messages <- msg
-				}
+		case <-statsTick.C:
+			s.logStats(prev)
+		case msg, ok := <-s.messages:
+			if !ok {
+				return
+			}
+			s.send(stop, msg)
+		}
+	}
+}
+
+// send decodes message once for all subscribers of its table, messages without subscribers are not decoded
+func (s *Sender) send(stop <-chan struct{}, msg *data.BitmexData) {
+	var event data.Event
+	for _, subs := range s.subscribers {
+		if !subs.isSubscriberTheme(types.Theme(msg.Table)) {
+			continue
+		}
+		if event == nil {
+			var err error
+			event, err = msg.Decode()
+			if err != nil {
+				s.log.Warnf("bitmex trade data decode message failed: %v", err)
+				return
 			}
 		}
+		subs.push(stop, event)
 	}
+}
 
+// drain delivers queued messages to the subscribers which are still reading, the rest is discarded
+func (s *Sender) drain() {
+	for _, subs := range s.subscribers {
+		delivered, discarded := subs.drain()
+		if delivered != 0 || discarded != 0 {
+			s.log.Infof("subscriber %s drained: delivered %d, discarded %d queued messages",
+				subs.Name(), delivered, discarded)
+		}
+	}
+}
+
+func (s *Sender) logStats(prev map[*Subscriber]Stats) {
+	for _, subs := range s.subscribers {
+		stats := subs.Stats()
+		s.log.Debugf("subscriber %s stats: %#v", subs.Name(), stats)
+		if stats.Dropped > prev[subs].Dropped {
+			s.log.Warnf("subscriber %s is slow, dropped %d messages for last %v, queued: %d",
+				subs.Name(), stats.Dropped-prev[subs].Dropped, statsPeriod, stats.Queued)
+		}
+		if stats.Spilled > prev[subs].Spilled {
+			s.log.Warnf("subscriber %s is slow, spilled %d messages over the queue size for last %v, "+
+				"queued: %d, max queued: %d",
+				subs.Name(), stats.Spilled-prev[subs].Spilled, statsPeriod, stats.Queued, stats.MaxQueued)
+		}
+		prev[subs] = stats
+	}
 }
@@ -1,26 +1,117 @@
 package bitmextradedata
 
 import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
 )
 
+const defaultBufferSize = 100
+
+// OverflowPolicy defines what subscriber does with a new message when its queue is full
+type OverflowPolicy string
+
+const (
+	// DropOldest removes the oldest queued message and enqueues the new one
+	DropOldest OverflowPolicy = "drop_oldest"
+	// Coalesce replaces a queued candle with the same key by the new one and merges update rows
+	// of position, instrument and margin into the last queued update of the table,
+	// if there is no such message the oldest one is dropped
+	Coalesce OverflowPolicy = "coalesce"
+	// Block waits until the subscriber reads a message from the queue
+	Block OverflowPolicy = "block"
+	// Spill enqueues the message over the queue size, nothing is dropped and the sender is not blocked,
+	// messages over the queue size are counted as spilled
+	Spill OverflowPolicy = "spill"
+)
+
+// ToOverflowPolicy converts string to overflow policy, empty or unknown value is Coalesce
+func ToOverflowPolicy(policy string) OverflowPolicy {
+	switch OverflowPolicy(strings.ToLower(policy)) {
+	case DropOldest:
+		return DropOldest
+	case Block:
+		return Block
+	case Spill:
+		return Spill
+	default:
+		return Coalesce
+	}
+}
+
+// Stats subscriber queue metrics
+type Stats struct {
+	Queued    int    // messages waiting in the queue now
+	Received  uint64 // messages accepted from sender
+	Delivered uint64 // messages read by the subscriber
+	Dropped   uint64 // messages removed from the full queue
+	Coalesced uint64 // messages replaced by a newer message with the same key or merged into queued one
+	Spilled   uint64 // messages queued over the queue size
+	MaxQueued int    // max count of queued messages of the spilled queue, 0 if the queue was not spilled
+}
+
 type Subscriber struct {
+	name     string
 	themes   []types.Theme
-	messages chan *data.BitmexData
+	messages chan data.Event
+	size     int
+	policy   OverflowPolicy
+
+	mx    sync.Mutex
+	queue []data.Event
+	ready chan struct{} // signals pump about new queued message
+	space chan struct{} // signals blocked sender about free place in the queue
+
+	received  uint64
+	delivered uint64
+	dropped   uint64
+	coalesced uint64
+	spilled   uint64
+	maxQueued int
 }
 
-func NewSubscriber(themes []types.Theme) *Subscriber {
+func NewSubscriber(name string, themes []types.Theme, size int, policy OverflowPolicy) *Subscriber {
+	if size <= 0 {
+		size = defaultBufferSize
+	}
 	return &Subscriber{
+		name:     name,
 		themes:   themes,
-		messages: make(chan *data.BitmexData),
+		messages: make(chan data.Event),
+		size:     size,
+		policy:   policy,
+		queue:    make([]data.Event, 0, size),
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
 	}
 }
 
-func (s *Subscriber) GetMsgChan() chan *data.BitmexData {
+func (s *Subscriber) GetMsgChan() chan data.Event {
 	return s.messages
 }
 
+func (s *Subscriber) Name() string {
+	return s.name
+}
+
+func (s *Subscriber) Stats() Stats {
+	s.mx.Lock()
+	queued, maxQueued := len(s.queue), s.maxQueued
+	s.mx.Unlock()
+	return Stats{
+		Queued:    queued,
+		Received:  atomic.LoadUint64(&s.received),
+		Delivered: atomic.LoadUint64(&s.delivered),
+		Dropped:   atomic.LoadUint64(&s.dropped),
+		Coalesced: atomic.LoadUint64(&s.coalesced),
+		Spilled:   atomic.LoadUint64(&s.spilled),
+		MaxQueued: maxQueued,
+	}
+}
+
 func (s *Subscriber) isSubscriberTheme(theme types.Theme) bool {
 	for _, th := range s.themes {
 		if th == theme {
@@ -30,3 +121,188 @@ func (s *Subscriber) isSubscriberTheme(theme types.Theme) bool {
 
 	return false
 }
+
+// push puts message to the subscriber queue according to the overflow policy,
+// blocks only for Block policy until there is place in the queue or done is closed.
+// Spill policy grows the queue over its size
+func (s *Subscriber) push(done <-chan struct{}, msg data.Event) {
+	for {
+		s.mx.Lock()
+		if s.policy == Coalesce && s.coalesce(msg) {
+			s.mx.Unlock()
+			atomic.AddUint64(&s.received, 1)
+			atomic.AddUint64(&s.coalesced, 1)
+			return
+		}
+		if len(s.queue) < s.size || s.policy == Spill {
+			s.queue = append(s.queue, msg)
+			if len(s.queue) > s.size {
+				atomic.AddUint64(&s.spilled, 1)
+				if len(s.queue) > s.maxQueued {
+					s.maxQueued = len(s.queue)
+				}
+			}
+			s.mx.Unlock()
+			atomic.AddUint64(&s.received, 1)
+			notify(s.ready)
+			return
+		}
+		if s.policy != Block {
+			s.queue = append(s.queue[1:], msg)
+			s.mx.Unlock()
+			atomic.AddUint64(&s.received, 1)
+			atomic.AddUint64(&s.dropped, 1)
+			notify(s.ready)
+			return
+		}
+		s.mx.Unlock()
+
+		select {
+		case <-done:
+			return
+		case <-s.space:
+		}
+	}
+}
+
+// coalesce replaces queued candle with the same key or merges update rows of delta tables
+// into the last queued update of the table, must be called under lock
+func (s *Subscriber) coalesce(msg data.Event) bool {
+	if msg.Len() == 0 || data.Action(msg.GetAction()) == data.Partial {
+		return false
+	}
+	if key := candlesKey(msg); key != "" {
+		for i := len(s.queue) - 1; i >= 0; i-- {
+			if candlesKey(s.queue[i]) == key && data.Action(s.queue[i].GetAction()) != data.Partial {
+				s.queue[i] = msg
+				return true
+			}
+		}
+		return false
+	}
+	if data.Action(msg.GetAction()) != data.Update {
+		return false
+	}
+	for i := len(s.queue) - 1; i >= 0; i-- {
+		if s.queue[i].GetTable() != msg.GetTable() {
+			continue
+		}
+		// rows are applied in order, so only the last message of the table can be extended
+		if s.queue[i].GetAction() != msg.GetAction() {
+			return false
+		}
+		merged := mergeUpdates(s.queue[i], msg)
+		if merged == nil {
+			return false
+		}
+		s.queue[i] = merged
+		return true
+	}
+	return false
+}
+
+func (s *Subscriber) pop() (data.Event, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if len(s.queue) == 0 {
+		return nil, false
+	}
+	msg := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	return msg, true
+}
+
+// pump delivers queued messages to the subscriber channel until done is closed
+func (s *Subscriber) pump(wg *sync.WaitGroup, done <-chan struct{}) {
+	defer wg.Done()
+	for {
+		msg, ok := s.pop()
+		if !ok {
+			select {
+			case <-done:
+				return
+			case <-s.ready:
+				continue
+			}
+		}
+		notify(s.space)
+
+		select {
+		case <-done:
+			return
+		case s.messages <- msg:
+			atomic.AddUint64(&s.delivered, 1)
+		}
+	}
+}
+
+// drain delivers queued messages to the subscriber channel without blocking and discards the rest
+func (s *Subscriber) drain() (delivered, discarded int) {
+	for {
+		msg, ok := s.pop()
+		if !ok {
+			return delivered, discarded
+		}
+		select {
+		case s.messages <- msg:
+			atomic.AddUint64(&s.delivered, 1)
+			delivered++
+		default:
+			discarded++
+		}
+	}
+}
+
+// candlesKey returns key of trade buckets message, candles carry full state, so the queued message
+// with the same candles can be replaced. Other tables are not keyed
+func candlesKey(msg data.Event) string {
+	e, ok := msg.(*data.TradeBinEvent)
+	if !ok || len(e.Data) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(e.Data))
+	for _, row := range e.Data {
+		keys = append(keys, string(row.Symbol)+":"+row.Timestamp)
+	}
+	return e.Table + ":" + strings.Join(keys, ",")
+}
+
+// mergeUpdates returns new message with rows of queued message followed by rows of msg.
+// Update rows of position, instrument and margin carry only changed fields, so rows are kept
+// and applied one by one by the subscriber. Other tables are not merged, nil is returned.
+// Queued message is shared with other subscribers and is not modified
+func mergeUpdates(queued, msg data.Event) data.Event {
+	switch e := msg.(type) {
+	case *data.PositionEvent:
+		q, ok := queued.(*data.PositionEvent)
+		if !ok {
+			return nil
+		}
+		rows := make([]data.Position, 0, len(q.Data)+len(e.Data))
+		return &data.PositionEvent{Header: e.Header, Data: append(append(rows, q.Data...), e.Data...)}
+	case *data.InstrumentEvent:
+		q, ok := queued.(*data.InstrumentEvent)
+		if !ok {
+			return nil
+		}
+		rows := make([]data.Instrument, 0, len(q.Data)+len(e.Data))
+		return &data.InstrumentEvent{Header: e.Header, Data: append(append(rows, q.Data...), e.Data...)}
+	case *data.MarginEvent:
+		q, ok := queued.(*data.MarginEvent)
+		if !ok {
+			return nil
+		}
+		rows := make([]data.Margin, 0, len(q.Data)+len(e.Data))
+		return &data.MarginEvent{Header: e.Header, Data: append(append(rows, q.Data...), e.Data...)}
+	default:
+		return nil
+	}
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
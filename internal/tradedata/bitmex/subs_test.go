@@ -0,0 +1,223 @@
+package bitmextradedata
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+)
+
+func newMsg(table, timestamp string, price float64) data.Event {
+	return newActionMsg(table, data.Insert, timestamp, price)
+}
+
+func newActionMsg(table string, action data.Action, timestamp string, price float64) data.Event {
+	header := data.Header{Table: table, Action: string(action)}
+	switch table {
+	case string(types.Position):
+		return &data.PositionEvent{
+			Header: header,
+			Data:   []data.Position{{Symbol: types.XBTUSD, Timestamp: timestamp, LastPrice: price}},
+		}
+	case string(types.Trade):
+		return &data.TradeEvent{
+			Header: header,
+			Data:   []data.Trade{{Symbol: types.XBTUSD, Timestamp: timestamp, Price: price}},
+		}
+	default:
+		return &data.TradeBinEvent{
+			Header: header,
+			Data:   []data.TradeBin{{Symbol: types.XBTUSD, Timestamp: timestamp, Close: price}},
+		}
+	}
+}
+
+func price(msg data.Event) float64 {
+	switch e := msg.(type) {
+	case *data.PositionEvent:
+		return e.Data[0].LastPrice
+	case *data.TradeEvent:
+		return e.Data[0].Price
+	case *data.TradeBinEvent:
+		return e.Data[0].Close
+	default:
+		return 0
+	}
+}
+
+// prices returns prices of all rows of queued messages
+func prices(queue []data.Event) []float64 {
+	var result []float64
+	for _, msg := range queue {
+		switch e := msg.(type) {
+		case *data.PositionEvent:
+			for _, row := range e.Data {
+				result = append(result, row.LastPrice)
+			}
+		case *data.TradeBinEvent:
+			for _, row := range e.Data {
+				result = append(result, row.Close)
+			}
+		default:
+			result = append(result, price(msg))
+		}
+	}
+	return result
+}
+
+func TestSubscriber_push(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     OverflowPolicy
+		msgs       []data.Event
+		wantPrices []float64
+		wantStats  Stats
+	}{
+		{
+			name:   "drop oldest",
+			policy: DropOldest,
+			msgs: []data.Event{
+				newMsg("tradeBin1m", "2020-05-16T10:10:00.000Z", 1),
+				newMsg("tradeBin1m", "2020-05-16T10:11:00.000Z", 2),
+				newMsg("tradeBin1m", "2020-05-16T10:12:00.000Z", 3),
+			},
+			wantPrices: []float64{2, 3},
+			wantStats:  Stats{Queued: 2, Received: 3, Dropped: 1},
+		},
+		{
+			name:   "coalesce same candle",
+			policy: Coalesce,
+			msgs: []data.Event{
+				newMsg("tradeBin1m", "2020-05-16T10:10:00.000Z", 1),
+				newMsg("tradeBin1m", "2020-05-16T10:10:00.000Z", 2),
+				newMsg("tradeBin1m", "2020-05-16T10:11:00.000Z", 3),
+			},
+			wantPrices: []float64{2, 3},
+			wantStats:  Stats{Queued: 2, Received: 3, Coalesced: 1},
+		},
+		{
+			name:   "merge position updates, drop oldest when full",
+			policy: Coalesce,
+			msgs: []data.Event{
+				newMsg("tradeBin1m", "2020-05-16T10:10:00.000Z", 1),
+				newActionMsg("position", data.Update, "2020-05-16T10:10:01.000Z", 2),
+				newActionMsg("position", data.Update, "2020-05-16T10:10:02.000Z", 3),
+				newMsg("tradeBin1m", "2020-05-16T10:11:00.000Z", 4),
+			},
+			wantPrices: []float64{2, 3, 4},
+			wantStats:  Stats{Queued: 2, Received: 4, Coalesced: 1, Dropped: 1},
+		},
+		{
+			name:   "position partial is not replaced by update",
+			policy: Coalesce,
+			msgs: []data.Event{
+				newActionMsg("position", data.Partial, "2020-05-16T10:10:01.000Z", 1),
+				newActionMsg("position", data.Update, "2020-05-16T10:10:02.000Z", 2),
+			},
+			wantPrices: []float64{1, 2},
+			wantStats:  Stats{Queued: 2, Received: 2},
+		},
+		{
+			name:   "position inserts are not merged",
+			policy: Coalesce,
+			msgs: []data.Event{
+				newMsg("position", "2020-05-16T10:10:01.000Z", 1),
+				newMsg("position", "2020-05-16T10:10:02.000Z", 2),
+			},
+			wantPrices: []float64{1, 2},
+			wantStats:  Stats{Queued: 2, Received: 2},
+		},
+		{
+			name:   "multi row candles are keyed by all rows",
+			policy: Coalesce,
+			msgs: []data.Event{
+				&data.TradeBinEvent{
+					Header: data.Header{Table: "tradeBin1m", Action: string(data.Insert)},
+					Data: []data.TradeBin{
+						{Symbol: types.XBTUSD, Timestamp: "2020-05-16T10:10:00.000Z", Close: 1},
+						{Symbol: types.XBTUSD, Timestamp: "2020-05-16T10:11:00.000Z", Close: 2},
+					},
+				},
+				newMsg("tradeBin1m", "2020-05-16T10:10:00.000Z", 3),
+			},
+			wantPrices: []float64{1, 2, 3},
+			wantStats:  Stats{Queued: 2, Received: 2},
+		},
+		{
+			name:   "spill over the queue size",
+			policy: Spill,
+			msgs: []data.Event{
+				newMsg("order", "2020-05-16T10:10:00.000Z", 1),
+				newMsg("order", "2020-05-16T10:10:01.000Z", 2),
+				newMsg("order", "2020-05-16T10:10:02.000Z", 3),
+			},
+			wantPrices: []float64{1, 2, 3},
+			wantStats:  Stats{Queued: 3, Received: 3, Spilled: 1, MaxQueued: 3},
+		},
+		{
+			name:   "trades are not coalesced",
+			policy: Coalesce,
+			msgs: []data.Event{
+				newMsg("trade", "2020-05-16T10:10:00.000Z", 1),
+				newMsg("trade", "2020-05-16T10:10:00.000Z", 2),
+			},
+			wantPrices: []float64{1, 2},
+			wantStats:  Stats{Queued: 2, Received: 2},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSubscriber("test", nil, 2, tt.policy)
+			for _, msg := range tt.msgs {
+				s.push(nil, msg)
+			}
+			require.Equal(t, tt.wantPrices, prices(s.queue))
+			require.Equal(t, tt.wantStats, s.Stats())
+		})
+	}
+}
+
+func TestSubscriber_pushBlock(t *testing.T) {
+	s := NewSubscriber("test", nil, 1, Block)
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go s.pump(wg, done)
+
+	s.push(done, newMsg("trade", "2020-05-16T10:10:00.000Z", 1))
+	s.push(done, newMsg("trade", "2020-05-16T10:10:00.000Z", 2))
+
+	pushed := make(chan struct{})
+	go func() {
+		s.push(done, newMsg("trade", "2020-05-16T10:10:00.000Z", 3))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push must block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for _, want := range []float64{1, 2, 3} {
+		msg := <-s.GetMsgChan()
+		require.Equal(t, want, price(msg))
+	}
+	<-pushed
+
+	close(done)
+	wg.Wait()
+	require.Equal(t, Stats{Received: 3, Delivered: 3}, s.Stats())
+}
+
+func TestToOverflowPolicy(t *testing.T) {
+	require.Equal(t, DropOldest, ToOverflowPolicy("drop_oldest"))
+	require.Equal(t, Block, ToOverflowPolicy("BLOCK"))
+	require.Equal(t, Coalesce, ToOverflowPolicy("coalesce"))
+	require.Equal(t, Coalesce, ToOverflowPolicy(""))
+}
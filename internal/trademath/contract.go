@@ -0,0 +1,155 @@
+package trademath
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// ContractColumns instrument columns required by NewContract
+const ContractColumns = "symbol,isInverse,isQuanto,multiplier,underlyingToSettleMultiplier,tickSize,lotSize,initMargin,makerFee,takerFee"
+
+// XBTUSD inverse perpetual contract, one contract is 1 USD
+var XBTUSD = Contract{
+	Symbol:     "XBTUSD",
+	IsInverse:  true,
+	Multiplier: -money.SatoshisPerBTC,
+	TickSize:   money.New(5, -1),
+	LotSize:    100,
+	InitMargin: money.New(1, -2),
+	Fees:       Fees{Maker: money.New(-25, -5), Taker: money.New(75, -5)},
+}
+
+// Contract bitmex contract specification, values of the contract are in settlement currency units,
+// for XBt settled contracts they are satoshis. Value of inverse contracts is contracts * |multiplier| / price,
+// value of linear and quanto contracts is contracts * |multiplier| * price
+type Contract struct {
+	Symbol    string
+	IsInverse bool
+	IsQuanto  bool
+	// Multiplier settlement units per contract and price unit, bitmex returns negative multiplier for inverse contracts
+	Multiplier int64
+	// UnderlyingToSettleMultiplier settlement units per underlying unit, it is used for linear contracts without multiplier
+	UnderlyingToSettleMultiplier int64
+	TickSize                     money.Decimal
+	LotSize                      int64
+	InitMargin                   money.Decimal // initial margin rate used for cross margin
+	Fees                         Fees
+}
+
+// NewContract returns contract by instrument requested with ContractColumns
+func NewContract(inst bitmex.Instrument) (Contract, error) {
+	c := Contract{
+		Symbol:                       inst.Symbol,
+		IsInverse:                    inst.IsInverse,
+		IsQuanto:                     inst.IsQuanto,
+		Multiplier:                   inst.Multiplier,
+		UnderlyingToSettleMultiplier: inst.UnderlyingToSettleMultiplier,
+		TickSize:                     money.FromFloat(inst.TickSize),
+		LotSize:                      inst.LotSize,
+		InitMargin:                   money.FromFloat(inst.InitMargin),
+		Fees:                         Fees{Maker: money.FromFloat(inst.MakerFee), Taker: money.FromFloat(inst.TakerFee)},
+	}
+	if c.LotSize <= 0 {
+		c.LotSize = 1
+	}
+	if c.multiplier() == 0 {
+		return Contract{}, fmt.Errorf("instrument %s multiplier is empty", inst.Symbol)
+	}
+	if c.TickSize.Sign() <= 0 {
+		return Contract{}, fmt.Errorf("instrument %s tick size is empty", inst.Symbol)
+	}
+	return c, nil
+}
+
+// Notional value of the contracts by the price, it is negative for short position
+func (c Contract) Notional(price money.Decimal, contracts int64) money.Satoshi {
+	if price.Sign() <= 0 {
+		return 0
+	}
+	return money.Satoshi(money.RoundRat(c.value(price, contracts)))
+}
+
+// PNL of the position opened by openPrice, it is calculated exactly and rounded to satoshis once
+func (c Contract) PNL(openPrice, lastPrice money.Decimal, contracts int64) money.Satoshi {
+	if openPrice.Sign() <= 0 || lastPrice.Sign() <= 0 {
+		return 0
+	}
+	open, last := c.value(openPrice, contracts), c.value(lastPrice, contracts)
+	if c.IsInverse {
+		return money.Satoshi(money.RoundRat(open.Sub(open, last)))
+	}
+	return money.Satoshi(money.RoundRat(last.Sub(last, open)))
+}
+
+// Margin initial margin of the contracts, it is notional divided by the leverage rounded up to satoshis.
+// Not positive leverage is cross margin, it uses initial margin rate of the contract
+func (c Contract) Margin(price money.Decimal, contracts int64, leverage money.Decimal) money.Satoshi {
+	if price.Sign() <= 0 {
+		return 0
+	}
+	value := c.value(price, contracts)
+	value.Abs(value)
+	if leverage.Sign() > 0 {
+		value.Quo(value, decimalRat(leverage))
+	} else {
+		value.Mul(value, decimalRat(c.InitMargin))
+	}
+	quo, rem := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return money.Satoshi(quo.Int64())
+}
+
+// Contracts returns count of contracts with notional not greater than amount by the price,
+// the count is truncated to the lot size
+func (c Contract) Contracts(price money.Decimal, amount money.Satoshi) (int64, error) {
+	if price.Sign() <= 0 {
+		return 0, errors.New("price must be positive")
+	}
+	one := c.value(price, 1)
+	if one.Sign() == 0 {
+		return 0, errors.New("contract value is empty")
+	}
+	count := new(big.Rat).Quo(new(big.Rat).SetInt64(int64(amount)), one)
+	contracts := new(big.Int).Quo(count.Num(), count.Denom()).Int64()
+	return contracts / c.LotSize * c.LotSize, nil
+}
+
+// RoundPrice returns the price rounded to the tick size
+func (c Contract) RoundPrice(price money.Decimal) money.Decimal {
+	return price.RoundStep(c.TickSize)
+}
+
+// RoundQty returns the quantity truncated to the lot size
+func (c Contract) RoundQty(qty money.Decimal) money.Decimal {
+	return qty.TruncStep(money.FromInt(c.LotSize))
+}
+
+func (c Contract) multiplier() int64 {
+	m := c.Multiplier
+	if m == 0 && !c.IsInverse && !c.IsQuanto {
+		m = c.UnderlyingToSettleMultiplier
+	}
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// value signed value of the contracts in settlement units
+func (c Contract) value(price money.Decimal, contracts int64) *big.Rat {
+	result := new(big.Rat).SetInt(new(big.Int).Mul(big.NewInt(contracts), big.NewInt(c.multiplier())))
+	if c.IsInverse {
+		return result.Quo(result, decimalRat(price))
+	}
+	return result.Mul(result, decimalRat(price))
+}
+
+func decimalRat(d money.Decimal) *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.Units()), big.NewInt(money.One.Units()))
+}
@@ -0,0 +1,157 @@
+package trademath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+var (
+	ethusd = Contract{Symbol: "ETHUSD", IsQuanto: true, Multiplier: 100, TickSize: money.New(5, -2), LotSize: 1}
+	ethxbt = Contract{
+		Symbol: "ETHH21", Multiplier: money.SatoshisPerBTC, TickSize: money.New(1, -5), LotSize: 1,
+		InitMargin: money.New(2, -2),
+	}
+)
+
+func TestNewContract(t *testing.T) {
+	tests := []struct {
+		name    string
+		inst    bitmex.Instrument
+		want    Contract
+		wantErr bool
+	}{
+		{
+			name: "inverse",
+			inst: bitmex.Instrument{
+				Symbol: "XBTUSD", IsInverse: true, Multiplier: -100000000, TickSize: 0.5, LotSize: 100, InitMargin: 0.01,
+				MakerFee: -0.00025, TakerFee: 0.00075,
+			},
+			want: XBTUSD,
+		},
+		{
+			name: "linear by underlying multiplier and empty lot",
+			inst: bitmex.Instrument{Symbol: "ADAH21", UnderlyingToSettleMultiplier: 100000000, TickSize: 0.00000001},
+			want: Contract{
+				Symbol: "ADAH21", UnderlyingToSettleMultiplier: 100000000, TickSize: money.New(1, -8), LotSize: 1,
+			},
+		},
+		{name: "empty multiplier", inst: bitmex.Instrument{Symbol: "ETHUSD", IsQuanto: true, TickSize: 0.05}, wantErr: true},
+		{name: "empty tick size", inst: bitmex.Instrument{Symbol: "XBTUSD", Multiplier: -100000000}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewContract(tt.inst)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestContract_Notional(t *testing.T) {
+	tests := []struct {
+		name      string
+		contract  Contract
+		price     money.Decimal
+		contracts int64
+		want      money.Satoshi
+	}{
+		{name: "inverse", contract: XBTUSD, price: money.FromInt(8000), contracts: 100, want: 1250000},
+		{name: "inverse short", contract: XBTUSD, price: money.FromInt(8000), contracts: -100, want: -1250000},
+		{name: "quanto", contract: ethusd, price: money.FromInt(200), contracts: 10, want: 200000},
+		{name: "linear", contract: ethxbt, price: money.New(3125, -5), contracts: 2, want: 6250000},
+		{name: "empty price", contract: XBTUSD, contracts: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.contract.Notional(tt.price, tt.contracts))
+		})
+	}
+}
+
+func TestContract_PNL(t *testing.T) {
+	tests := []struct {
+		name      string
+		contract  Contract
+		open      money.Decimal
+		last      money.Decimal
+		contracts int64
+		want      money.Satoshi
+	}{
+		{name: "inverse long profit", contract: XBTUSD, open: money.FromInt(8000), last: money.FromInt(9000), contracts: 100, want: 138889},
+		{name: "inverse short profit", contract: XBTUSD, open: money.FromInt(9000), last: money.FromInt(8000), contracts: -100, want: 138889},
+		{name: "quanto long profit", contract: ethusd, open: money.FromInt(200), last: money.FromInt(210), contracts: 10, want: 10000},
+		{name: "quanto short loss", contract: ethusd, open: money.FromInt(200), last: money.FromInt(210), contracts: -10, want: -10000},
+		{name: "linear long loss", contract: ethxbt, open: money.New(3125, -5), last: money.New(3, -2), contracts: 2, want: -250000},
+		{name: "empty price", contract: ethusd, last: money.FromInt(210), contracts: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.contract.PNL(tt.open, tt.last, tt.contracts))
+		})
+	}
+}
+
+func TestContract_Margin(t *testing.T) {
+	tests := []struct {
+		name      string
+		contract  Contract
+		price     money.Decimal
+		contracts int64
+		leverage  money.Decimal
+		want      money.Satoshi
+	}{
+		{name: "isolated", contract: XBTUSD, price: money.FromInt(8000), contracts: 100, leverage: money.FromInt(10), want: 125000},
+		{name: "short rounded up", contract: XBTUSD, price: money.FromInt(9000), contracts: -100, leverage: money.FromInt(3), want: 370371},
+		{name: "cross", contract: ethxbt, price: money.New(3125, -5), contracts: 2, want: 125000},
+		{name: "empty price", contract: XBTUSD, contracts: 100, leverage: money.One},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.contract.Margin(tt.price, tt.contracts, tt.leverage))
+		})
+	}
+}
+
+func TestContract_Contracts(t *testing.T) {
+	tests := []struct {
+		name     string
+		contract Contract
+		price    money.Decimal
+		amount   money.Satoshi
+		want     int64
+		wantErr  bool
+	}{
+		{name: "inverse truncated to lot", contract: XBTUSD, price: money.FromInt(9000), amount: money.FromBTCFloat(0.135), want: 1200},
+		{name: "inverse less than lot", contract: XBTUSD, price: money.FromInt(9000), amount: 1000000},
+		{name: "quanto", contract: ethusd, price: money.FromInt(200), amount: 210000, want: 10},
+		{name: "linear", contract: ethxbt, price: money.New(3125, -5), amount: 9375000, want: 3},
+		{name: "empty price", contract: XBTUSD, amount: 1000000, wantErr: true},
+		{name: "empty contract", price: money.FromInt(200), amount: 1000000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.contract.Contracts(tt.price, tt.amount)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestContract_Round(t *testing.T) {
+	require.Equal(t, money.New(91235, -1), XBTUSD.RoundPrice(money.FromFloat(9123.74)))
+	require.Equal(t, money.New(20005, -2), ethusd.RoundPrice(money.FromFloat(200.03)))
+	require.Equal(t, money.FromInt(300), XBTUSD.RoundQty(money.FromInt(345)))
+	require.Equal(t, money.FromInt(345), ethusd.RoundQty(money.FromInt(345)))
+}
@@ -0,0 +1,173 @@
+package trademath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type DivergenceType uint8
+
+const (
+	RegularBullDivergence DivergenceType = iota + 1 // price lower low, oscillator higher low
+	RegularBearDivergence                           // price higher high, oscillator lower high
+	HiddenBullDivergence                            // price higher low, oscillator lower low
+	HiddenBearDivergence                            // price lower high, oscillator higher high
+)
+
+func (t DivergenceType) String() string {
+	switch t {
+	case RegularBullDivergence:
+		return "regular bull"
+	case RegularBearDivergence:
+		return "regular bear"
+	case HiddenBullDivergence:
+		return "hidden bull"
+	case HiddenBearDivergence:
+		return "hidden bear"
+	default:
+		return fmt.Sprintf("divergence(%d)", uint8(t))
+	}
+}
+
+// Bull returns true for regular and hidden bull divergences
+func (t DivergenceType) Bull() bool {
+	return t == RegularBullDivergence || t == HiddenBullDivergence
+}
+
+// DivergenceParams pivot lookback and distance between two pivots of a divergence
+type DivergenceParams struct {
+	PivotLeft  int // count of values before the pivot, recommendation: 5
+	PivotRight int // count of values after the pivot, the pivot is confirmed PivotRight values later, recommendation: 5
+	MinRange   int // min count of values between pivots, 0 - not limited
+	MaxRange   int // max count of values between pivots, 0 - not limited
+}
+
+func (p DivergenceParams) validate() error {
+	if p.PivotLeft <= 0 || p.PivotRight < 0 {
+		return errors.New("pivot left lookback must be positive and right lookback not negative")
+	}
+	if p.MaxRange != 0 && p.MaxRange < p.MinRange {
+		return errors.New("divergence max range less than min range")
+	}
+	return nil
+}
+
+// Divergence of price and oscillator between two pivots of the oscillator, First is before Second
+type Divergence struct {
+	Type        DivergenceType
+	First       int // index of the first pivot
+	Second      int // index of the second pivot
+	FirstPrice  float64
+	SecondPrice float64
+	FirstOsc    float64
+	SecondOsc   float64
+	// Strength sum of relative changes of price and oscillator between pivots
+	Strength float64
+}
+
+// FindDivergences returns divergences of the price and oscillator by pairs of neighboring oscillator pivots,
+// pivots lows define bull divergences and pivot highs define bear divergences.
+// Values must be ordered from old to new and have the same length, the input is not changed.
+// Divergences are ordered by the second pivot, bull divergence goes before bear one with the same second pivot
+func FindDivergences(prices, oscillator []float64, params DivergenceParams) ([]Divergence, error) {
+	if len(prices) != len(oscillator) {
+		return nil, fmt.Errorf("prices count %d not equal oscillator count %d", len(prices), len(oscillator))
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	lows := PivotLows(oscillator, params.PivotLeft, params.PivotRight)
+	highs := PivotHighs(oscillator, params.PivotLeft, params.PivotRight)
+
+	var (
+		result       []Divergence
+		lowI, highI  = 1, 1
+		detectInPair = func(pivots []int, i int, bull bool) {
+			if d, ok := divergence(prices, oscillator, pivots[i-1], pivots[i], bull, params); ok {
+				result = append(result, d)
+			}
+		}
+	)
+	for lowI < len(lows) || highI < len(highs) {
+		if highI >= len(highs) || (lowI < len(lows) && lows[lowI] <= highs[highI]) {
+			detectInPair(lows, lowI, true)
+			lowI++
+			continue
+		}
+		detectInPair(highs, highI, false)
+		highI++
+	}
+	return result, nil
+}
+
+// divergence checks divergence between first and second pivots
+func divergence(prices, oscillator []float64, first, second int, bull bool, params DivergenceParams) (Divergence, bool) {
+	distance := second - first - 1
+	if distance < params.MinRange || (params.MaxRange != 0 && distance > params.MaxRange) {
+		return Divergence{}, false
+	}
+
+	d := Divergence{
+		First:       first,
+		Second:      second,
+		FirstPrice:  prices[first],
+		SecondPrice: prices[second],
+		FirstOsc:    oscillator[first],
+		SecondOsc:   oscillator[second],
+	}
+	priceUp, oscUp := d.SecondPrice > d.FirstPrice, d.SecondOsc > d.FirstOsc
+	priceDown, oscDown := d.SecondPrice < d.FirstPrice, d.SecondOsc < d.FirstOsc
+	switch {
+	case bull && priceDown && oscUp:
+		d.Type = RegularBullDivergence
+	case !bull && priceUp && oscDown:
+		d.Type = RegularBearDivergence
+	case bull && priceUp && oscDown:
+		d.Type = HiddenBullDivergence
+	case !bull && priceDown && oscUp:
+		d.Type = HiddenBearDivergence
+	default:
+		return Divergence{}, false
+	}
+	d.Strength = RoundFloat(relativeChange(d.FirstPrice, d.SecondPrice)+relativeChange(d.FirstOsc, d.SecondOsc), 4)
+	return d, true
+}
+
+// relativeChange returns absolute change of the value relative to the greatest absolute value
+func relativeChange(from, to float64) float64 {
+	base := math.Max(math.Abs(from), math.Abs(to))
+	if base == 0 {
+		return 0
+	}
+	return math.Abs(to-from) / base
+}
+
+// PivotLows returns indexes of values lower than left values before them and not greater than right values after them
+func PivotLows(values []float64, left, right int) []int {
+	return pivots(values, left, right, func(pivot, value float64) bool { return pivot < value })
+}
+
+// PivotHighs returns indexes of values greater than left values before them and not lower than right values after them
+func PivotHighs(values []float64, left, right int) []int {
+	return pivots(values, left, right, func(pivot, value float64) bool { return pivot > value })
+}
+
+// pivots returns indexes of pivots, beyond reports that pivot is beyond the value
+func pivots(values []float64, left, right int, beyond func(pivot, value float64) bool) []int {
+	var result []int
+	for i := left; i < len(values)-right; i++ {
+		pivot := true
+		for j := i - left; j < i && pivot; j++ {
+			pivot = beyond(values[i], values[j])
+		}
+		for j := i + 1; j <= i+right && pivot; j++ {
+			pivot = !beyond(values[j], values[i])
+		}
+		if pivot {
+			result = append(result, i)
+		}
+	}
+	return result
+}
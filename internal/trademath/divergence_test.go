@@ -0,0 +1,155 @@
+package trademath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPivots(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []float64
+		left, right int
+		wantLows    []int
+		wantHighs   []int
+	}{
+		{
+			name:      "zigzag",
+			values:    []float64{0, -2, -5, -3, -1, -4, -2, 0, 3, 6, 4, 2, 5, 3, 1},
+			left:      1,
+			right:     1,
+			wantLows:  []int{2, 5, 11},
+			wantHighs: []int{4, 9, 12},
+		},
+		{
+			name:      "wide lookback",
+			values:    []float64{0, -2, -5, -3, -1, -4, -2, 0, 3, 6, 4, 2, 5, 3, 1},
+			left:      3,
+			right:     3,
+			wantHighs: []int{9},
+		},
+		{
+			name:      "flat pivot is the first value",
+			values:    []float64{3, 1, 1, 2, 2, 0},
+			left:      1,
+			right:     1,
+			wantLows:  []int{1},
+			wantHighs: []int{3},
+		},
+		{
+			name:   "not enough values",
+			values: []float64{3, 1},
+			left:   1,
+			right:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantLows, PivotLows(tt.values, tt.left, tt.right))
+			require.Equal(t, tt.wantHighs, PivotHighs(tt.values, tt.left, tt.right))
+		})
+	}
+}
+
+func TestFindDivergences(t *testing.T) {
+	var (
+		osc    = []float64{0, -2, -5, -3, -1, -4, -2, 0, 3, 6, 4, 2, 5, 3, 1}
+		prices = []float64{100, 98, 95, 97, 99, 94, 96, 99, 102, 105, 104, 103, 107, 104, 102}
+		pivot  = DivergenceParams{PivotLeft: 1, PivotRight: 1}
+	)
+	with := func(values []float64, i int, value float64) []float64 {
+		result := append([]float64(nil), values...)
+		result[i] = value
+		return result
+	}
+	tests := []struct {
+		name    string
+		prices  []float64
+		osc     []float64
+		params  DivergenceParams
+		want    []Divergence
+		wantErr bool
+	}{
+		{
+			name:   "regular bull and bear",
+			prices: prices,
+			osc:    osc,
+			params: pivot,
+			want: []Divergence{
+				{
+					Type: RegularBullDivergence, First: 2, Second: 5,
+					FirstPrice: 95, SecondPrice: 94, FirstOsc: -5, SecondOsc: -4, Strength: 0.2105,
+				},
+				{
+					Type: RegularBearDivergence, First: 9, Second: 12,
+					FirstPrice: 105, SecondPrice: 107, FirstOsc: 6, SecondOsc: 5, Strength: 0.1854,
+				},
+			},
+		},
+		{
+			name:   "hidden bear",
+			prices: with(prices, 12, 103),
+			osc:    with(osc, 12, 7),
+			params: DivergenceParams{PivotLeft: 1, PivotRight: 1, MaxRange: 2},
+			want: []Divergence{
+				{
+					Type: RegularBullDivergence, First: 2, Second: 5,
+					FirstPrice: 95, SecondPrice: 94, FirstOsc: -5, SecondOsc: -4, Strength: 0.2105,
+				},
+				{
+					Type: HiddenBearDivergence, First: 9, Second: 12,
+					FirstPrice: 105, SecondPrice: 103, FirstOsc: 6, SecondOsc: 7, Strength: 0.1619,
+				},
+			},
+		},
+		{
+			name:   "pivots too close",
+			prices: prices,
+			osc:    osc,
+			params: DivergenceParams{PivotLeft: 1, PivotRight: 1, MinRange: 3, MaxRange: 4},
+		},
+		{
+			name:    "different length",
+			prices:  prices[1:],
+			osc:     osc,
+			params:  pivot,
+			wantErr: true,
+		},
+		{
+			name:    "invalid lookback",
+			prices:  prices,
+			osc:     osc,
+			params:  DivergenceParams{PivotRight: 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			prices:  prices,
+			osc:     osc,
+			params:  DivergenceParams{PivotLeft: 1, MinRange: 3, MaxRange: 2},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricesCopy := append([]float64(nil), tt.prices...)
+			oscCopy := append([]float64(nil), tt.osc...)
+
+			got, err := FindDivergences(tt.prices, tt.osc, tt.params)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.want))
+			for i := range tt.want {
+				require.InDelta(t, tt.want[i].Strength, got[i].Strength, delta)
+				got[i].Strength = tt.want[i].Strength
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, pricesCopy, tt.prices)
+			require.Equal(t, oscCopy, tt.osc)
+		})
+	}
+}
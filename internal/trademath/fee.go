@@ -0,0 +1,103 @@
+package trademath
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+)
+
+// Fees maker and taker fee rates of the contract, negative rate is rebate
+type Fees struct {
+	Maker money.Decimal
+	Taker money.Decimal
+}
+
+// Rate returns maker rate for passive orders which only add liquidity, otherwise taker rate
+func (f Fees) Rate(passive bool) money.Decimal {
+	if passive {
+		return f.Maker
+	}
+	return f.Taker
+}
+
+// Fee of the order of the contracts executed by the price, negative fee is rebate
+func (c Contract) Fee(price money.Decimal, contracts int64, passive bool) money.Satoshi {
+	if price.Sign() <= 0 {
+		return 0
+	}
+	return money.Satoshi(money.RoundRat(c.fee(price, contracts, passive)))
+}
+
+// NetPNL pnl of the position opened by openPrice and closed by lastPrice without opening and closing fees,
+// passive order fee is maker fee, otherwise it is taker fee
+func (c Contract) NetPNL(
+	openPrice, lastPrice money.Decimal, contracts int64, openPassive, closePassive bool,
+) money.Satoshi {
+	if openPrice.Sign() <= 0 || lastPrice.Sign() <= 0 {
+		return 0
+	}
+	open, last := c.value(openPrice, contracts), c.value(lastPrice, contracts)
+	pnl := new(big.Rat).Sub(last, open)
+	if c.IsInverse {
+		pnl.Neg(pnl)
+	}
+	pnl.Sub(pnl, c.fee(openPrice, contracts, openPassive))
+	pnl.Sub(pnl, c.fee(lastPrice, contracts, closePassive))
+	return money.Satoshi(money.RoundRat(pnl))
+}
+
+// BreakEvenPrice price of closing the position with zero NetPNL, it is rounded to the tick size
+// against the position: up for long and down for short
+func (c Contract) BreakEvenPrice(
+	openPrice money.Decimal, contracts int64, openPassive, closePassive bool,
+) (money.Decimal, error) {
+	if openPrice.Sign() <= 0 || contracts == 0 {
+		return money.Zero, errors.New("position is empty")
+	}
+	var (
+		one       = big.NewRat(1, 1)
+		openRate  = decimalRat(c.Fees.Rate(openPassive))
+		closeRate = decimalRat(c.Fees.Rate(closePassive))
+		long      = contracts > 0
+		num, den  *big.Rat
+	)
+	// inverse long: (1 - open rate) / open = (1 + close rate) / price,
+	// linear long: price * (1 - close rate) = open * (1 + open rate), short is the same with negative rates
+	if !long {
+		openRate.Neg(openRate)
+		closeRate.Neg(closeRate)
+	}
+	if c.IsInverse {
+		num, den = new(big.Rat).Add(one, closeRate), new(big.Rat).Sub(one, openRate)
+	} else {
+		num, den = new(big.Rat).Add(one, openRate), new(big.Rat).Sub(one, closeRate)
+	}
+	if num.Sign() <= 0 || den.Sign() <= 0 {
+		return money.Zero, errors.New("fee rate is too large")
+	}
+	price := new(big.Rat).Mul(decimalRat(openPrice), num)
+	return ratStep(price.Quo(price, den), c.TickSize, long), nil
+}
+
+// fee signed fee of the contracts, it is positive for both sides if the rate is positive
+func (c Contract) fee(price money.Decimal, contracts int64, passive bool) *big.Rat {
+	value := c.value(price, contracts)
+	value.Abs(value)
+	return value.Mul(value, decimalRat(c.Fees.Rate(passive)))
+}
+
+// ratStep returns the positive number rounded up or down to a multiple of the step,
+// empty step is the smallest decimal unit
+func ratStep(r *big.Rat, step money.Decimal, up bool) money.Decimal {
+	stepUnits := step.Units()
+	if stepUnits <= 0 {
+		stepUnits = 1
+	}
+	units := new(big.Rat).Mul(r, big.NewRat(money.One.Units(), stepUnits))
+	quo, rem := new(big.Int).QuoRem(units.Num(), units.Denom(), new(big.Int))
+	if up && rem.Sign() != 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return money.New(quo.Int64()*stepUnits, -money.Scale)
+}
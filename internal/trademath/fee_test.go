@@ -0,0 +1,88 @@
+package trademath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
+)
+
+func TestContract_Fee(t *testing.T) {
+	require.Equal(t, money.Satoshi(938), XBTUSD.Fee(money.FromInt(8000), 100, false))
+	require.Equal(t, money.Satoshi(938), XBTUSD.Fee(money.FromInt(8000), -100, false))
+	require.Equal(t, money.Satoshi(-313), XBTUSD.Fee(money.FromInt(8000), 100, true))
+	require.Equal(t, money.Satoshi(0), ethusd.Fee(money.FromInt(200), 10, false))
+	require.Equal(t, money.Satoshi(0), XBTUSD.Fee(money.Zero, 100, false))
+}
+
+func TestContract_NetPNL(t *testing.T) {
+	tests := []struct {
+		name         string
+		contract     Contract
+		open         money.Decimal
+		last         money.Decimal
+		contracts    int64
+		openPassive  bool
+		closePassive bool
+		want         money.Satoshi
+	}{
+		{name: "market entry and exit", contract: XBTUSD, open: money.FromInt(8000), last: money.FromInt(9000), contracts: 100, want: 137118},
+		{
+			name: "passive entry and exit rebates", contract: XBTUSD, open: money.FromInt(8000), last: money.FromInt(9000), contracts: 100,
+			openPassive: true, closePassive: true, want: 139479,
+		},
+		{
+			name: "passive entry and market exit", contract: XBTUSD, open: money.FromInt(8000), last: money.FromInt(8000), contracts: -100,
+			openPassive: true, want: -625,
+		},
+		{name: "without fees", contract: ethusd, open: money.FromInt(200), last: money.FromInt(210), contracts: 10, want: 10000},
+		{name: "empty price", contract: XBTUSD, last: money.FromInt(9000), contracts: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.contract.NetPNL(tt.open, tt.last, tt.contracts, tt.openPassive, tt.closePassive)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestContract_BreakEvenPrice(t *testing.T) {
+	linear := ethxbt
+	linear.Fees = Fees{Taker: money.New(1, -3)}
+	tooLarge := XBTUSD
+	tooLarge.Fees = Fees{Taker: money.One}
+
+	tests := []struct {
+		name         string
+		contract     Contract
+		open         money.Decimal
+		contracts    int64
+		openPassive  bool
+		closePassive bool
+		want         money.Decimal
+		wantErr      bool
+	}{
+		{name: "inverse long market", contract: XBTUSD, open: money.FromInt(8000), contracts: 100, want: money.New(80125, -1)},
+		{
+			name: "inverse long passive", contract: XBTUSD, open: money.FromInt(8000), contracts: 100,
+			openPassive: true, closePassive: true, want: money.New(79965, -1),
+		},
+		{name: "inverse short market", contract: XBTUSD, open: money.FromInt(8000), contracts: -100, want: money.FromInt(7988)},
+		{name: "linear long market", contract: linear, open: money.New(3125, -5), contracts: 2, want: money.New(3132, -5)},
+		{name: "without fees", contract: ethusd, open: money.FromInt(200), contracts: 10, want: money.FromInt(200)},
+		{name: "empty position", contract: XBTUSD, open: money.FromInt(8000), wantErr: true},
+		{name: "fee rate too large", contract: tooLarge, open: money.FromInt(8000), contracts: 100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.contract.BreakEvenPrice(tt.open, tt.contracts, tt.openPassive, tt.closePassive)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
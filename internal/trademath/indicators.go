@@ -0,0 +1,302 @@
+package trademath
+
+import (
+	"math"
+
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// OHLCV candles prices and volumes, all slices have the same length and are ordered from old to new candles
+type OHLCV struct {
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+}
+
+// NewOHLCV returns prices and volumes of the ordered candles
+func NewOHLCV(candles []bitmex.TradeBuck) OHLCV {
+	var o = OHLCV{
+		Open:   make([]float64, 0, len(candles)),
+		High:   make([]float64, 0, len(candles)),
+		Low:    make([]float64, 0, len(candles)),
+		Close:  make([]float64, 0, len(candles)),
+		Volume: make([]float64, 0, len(candles)),
+	}
+	for _, candle := range candles {
+		o.Open = append(o.Open, candle.Open)
+		o.High = append(o.High, candle.High)
+		o.Low = append(o.Low, candle.Low)
+		o.Close = append(o.Close, candle.Close)
+		o.Volume = append(o.Volume, float64(candle.Volume))
+	}
+	return o
+}
+
+// Len returns count of candles
+func (o OHLCV) Len() int {
+	return len(o.Close)
+}
+
+// Median returns (high + low) / 2 of every candle
+func (o OHLCV) Median() []float64 {
+	var result = make([]float64, 0, o.Len())
+	for i := range o.Close {
+		result = append(result, (o.High[i]+o.Low[i])/2)
+	}
+	return result
+}
+
+type Stochastic struct {
+	K float64 // %K line
+	D float64 // %D signal line
+}
+
+type DMI struct {
+	ADX     float64 // average directional index
+	PlusDI  float64 // +DI
+	MinusDI float64 // -DI
+}
+
+type SAR struct {
+	Value float64
+	Up    bool // true, sar is below the price
+}
+
+type Channel struct {
+	TL float64 // top
+	ML float64 // middle
+	BL float64 // bottom
+}
+
+type Ichimoku struct {
+	Conversion float64 // tenkan-sen
+	Base       float64 // kijun-sen
+	SpanA      float64 // senkou span A of the last candle, it was calculated base period candles ago
+	SpanB      float64 // senkou span B of the last candle, it was calculated base period candles ago
+	LeadSpanA  float64 // senkou span A calculated by the last candle, it is shown base period candles ahead
+	LeadSpanB  float64 // senkou span B calculated by the last candle, it is shown base period candles ahead
+}
+
+type SuperTrend struct {
+	Value float64
+	Up    bool // true, uptrend and the value is below the price
+}
+
+// CalcATR average true range with Wilder smoothing, recommendation: period = 14
+func (c *Calc) CalcATR(o OHLCV, period int) float64 {
+	if period <= 0 || o.Len() <= period {
+		return 0
+	}
+	return lastRounded(talib.Atr(o.High, o.Low, o.Close, period))
+}
+
+// CalcAO awesome oscillator, sma of median prices with fast period minus sma with slow period,
+// recommendation: fast = 5, slow = 34
+func (c *Calc) CalcAO(o OHLCV, fastPeriod, slowPeriod int) float64 {
+	if fastPeriod <= 0 || fastPeriod >= slowPeriod || o.Len() < slowPeriod {
+		return 0
+	}
+	return lastRounded(awesomeOscillator(o, fastPeriod, slowPeriod))
+}
+
+// CalcAC accelerator oscillator, awesome oscillator minus its sma with signal period,
+// recommendation: fast = 5, slow = 34, signal = 5
+func (c *Calc) CalcAC(o OHLCV, fastPeriod, slowPeriod, signalPeriod int) float64 {
+	if fastPeriod <= 0 || fastPeriod >= slowPeriod || signalPeriod <= 0 || o.Len() < slowPeriod+signalPeriod-1 {
+		return 0
+	}
+	ao := awesomeOscillator(o, fastPeriod, slowPeriod)[slowPeriod-1:]
+	signal := talib.Sma(ao, signalPeriod)
+	return RoundFloat(ao[len(ao)-1]-signal[len(signal)-1], 4)
+}
+
+// CalcSAR parabolic sar, recommendation: acceleration = 0.02, maximum = 0.2
+func (c *Calc) CalcSAR(o OHLCV, acceleration, maximum float64) SAR {
+	if o.Len() < 2 {
+		return SAR{}
+	}
+	sar := talib.Sar(o.High, o.Low, acceleration, maximum)
+	value := sar[len(sar)-1]
+	return SAR{
+		Value: RoundFloat(value, 4),
+		Up:    value < o.Low[o.Len()-1],
+	}
+}
+
+// CalcStochastic slow stochastic oscillator, recommendation: k period = 14, k slowing = 3, d period = 3, SMA
+func (c *Calc) CalcStochastic(o OHLCV, kPeriod, kSlowing, dPeriod int, maType talib.MaType) Stochastic {
+	if kPeriod <= 0 || kSlowing <= 0 || dPeriod <= 0 || o.Len() < kPeriod+kSlowing+dPeriod-2 {
+		return Stochastic{}
+	}
+	k, d := talib.Stoch(o.High, o.Low, o.Close, kPeriod, kSlowing, maType, dPeriod, maType)
+	return Stochastic{K: lastRounded(k), D: lastRounded(d)}
+}
+
+// CalcStochRSI stochastic oscillator of rsi by closes,
+// recommendation: rsi period = 14, k period = 14, d period = 3, SMA
+func (c *Calc) CalcStochRSI(o OHLCV, rsiPeriod, kPeriod, dPeriod int, maType talib.MaType) Stochastic {
+	if rsiPeriod <= 0 || kPeriod <= 0 || dPeriod <= 0 || o.Len() < rsiPeriod+kPeriod+dPeriod-1 {
+		return Stochastic{}
+	}
+	k, d := talib.StochRsi(o.Close, rsiPeriod, kPeriod, dPeriod, maType)
+	return Stochastic{K: lastRounded(k), D: lastRounded(d)}
+}
+
+// CalcADX average directional index and directional indicators, recommendation: period = 14
+func (c *Calc) CalcADX(o OHLCV, period int) DMI {
+	if period <= 0 || o.Len() < 2*period {
+		return DMI{}
+	}
+	return DMI{
+		ADX:     lastRounded(talib.Adx(o.High, o.Low, o.Close, period)),
+		PlusDI:  lastRounded(talib.PlusDI(o.High, o.Low, o.Close, period)),
+		MinusDI: lastRounded(talib.MinusDI(o.High, o.Low, o.Close, period)),
+	}
+}
+
+// CalcIchimoku ichimoku cloud lines, recommendation: conversion = 9, base = 26, span B = 52
+func (c *Calc) CalcIchimoku(o OHLCV, conversionPeriod, basePeriod, spanBPeriod int) Ichimoku {
+	if conversionPeriod <= 0 || basePeriod <= 0 || spanBPeriod <= 0 {
+		return Ichimoku{}
+	}
+	last := o.Len() - 1
+	cloud := last - basePeriod
+	if cloud < spanBPeriod-1 || cloud < conversionPeriod-1 {
+		return Ichimoku{}
+	}
+	spanA := func(end int) float64 {
+		return (midRange(o, end, conversionPeriod) + midRange(o, end, basePeriod)) / 2
+	}
+	return Ichimoku{
+		Conversion: RoundFloat(midRange(o, last, conversionPeriod), 4),
+		Base:       RoundFloat(midRange(o, last, basePeriod), 4),
+		SpanA:      RoundFloat(spanA(cloud), 4),
+		SpanB:      RoundFloat(midRange(o, cloud, spanBPeriod), 4),
+		LeadSpanA:  RoundFloat(spanA(last), 4),
+		LeadSpanB:  RoundFloat(midRange(o, last, spanBPeriod), 4),
+	}
+}
+
+// CalcKeltner keltner channel, ema of closes plus and minus atr multiplied by multiplier,
+// recommendation: ema period = 20, atr period = 10, multiplier = 2
+func (c *Calc) CalcKeltner(o OHLCV, emaPeriod, atrPeriod int, multiplier float64) Channel {
+	if emaPeriod <= 0 || atrPeriod <= 0 || o.Len() < emaPeriod || o.Len() <= atrPeriod {
+		return Channel{}
+	}
+	ml := talib.Ema(o.Close, emaPeriod)
+	atr := talib.Atr(o.High, o.Low, o.Close, atrPeriod)
+	middle, offset := ml[len(ml)-1], multiplier*atr[len(atr)-1]
+	return Channel{
+		TL: RoundFloat(middle+offset, 4),
+		ML: RoundFloat(middle, 4),
+		BL: RoundFloat(middle-offset, 4),
+	}
+}
+
+// CalcDonchian donchian channel, the highest high and the lowest low of the last period candles,
+// recommendation: period = 20
+func (c *Calc) CalcDonchian(o OHLCV, period int) Channel {
+	if period <= 0 || o.Len() < period {
+		return Channel{}
+	}
+	high, low := extremes(o, o.Len()-1, period)
+	return Channel{
+		TL: RoundFloat(high, 4),
+		ML: RoundFloat((high+low)/2, 4),
+		BL: RoundFloat(low, 4),
+	}
+}
+
+// CalcCCI commodity channel index, recommendation: period = 20
+func (c *Calc) CalcCCI(o OHLCV, period int) float64 {
+	if period <= 0 || o.Len() < period {
+		return 0
+	}
+	return lastRounded(talib.Cci(o.High, o.Low, o.Close, period))
+}
+
+// CalcMFI money flow index, recommendation: period = 14
+func (c *Calc) CalcMFI(o OHLCV, period int) float64 {
+	if period <= 0 || o.Len() <= period {
+		return 0
+	}
+	return lastRounded(talib.Mfi(o.High, o.Low, o.Close, o.Volume, period))
+}
+
+// CalcSuperTrend supertrend by median price and atr multiplied by multiplier,
+// the trend of the first candle with atr is up if its close is not below the median,
+// recommendation: period = 10, multiplier = 3
+func (c *Calc) CalcSuperTrend(o OHLCV, period int, multiplier float64) SuperTrend {
+	if period <= 0 || o.Len() <= period {
+		return SuperTrend{}
+	}
+	var (
+		atr          = talib.Atr(o.High, o.Low, o.Close, period)
+		median       = o.Median()
+		upper, lower float64
+		up           bool
+	)
+	for i := period; i < o.Len(); i++ {
+		basicUpper := median[i] + multiplier*atr[i]
+		basicLower := median[i] - multiplier*atr[i]
+		if i == period {
+			upper, lower, up = basicUpper, basicLower, o.Close[i] >= median[i]
+			continue
+		}
+		if basicUpper < upper || o.Close[i-1] > upper {
+			upper = basicUpper
+		}
+		if basicLower > lower || o.Close[i-1] < lower {
+			lower = basicLower
+		}
+		switch {
+		case up && o.Close[i] < lower:
+			up = false
+		case !up && o.Close[i] > upper:
+			up = true
+		}
+	}
+	if up {
+		return SuperTrend{Value: RoundFloat(lower, 4), Up: true}
+	}
+	return SuperTrend{Value: RoundFloat(upper, 4)}
+}
+
+// awesomeOscillator returns awesome oscillator values, the first slowPeriod-1 values are zero
+func awesomeOscillator(o OHLCV, fastPeriod, slowPeriod int) []float64 {
+	median := o.Median()
+	fast := talib.Sma(median, fastPeriod)
+	slow := talib.Sma(median, slowPeriod)
+	var result = make([]float64, len(median))
+	for i := slowPeriod - 1; i < len(median); i++ {
+		result[i] = fast[i] - slow[i]
+	}
+	return result
+}
+
+// extremes returns the highest high and the lowest low of period candles ending with the end candle
+func extremes(o OHLCV, end, period int) (high, low float64) {
+	high, low = o.High[end], o.Low[end]
+	for i := end - period + 1; i < end; i++ {
+		high = math.Max(high, o.High[i])
+		low = math.Min(low, o.Low[i])
+	}
+	return high, low
+}
+
+// midRange returns middle of the highest high and the lowest low of period candles ending with the end candle
+func midRange(o OHLCV, end, period int) float64 {
+	high, low := extremes(o, end, period)
+	return (high + low) / 2
+}
+
+func lastRounded(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return RoundFloat(values[len(values)-1], 4)
+}
@@ -0,0 +1,278 @@
+package trademath
+
+import (
+	"testing"
+
+	"github.com/markcheno/go-talib"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// delta of the reference values, RoundFloat can round the fourth decimal up
+const delta = 0.0002
+
+// testOHLCV returns 40 candles of a sine wave with uptrend, reference values are calculated by independent
+// implementation of the indicators formulas
+func testOHLCV() OHLCV {
+	var o = OHLCV{
+		High: []float64{
+			101, 104.77, 108.39, 108.72, 111.61, 113.99, 112.77, 113.94, 114.49, 111.48,
+			110.98, 110.12, 106.01, 104.82, 103.69, 99.78, 99.23, 99.15, 96.62, 97.71,
+			99.41, 98.71, 101.54, 104.82, 105.41, 109.17, 112.95, 113.6, 116.97, 119.93,
+			119.38, 121.25, 122.49, 120.13, 120.18, 119.75, 115.92, 114.84, 113.65, 109.5,
+		},
+		Low: []float64{
+			99, 101.27, 103.39, 105.22, 108.61, 109.49, 109.77, 109.44, 110.49, 108.98,
+			106.98, 104.62, 104.01, 101.32, 98.69, 96.28, 96.23, 94.65, 93.62, 93.21,
+			95.41, 96.21, 97.54, 99.32, 103.41, 105.67, 107.95, 110.1, 113.97, 115.43,
+			116.38, 116.75, 118.49, 117.63, 116.18, 114.25, 113.92, 111.34, 108.65, 106,
+		},
+		Close: []float64{
+			100, 102.77, 105.39, 107.72, 109.61, 110.99, 111.77, 111.94, 111.49, 110.48,
+			108.98, 107.12, 105.01, 102.82, 100.69, 98.78, 97.23, 96.15, 95.62, 95.71,
+			96.41, 97.71, 99.54, 101.82, 104.41, 107.17, 109.95, 112.6, 114.97, 116.93,
+			118.38, 119.25, 119.49, 119.13, 118.18, 116.75, 114.92, 112.84, 110.65, 108.5,
+		},
+		Volume: []float64{
+			100, 137, 124, 111, 148, 135, 122, 109, 146, 133,
+			120, 107, 144, 131, 118, 105, 142, 129, 116, 103,
+			140, 127, 114, 101, 138, 125, 112, 149, 136, 123,
+			110, 147, 134, 121, 108, 145, 132, 119, 106, 143,
+		},
+	}
+	o.Open = append([]float64{o.Close[0]}, o.Close[:len(o.Close)-1]...)
+	return o
+}
+
+// head returns the first n candles
+func (o OHLCV) head(n int) OHLCV {
+	return OHLCV{Open: o.Open[:n], High: o.High[:n], Low: o.Low[:n], Close: o.Close[:n], Volume: o.Volume[:n]}
+}
+
+func TestNewOHLCV(t *testing.T) {
+	got := NewOHLCV([]bitmex.TradeBuck{
+		{Open: 1, High: 3, Low: 0.5, Close: 2, Volume: 10},
+		{Open: 2, High: 4, Low: 1, Close: 3, Volume: 20},
+	})
+	require.Equal(t, OHLCV{
+		Open:   []float64{1, 2},
+		High:   []float64{3, 4},
+		Low:    []float64{0.5, 1},
+		Close:  []float64{2, 3},
+		Volume: []float64{10, 20},
+	}, got)
+	require.Equal(t, 2, got.Len())
+	require.Equal(t, []float64{1.75, 2.5}, got.Median())
+}
+
+func TestCalc_SingleValueIndicators(t *testing.T) {
+	var (
+		c = &Calc{}
+		o = testOHLCV()
+	)
+	tests := []struct {
+		name string
+		calc func(o OHLCV) float64
+		o    OHLCV
+		want float64
+	}{
+		{
+			name: "atr",
+			calc: func(o OHLCV) float64 { return c.CalcATR(o, 14) },
+			o:    o,
+			want: 4.1206,
+		},
+		{
+			name: "atr not enough candles",
+			calc: func(o OHLCV) float64 { return c.CalcATR(o, 14) },
+			o:    o.head(14),
+			want: 0,
+		},
+		{
+			name: "awesome oscillator",
+			calc: func(o OHLCV) float64 { return c.CalcAO(o, 5, 20) },
+			o:    o,
+			want: 1.677,
+		},
+		{
+			name: "awesome oscillator not enough candles",
+			calc: func(o OHLCV) float64 { return c.CalcAO(o, 5, 20) },
+			o:    o.head(19),
+			want: 0,
+		},
+		{
+			name: "accelerator oscillator",
+			calc: func(o OHLCV) float64 { return c.CalcAC(o, 5, 20, 5) },
+			o:    o,
+			want: -4.8198,
+		},
+		{
+			name: "accelerator oscillator not enough candles",
+			calc: func(o OHLCV) float64 { return c.CalcAC(o, 5, 20, 5) },
+			o:    o.head(23),
+			want: 0,
+		},
+		{
+			name: "cci",
+			calc: func(o OHLCV) float64 { return c.CalcCCI(o, 20) },
+			o:    o,
+			want: -32.7227,
+		},
+		{
+			name: "mfi",
+			calc: func(o OHLCV) float64 { return c.CalcMFI(o, 14) },
+			o:    o,
+			want: 51.4182,
+		},
+		{
+			name: "mfi not enough candles",
+			calc: func(o OHLCV) float64 { return c.CalcMFI(o, 14) },
+			o:    o.head(14),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.want, tt.calc(tt.o), delta)
+		})
+	}
+}
+
+func TestCalc_CalcStochastic(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	got := c.CalcStochastic(o, 14, 3, 3, talib.SMA)
+	require.InDelta(t, 31.3973, got.K, delta)
+	require.InDelta(t, 48.2552, got.D, delta)
+	require.Equal(t, Stochastic{}, c.CalcStochastic(o.head(17), 14, 3, 3, talib.SMA))
+}
+
+func TestCalc_CalcStochRSI(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	for i := range o.Close {
+		if i%2 == 0 {
+			o.Close[i] -= 1.5
+		} else {
+			o.Close[i] += 1.5
+		}
+	}
+	got := c.CalcStochRSI(o, 14, 5, 3, talib.SMA)
+	require.InDelta(t, 8.1471, got.K, delta)
+	require.InDelta(t, 5.5546, got.D, delta)
+	require.Equal(t, Stochastic{}, c.CalcStochRSI(o.head(20), 14, 5, 3, talib.SMA))
+}
+
+func TestCalc_CalcADX(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	got := c.CalcADX(o, 14)
+	require.InDelta(t, 29.5948, got.ADX, delta)
+	require.InDelta(t, 21.9273, got.PlusDI, delta)
+	require.InDelta(t, 23.3181, got.MinusDI, delta)
+	require.Equal(t, DMI{}, c.CalcADX(o.head(27), 14))
+}
+
+func TestCalc_CalcSAR(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	tests := []struct {
+		name   string
+		o      OHLCV
+		wantUp bool
+	}{
+		{name: "uptrend", o: o.head(31), wantUp: true},
+		{name: "downtrend", o: o.head(19), wantUp: false},
+		{name: "downtrend after uptrend", o: o, wantUp: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.CalcSAR(tt.o, 0.02, 0.2)
+			require.Equal(t, tt.wantUp, got.Up)
+			last := tt.o.Len() - 1
+			if tt.wantUp {
+				require.Less(t, got.Value, tt.o.Low[last])
+			} else {
+				require.Greater(t, got.Value, tt.o.High[last])
+			}
+		})
+	}
+}
+
+func TestCalc_CalcIchimoku(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	require.Equal(t, Ichimoku{
+		Conversion: 110.42,
+		Base:       112.875,
+		SpanA:      119.335,
+		SpanB:      114.08,
+		LeadSpanA:  111.6475,
+		LeadSpanB:  114.245,
+	}, c.CalcIchimoku(o, 3, 5, 10))
+	require.Equal(t, Ichimoku{}, c.CalcIchimoku(o.head(14), 3, 5, 10))
+}
+
+func TestCalc_Channels(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	tests := []struct {
+		name string
+		got  Channel
+		want Channel
+	}{
+		{
+			name: "keltner",
+			got:  c.CalcKeltner(o, 20, 10, 2),
+			want: Channel{TL: 120.1227, ML: 111.8517, BL: 103.5806},
+		},
+		{
+			name: "keltner not enough candles",
+			got:  c.CalcKeltner(o.head(19), 20, 10, 2),
+		},
+		{
+			name: "donchian",
+			got:  c.CalcDonchian(o, 20),
+			want: Channel{TL: 122.49, ML: 108.95, BL: 95.41},
+		},
+		{
+			name: "donchian not enough candles",
+			got:  c.CalcDonchian(o.head(19), 20),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.want.TL, tt.got.TL, delta)
+			require.InDelta(t, tt.want.ML, tt.got.ML, delta)
+			require.InDelta(t, tt.want.BL, tt.got.BL, delta)
+		})
+	}
+}
+
+func TestCalc_CalcSuperTrend(t *testing.T) {
+	c := &Calc{}
+	o := testOHLCV()
+	tests := []struct {
+		name       string
+		period     int
+		multiplier float64
+		o          OHLCV
+		want       SuperTrend
+	}{
+		{name: "uptrend", period: 10, multiplier: 3, o: o, want: SuperTrend{Value: 107.9811, Up: true}},
+		{name: "downtrend", period: 5, multiplier: 1, o: o, want: SuperTrend{Value: 111.949}},
+		{name: "not enough candles", period: 10, multiplier: 3, o: o.head(10)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.CalcSuperTrend(tt.o, tt.period, tt.multiplier)
+			require.Equal(t, tt.want.Up, got.Up)
+			require.InDelta(t, tt.want.Value, got.Value, delta)
+		})
+	}
+}
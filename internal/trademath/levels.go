@@ -0,0 +1,188 @@
+package trademath
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type PivotType uint8
+
+const (
+	ClassicPivot PivotType = iota
+	FibonacciPivot
+	CamarillaPivot
+)
+
+// PivotPoints pivot point with resistance and support levels, R4 and S4 are calculated only by camarilla
+type PivotPoints struct {
+	P  float64
+	R1 float64
+	R2 float64
+	R3 float64
+	R4 float64
+	S1 float64
+	S2 float64
+	S3 float64
+	S4 float64
+}
+
+// Levels returns not empty levels ordered from S4 to R4
+func (p PivotPoints) Levels() []float64 {
+	var result []float64
+	for _, level := range []float64{p.S4, p.S3, p.S2, p.S1, p.P, p.R1, p.R2, p.R3, p.R4} {
+		if level != 0 {
+			result = append(result, level)
+		}
+	}
+	return result
+}
+
+// Swing swing high or swing low candle
+type Swing struct {
+	Index int // index of the candle
+	Price float64
+	High  bool // true, swing high by candle high, otherwise swing low by candle low
+}
+
+// Zone support or resistance zone of clustered swings
+type Zone struct {
+	Low     float64
+	High    float64
+	Level   float64 // average price of the swings
+	Touches int     // count of the swings
+}
+
+// Levels support and resistance levels of the candles
+type Levels struct {
+	Timestamp time.Time // timestamp of the last candle used for levels
+	Pivots    PivotPoints
+	Swings    []Swing
+	Zones     []Zone // ordered from the lowest zone
+}
+
+// CalcPivotPoints pivot points by high, low and close of the previous period candle, usually the previous day
+func (c *Calc) CalcPivotPoints(high, low, close float64, pivotType PivotType) PivotPoints {
+	var (
+		p      = (high + low + close) / 3
+		r      = high - low
+		result = PivotPoints{P: p}
+	)
+	switch pivotType {
+	case FibonacciPivot:
+		result.R1, result.S1 = p+0.382*r, p-0.382*r
+		result.R2, result.S2 = p+0.618*r, p-0.618*r
+		result.R3, result.S3 = p+r, p-r
+	case CamarillaPivot:
+		result.R1, result.S1 = close+r*1.1/12, close-r*1.1/12
+		result.R2, result.S2 = close+r*1.1/6, close-r*1.1/6
+		result.R3, result.S3 = close+r*1.1/4, close-r*1.1/4
+		result.R4, result.S4 = close+r*1.1/2, close-r*1.1/2
+	default:
+		result.R1, result.S1 = 2*p-low, 2*p-high
+		result.R2, result.S2 = p+r, p-r
+		result.R3, result.S3 = high+2*(p-low), low-2*(high-p)
+	}
+	return roundPivots(result)
+}
+
+// FindSwings returns swing highs and lows of the candles ordered by index, swing is confirmed
+// when it is the highest high or the lowest low of lookback candles before and after it
+func FindSwings(o OHLCV, lookback int) []Swing {
+	if lookback <= 0 {
+		return nil
+	}
+	var result []Swing
+	for _, i := range PivotHighs(o.High, lookback, lookback) {
+		result = append(result, Swing{Index: i, Price: o.High[i], High: true})
+	}
+	for _, i := range PivotLows(o.Low, lookback, lookback) {
+		result = append(result, Swing{Index: i, Price: o.Low[i]})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Index < result[j].Index
+	})
+	return result
+}
+
+// ClusterZones clusters swing prices into zones, price is added to the zone if it is not farther than
+// width part of the zone low, for example 0.003. Zones with less than minTouches swings are skipped
+func ClusterZones(swings []Swing, width float64, minTouches int) []Zone {
+	var prices = make([]float64, 0, len(swings))
+	for _, swing := range swings {
+		prices = append(prices, swing.Price)
+	}
+	sort.Float64s(prices)
+
+	var (
+		result []Zone
+		zone   Zone
+		sum    float64
+	)
+	closeZone := func() {
+		if zone.Touches != 0 && zone.Touches >= minTouches {
+			zone.Level = RoundFloat(sum/float64(zone.Touches), 4)
+			result = append(result, zone)
+		}
+	}
+	for _, price := range prices {
+		if zone.Touches == 0 || price-zone.Low > zone.Low*width {
+			closeZone()
+			zone, sum = Zone{Low: price}, 0
+		}
+		zone.High = price
+		zone.Touches++
+		sum += price
+	}
+	closeZone()
+	return result
+}
+
+// Support returns the nearest pivot or zone level below the price
+func (l Levels) Support(price float64) (float64, bool) {
+	var (
+		result = math.Inf(-1)
+		ok     bool
+	)
+	for _, level := range l.levels() {
+		if level < price && level > result {
+			result, ok = level, true
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return result, true
+}
+
+// Resistance returns the nearest pivot or zone level above the price
+func (l Levels) Resistance(price float64) (float64, bool) {
+	var (
+		result = math.Inf(1)
+		ok     bool
+	)
+	for _, level := range l.levels() {
+		if level > price && level < result {
+			result, ok = level, true
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return result, true
+}
+
+func (l Levels) levels() []float64 {
+	result := l.Pivots.Levels()
+	for _, zone := range l.Zones {
+		result = append(result, zone.Level)
+	}
+	return result
+}
+
+func roundPivots(p PivotPoints) PivotPoints {
+	for _, level := range []*float64{&p.P, &p.R1, &p.R2, &p.R3, &p.R4, &p.S1, &p.S2, &p.S3, &p.S4} {
+		*level = RoundFloat(*level, 4)
+	}
+	return p
+}
@@ -0,0 +1,123 @@
+package trademath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalc_CalcPivotPoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		pivotType PivotType
+		want      []float64
+	}{
+		{
+			name:      "classic",
+			pivotType: ClassicPivot,
+			want:      []float64{73.3333, 81.6667, 93.3333, 101.6667, 113.3333, 121.6667, 133.3333},
+		},
+		{
+			name:      "fibonacci",
+			pivotType: FibonacciPivot,
+			want:      []float64{81.6667, 89.3067, 94.0267, 101.6667, 109.3067, 114.0267, 121.6667},
+		},
+		{
+			name:      "camarilla",
+			pivotType: CamarillaPivot,
+			want:      []float64{94, 99.5, 101.3333, 103.1667, 101.6667, 106.8333, 108.6667, 110.5, 116},
+		},
+	}
+	c := &Calc{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.CalcPivotPoints(110, 90, 105, tt.pivotType)
+			require.InDeltaSlice(t, tt.want, got.Levels(), delta)
+		})
+	}
+}
+
+func TestFindSwings(t *testing.T) {
+	o := OHLCV{
+		High: []float64{1, 2, 5, 2, 1, 2, 4, 2, 1},
+		Low:  []float64{0, 1, 4, 1, 0, 1, 3, 1, 0},
+	}
+	require.Equal(t, []Swing{
+		{Index: 2, Price: 5, High: true},
+		{Index: 4, Price: 0},
+		{Index: 6, Price: 4, High: true},
+	}, FindSwings(o, 2))
+	require.Nil(t, FindSwings(o, 0))
+	require.Nil(t, FindSwings(o, 5))
+}
+
+func TestClusterZones(t *testing.T) {
+	swings := []Swing{
+		{Index: 1, Price: 100}, {Index: 3, Price: 100.2, High: true}, {Index: 5, Price: 105, High: true},
+		{Index: 8, Price: 100.1}, {Index: 9, Price: 105.3, High: true}, {Index: 12, Price: 110, High: true},
+	}
+	tests := []struct {
+		name       string
+		minTouches int
+		want       []Zone
+	}{
+		{
+			name:       "zones of two touches",
+			minTouches: 2,
+			want: []Zone{
+				{Low: 100, High: 100.2, Level: 100.1, Touches: 3},
+				{Low: 105, High: 105.3, Level: 105.15, Touches: 2},
+			},
+		},
+		{
+			name: "all zones",
+			want: []Zone{
+				{Low: 100, High: 100.2, Level: 100.1, Touches: 3},
+				{Low: 105, High: 105.3, Level: 105.15, Touches: 2},
+				{Low: 110, High: 110, Level: 110, Touches: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClusterZones(swings, 0.003, tt.minTouches)
+			require.Len(t, got, len(tt.want))
+			for i := range tt.want {
+				require.InDelta(t, tt.want[i].Level, got[i].Level, delta)
+				got[i].Level = tt.want[i].Level
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+	require.Nil(t, ClusterZones(nil, 0.003, 1))
+}
+
+func TestLevels_SupportResistance(t *testing.T) {
+	levels := Levels{
+		Pivots: PivotPoints{P: 102},
+		Zones:  []Zone{{Level: 100.1}, {Level: 105.15}},
+	}
+	tests := []struct {
+		name           string
+		price          float64
+		wantSupport    float64
+		wantSupportOk  bool
+		wantResistance float64
+		wantResistOk   bool
+	}{
+		{name: "between levels", price: 103, wantSupport: 102, wantSupportOk: true, wantResistance: 105.15, wantResistOk: true},
+		{name: "below levels", price: 99, wantResistance: 100.1, wantResistOk: true},
+		{name: "above levels", price: 106, wantSupport: 105.15, wantSupportOk: true},
+		{name: "on the level", price: 102, wantSupport: 100.1, wantSupportOk: true, wantResistance: 105.15, wantResistOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			support, ok := levels.Support(tt.price)
+			require.Equal(t, tt.wantSupportOk, ok)
+			require.Equal(t, tt.wantSupport, support)
+			resistance, ok := levels.Resistance(tt.price)
+			require.Equal(t, tt.wantResistOk, ok)
+			require.Equal(t, tt.wantResistance, resistance)
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package trademath
 
 import (
+	"fmt"
 	"math"
+	"strings"
 
 	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
 )
 
 type MAIndication uint16
@@ -14,6 +18,41 @@ const (
 	WMAIndication
 )
 
+// maTypes moving average types available in config
+var maTypes = map[string]talib.MaType{
+	"SMA":  talib.SMA,
+	"EMA":  talib.EMA,
+	"WMA":  talib.WMA,
+	"DEMA": talib.DEMA,
+	"TEMA": talib.TEMA,
+	"KAMA": talib.KAMA,
+}
+
+// ToMAType returns moving average type by name: SMA, EMA, WMA, DEMA, TEMA or KAMA
+func ToMAType(name string) (talib.MaType, error) {
+	maType, ok := maTypes[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown moving average type: %s", name)
+	}
+	return maType, nil
+}
+
+// MALookback returns count of values before the first value of the moving average
+func MALookback(maType talib.MaType, period int) int {
+	switch {
+	case period <= 1:
+		return 0
+	case maType == talib.DEMA:
+		return 2 * (period - 1)
+	case maType == talib.TEMA:
+		return 3 * (period - 1)
+	case maType == talib.KAMA:
+		return period
+	default:
+		return period - 1
+	}
+}
+
 type Signals struct {
 	SMA float64  // sma with period
 	WMA float64  // wma with period
@@ -59,6 +98,27 @@ func (c *Calc) CalcMACD(
 	}
 }
 
+// CalcMACDSeries returns macd of every value, macd of values of the lookback period is empty
+func (c *Calc) CalcMACDSeries(
+	values []float64,
+	inFastPeriod int, inFastMAType talib.MaType,
+	inSlowPeriod int, inSlowMAType talib.MaType,
+	inSignalPeriod int, inSignalMAType talib.MaType,
+) []MACD {
+	macd, macdSig, macdHist := talib.MacdExt(values, inFastPeriod, inFastMAType, inSlowPeriod, inSlowMAType,
+		inSignalPeriod, inSignalMAType)
+
+	var result = make([]MACD, 0, len(macdHist))
+	for i := range macdHist {
+		result = append(result, MACD{
+			HistogramValue: RoundFloat(macdHist[i], 3),
+			Value:          RoundFloat(macd[i], 3),
+			Sig:            RoundFloat(macdSig[i], 3),
+		})
+	}
+	return result
+}
+
 // CalculateMACD - calculate macd, indication - use only EMA or WMA.
 // recommendation: count for values: fast=12, slow=26, prevMACDValues=8
 // Deprecated
@@ -164,8 +224,15 @@ func (c *Calc) CalculateSignals(values []float64) Signals {
 	}
 }
 
-func (c *Calc) CalcBB(values []float64, maType talib.MaType) (tlV, mlV, blV float64) {
-	tl, ml, bl := talib.BBands(values, len(values), 2, 2, maType)
+// CalcBB bollinger band of the last value, deviation is multiplier of the standard deviation.
+// Zero values are returned if there are not enough values for the moving average
+func (c *Calc) CalcBB(
+	values []float64, period int, deviation float64, maType talib.MaType,
+) (tlV, mlV, blV float64) {
+	if period <= 0 || len(values) < period || len(values) <= MALookback(maType, period) {
+		return 0, 0, 0
+	}
+	tl, ml, bl := talib.BBands(values, period, deviation, deviation, maType)
 	if len(tl) == 0 || len(ml) == 0 || len(bl) == 0 {
 		return 0, 0, 0
 	}
@@ -283,7 +350,7 @@ func RoundFloat(x float64, prec int) float64 {
 	return rounder / pow
 }
 
-const SatoshisPerBTC = 100000000
+const SatoshisPerBTC = money.SatoshisPerBTC
 
 func RemoveEmptyValues(indexes []float64, vals []float64) ([]float64, []float64) {
 	var indxesResult = make([]float64, 0)
@@ -299,15 +366,18 @@ func RemoveEmptyValues(indexes []float64, vals []float64) ([]float64, []float64)
 	return indxesResult, result
 }
 
+// ConvertToBTC converts satoshis to bitcoins
 func ConvertToBTC(b int64) float64 {
-	return float64(b) / SatoshisPerBTC
+	return money.Satoshi(b).Float64()
 }
 
-func ConvertFromBTCToContracts(balance float64) float64 {
-	return balance * SatoshisPerBTC
+// CalculateUnrealizedPNL unrealized pnl in bitcoins of XBTUSD position, see UnrealizedPNL
+func CalculateUnrealizedPNL(openPrice, lastPrice float64, contractsCount int64) float64 {
+	return UnrealizedPNL(money.FromFloat(openPrice), money.FromFloat(lastPrice), contractsCount).Float64()
 }
 
-func CalculateUnrealizedPNL(openPrice, lastPrice float64, contractsCount int64) float64 {
-	result := (1/openPrice - 1/lastPrice) * float64(contractsCount)
-	return RoundFloat(result, 8)
+// UnrealizedPNL unrealized pnl of XBTUSD position (1 / open price - 1 / last price) * contracts,
+// use Contract.PNL for other contracts
+func UnrealizedPNL(openPrice, lastPrice money.Decimal, contractsCount int64) money.Satoshi {
+	return XBTUSD.PNL(openPrice, lastPrice, contractsCount)
 }
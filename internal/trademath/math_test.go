@@ -8,6 +8,9 @@ import (
 	"github.com/markcheno/go-talib"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath/money"
 )
 
 func TestCalc_CalculateSignals(t *testing.T) {
@@ -272,6 +275,20 @@ func TestCalc_CalculateMACD(t *testing.T) {
 	}
 }
 
+func TestCalc_CalcMACDSeries(t *testing.T) {
+	var values []float64
+	for i := 0; i < 60; i++ {
+		values = append(values, 1100+float64(i%7)*3-float64(i%5)*2)
+	}
+	c := &Calc{}
+	got := c.CalcMACDSeries(values, 12, talib.EMA, 26, talib.EMA, 9, talib.WMA)
+	require.Len(t, got, len(values))
+	require.Equal(t, MACD{}, got[0])
+	for i := 40; i <= len(values); i++ {
+		require.Equal(t, c.CalcMACD(values[:i], 12, talib.EMA, 26, talib.EMA, 9, talib.WMA), got[i-1])
+	}
+}
+
 func TestCalculateUnrealizedPNL(t *testing.T) {
 	type args struct {
 		openPrice      float64
@@ -290,7 +307,7 @@ func TestCalculateUnrealizedPNL(t *testing.T) {
 				lastPrice:      9000,
 				contractcCount: 100,
 			},
-			want: 0.0013889,
+			want: 0.00138889,
 		},
 		{
 			name: "long - loss",
@@ -299,7 +316,7 @@ func TestCalculateUnrealizedPNL(t *testing.T) {
 				lastPrice:      8000,
 				contractcCount: 100,
 			},
-			want: -0.0013889,
+			want: -0.00138889,
 		},
 		{
 			name: "short - profit",
@@ -308,7 +325,7 @@ func TestCalculateUnrealizedPNL(t *testing.T) {
 				lastPrice:      8000,
 				contractcCount: -100,
 			},
-			want: 0.0013889,
+			want: 0.00138889,
 		},
 		{
 			name: "short - loss",
@@ -317,7 +334,7 @@ func TestCalculateUnrealizedPNL(t *testing.T) {
 				lastPrice:      9000,
 				contractcCount: -100,
 			},
-			want: -0.0013889,
+			want: -0.00138889,
 		},
 	}
 	for _, tt := range tests {
@@ -330,3 +347,124 @@ func TestCalculateUnrealizedPNL(t *testing.T) {
 		})
 	}
 }
+
+func TestUnrealizedPNL(t *testing.T) {
+	tests := []struct {
+		name      string
+		openPrice money.Decimal
+		lastPrice money.Decimal
+		contracts int64
+		want      money.Satoshi
+	}{
+		{
+			name:      "long - profit",
+			openPrice: money.FromInt(8000),
+			lastPrice: money.FromInt(9000),
+			contracts: 100,
+			want:      138889,
+		},
+		{
+			name:      "short - loss, half tick prices",
+			openPrice: money.New(80005, -1),
+			lastPrice: money.New(90005, -1),
+			contracts: -3,
+			want:      -4166,
+		},
+		{
+			name:      "rounded to satoshis away from zero",
+			openPrice: money.FromInt(3),
+			lastPrice: money.FromInt(6),
+			contracts: -1,
+			want:      -16666667,
+		},
+		{
+			name:      "empty price",
+			lastPrice: money.FromInt(9000),
+			contracts: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, UnrealizedPNL(tt.openPrice, tt.lastPrice, tt.contracts))
+		})
+	}
+}
+
+func TestToMAType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    talib.MaType
+		wantErr bool
+	}{
+		{name: "SMA", want: talib.SMA},
+		{name: "ema", want: talib.EMA},
+		{name: "Kama", want: talib.KAMA},
+		{name: "T3", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToMAType(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCalc_CalcBB(t *testing.T) {
+	values := []float64{10, 12, 11, 13, 15, 14, 16, 18, 17, 19}
+	tests := []struct {
+		name      string
+		values    []float64
+		period    int
+		deviation float64
+		maType    talib.MaType
+		want      BB
+	}{
+		{
+			name:      "sma",
+			values:    values,
+			period:    5,
+			deviation: 2,
+			maType:    talib.SMA,
+			want:      BB{TL: 20.2409, ML: 16.8, BL: 13.3591},
+		},
+		{
+			name:      "sma deviation 1",
+			values:    values,
+			period:    5,
+			deviation: 1,
+			maType:    talib.SMA,
+			want:      BB{TL: 18.5205, ML: 16.8, BL: 15.0795},
+		},
+		{
+			name:      "dema not enough values",
+			values:    values[:8],
+			period:    5,
+			deviation: 2,
+			maType:    talib.DEMA,
+		},
+		{
+			name:      "not enough values",
+			values:    values[:4],
+			period:    5,
+			deviation: 2,
+			maType:    talib.SMA,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Calc{}
+			tl, ml, bl := c.CalcBB(tt.values, tt.period, tt.deviation, tt.maType)
+			assert.InDelta(t, tt.want.TL, tl, 0.0002)
+			assert.InDelta(t, tt.want.ML, ml, 0.0002)
+			assert.InDelta(t, tt.want.BL, bl, 0.0002)
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale count of decimal places kept by Decimal, it is enough for satoshis and bitmex tick sizes
+const Scale = 8
+
+const unitsPerOne = 100000000 // 10^Scale
+
+var (
+	maxUnits = big.NewInt(math.MaxInt64)
+	minUnits = big.NewInt(-math.MaxInt64)
+)
+
+var (
+	Zero = Decimal{}
+	One  = FromInt(1)
+	// Max and Min limits of the value, results out of the range are saturated to them
+	Max = Decimal{units: math.MaxInt64}
+	Min = Decimal{units: -math.MaxInt64}
+)
+
+// Decimal fixed-point number with Scale decimal places for prices, quantities and bitcoin amounts,
+// it is stored as count of 10^-Scale units, so values are limited by ±92233720368 and results out of
+// the range are saturated to Max or Min instead of wrapping.
+// Addition and subtraction are exact, multiplication and division are rounded half away from zero
+type Decimal struct {
+	units int64
+}
+
+// New returns value * 10^exp, value is rounded half away from zero if exp is less than -Scale
+func New(value int64, exp int) Decimal {
+	switch {
+	case exp > 18-Scale:
+		return saturated(sign(value))
+	case exp >= -Scale:
+		return Decimal{units: saturate(new(big.Int).Mul(big.NewInt(value), big.NewInt(pow10(exp+Scale))))}
+	case exp < -Scale-18:
+		return Zero
+	default:
+		return Decimal{units: quoRound(big.NewInt(value), big.NewInt(pow10(-exp-Scale)))}
+	}
+}
+
+// FromInt returns integer value, it is saturated to Max or Min if the value is out of the range
+func FromInt(value int64) Decimal {
+	if value > math.MaxInt64/unitsPerOne || value < -math.MaxInt64/unitsPerOne {
+		return saturated(sign(value))
+	}
+	return Decimal{units: value * unitsPerOne}
+}
+
+// FromFloat returns value rounded to Scale decimal places, it is the nearest decimal of the float
+// and not the float binary value, so FromFloat(0.1) is exactly 0.1.
+// Value out of the range is saturated to Max or Min, NaN is zero
+func FromFloat(value float64) Decimal {
+	if math.IsNaN(value) {
+		return Zero
+	}
+	d, err := Parse(strconv.FormatFloat(value, 'f', Scale, 64))
+	if err != nil {
+		switch {
+		case value > 0:
+			return Max
+		case value < 0:
+			return Min
+		}
+		return Zero
+	}
+	return d
+}
+
+// Parse parses decimal string like -123.45, digits after Scale decimal places are rounded half away from zero
+func Parse(s string) (Decimal, error) {
+	var (
+		value     = strings.TrimSpace(s)
+		intPart   = value
+		fracPart  string
+		fracRound bool
+	)
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		intPart, fracPart = value[:i], value[i+1:]
+	}
+	negative := strings.HasPrefix(intPart, "-")
+	if negative || strings.HasPrefix(intPart, "+") {
+		intPart = intPart[1:]
+	}
+	if (intPart == "" && fracPart == "") || strings.IndexFunc(intPart+fracPart, notDigit) >= 0 {
+		return Zero, fmt.Errorf("invalid decimal: %q", s)
+	}
+	if len(fracPart) > Scale {
+		fracRound = fracPart[Scale] >= '5'
+		fracPart = fracPart[:Scale]
+	}
+	fracPart += strings.Repeat("0", Scale-len(fracPart))
+
+	units, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Zero, fmt.Errorf("invalid decimal: %q", s)
+	}
+	if fracRound {
+		units.Add(units, big.NewInt(1))
+	}
+	if !units.IsInt64() {
+		return Zero, fmt.Errorf("decimal %q is out of range", s)
+	}
+	result := units.Int64()
+	if negative {
+		result = -result
+	}
+	return Decimal{units: result}, nil
+}
+
+// Units returns count of 10^-Scale units
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// Add returns d + e saturated to Max or Min
+func (d Decimal) Add(e Decimal) Decimal {
+	units := d.units + e.units
+	switch {
+	case d.units > 0 && e.units > 0 && units < 0:
+		return Max
+	case d.units < 0 && e.units < 0 && units >= 0, units == math.MinInt64:
+		return Min
+	}
+	return Decimal{units: units}
+}
+
+// Sub returns d - e saturated to Max or Min
+func (d Decimal) Sub(e Decimal) Decimal {
+	return d.Add(e.Neg())
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units}
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Sign returns -1, 0 or 1
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp returns -1 if d < e, 0 if d == e and 1 if d > e
+func (d Decimal) Cmp(e Decimal) int {
+	return d.Sub(e).Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Mul returns d * e rounded to Scale decimal places and saturated to Max or Min
+func (d Decimal) Mul(e Decimal) Decimal {
+	num := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(e.units))
+	return Decimal{units: quoRound(num, big.NewInt(unitsPerOne))}
+}
+
+// Quo returns d / e rounded to Scale decimal places and saturated to Max or Min, division by zero panics
+func (d Decimal) Quo(e Decimal) Decimal {
+	if e.IsZero() {
+		panic("money: division by zero")
+	}
+	num := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(unitsPerOne))
+	return Decimal{units: quoRound(num, big.NewInt(e.units))}
+}
+
+// RoundStep returns the nearest multiple of the step, for example tick size or lot size,
+// the half is rounded away from zero. Not positive step returns the value
+func (d Decimal) RoundStep(step Decimal) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	steps := quoRound(big.NewInt(d.units), big.NewInt(step.units))
+	return Decimal{units: saturate(new(big.Int).Mul(big.NewInt(steps), big.NewInt(step.units)))}
+}
+
+// TruncStep returns multiple of the step rounded toward zero. Not positive step returns the value
+func (d Decimal) TruncStep(step Decimal) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	return Decimal{units: d.units / step.units * step.units}
+}
+
+// Int64 returns integer part of the value
+func (d Decimal) Int64() int64 {
+	return d.units / unitsPerOne
+}
+
+// Float64 returns the nearest float of the value
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the value without trailing zeros, for example -123.45
+func (d Decimal) String() string {
+	abs := uint64(d.units)
+	if d.units < 0 {
+		abs = uint64(-d.units)
+	}
+	s := fmt.Sprintf("%d.%0*d", abs/unitsPerOne, Scale, abs%unitsPerOne)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if d.units < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// MarshalJSON returns the value as json number
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON parses json number or string
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s json.Number
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	result, err := Parse(s.String())
+	if err != nil {
+		return err
+	}
+	*d = result
+	return nil
+}
+
+// Scan scans decimal from numeric or text column, float is rounded to Scale decimal places
+func (d *Decimal) Scan(value interface{}) error {
+	var (
+		result Decimal
+		err    error
+	)
+	switch v := value.(type) {
+	case nil:
+	case int64:
+		result = FromInt(v)
+	case float64:
+		result = FromFloat(v)
+	case []byte:
+		result, err = Parse(string(v))
+	case string:
+		result, err = Parse(v)
+	default:
+		err = errors.New("decimal type assertion failed")
+	}
+	if err != nil {
+		return err
+	}
+	*d = result
+	return nil
+}
+
+// Value returns decimal string, numeric columns keep it as float which is scanned back to the same decimal
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func notDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+func pow10(exp int) int64 {
+	result := int64(1)
+	for i := 0; i < exp; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// RoundRat returns the rational number rounded half away from zero and saturated to ±math.MaxInt64
+func RoundRat(r *big.Rat) int64 {
+	return quoRound(r.Num(), r.Denom())
+}
+
+// quoRound returns num / den rounded half away from zero and saturated to ±math.MaxInt64
+func quoRound(num, den *big.Int) int64 {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	twiceRem := new(big.Int).Abs(new(big.Int).Mul(rem, big.NewInt(2)))
+	if rem.Sign() != 0 && twiceRem.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return saturate(quo)
+}
+
+// saturate returns units limited by ±math.MaxInt64
+func saturate(units *big.Int) int64 {
+	switch {
+	case units.Cmp(maxUnits) > 0:
+		return math.MaxInt64
+	case units.Cmp(minUnits) < 0:
+		return -math.MaxInt64
+	default:
+		return units.Int64()
+	}
+}
+
+// saturated returns Max for positive sign, Min for negative sign and zero otherwise
+func saturated(sign int) Decimal {
+	switch {
+	case sign > 0:
+		return Max
+	case sign < 0:
+		return Min
+	default:
+		return Zero
+	}
+}
+
+func sign(value int64) int {
+	switch {
+	case value < 0:
+		return -1
+	case value > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,174 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "0", want: "0"},
+		{value: "123.45", want: "123.45"},
+		{value: "-0.00000001", want: "-0.00000001"},
+		{value: "+.5", want: "0.5"},
+		{value: "7.", want: "7"},
+		{value: "1.000000005", want: "1.00000001"},
+		{value: "-1.000000004", want: "-1"},
+		{value: "92233720368.54775807", want: "92233720368.54775807"},
+		{value: "92233720368.54775808", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "-", wantErr: true},
+		{value: "1e3", wantErr: true},
+		{value: "--1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	require.Equal(t, New(1, -1), FromFloat(0.1))
+	require.Equal(t, FromFloat(0.3), FromFloat(0.1).Add(FromFloat(0.2)))
+	require.Equal(t, "9123.5", FromFloat(9123.5).String())
+	require.Equal(t, "0.00000001", FromFloat(0.000000005).String())
+	require.Equal(t, Max, FromFloat(1e20))
+	require.Equal(t, Min, FromFloat(math.Inf(-1)))
+	require.Equal(t, Zero, FromFloat(math.NaN()))
+}
+
+func TestDecimal_Saturation(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Decimal
+		want Decimal
+	}{
+		{name: "from int", got: FromInt(100000000000), want: Max},
+		{name: "from negative int", got: FromInt(-100000000000), want: Min},
+		{name: "from int in range", got: FromInt(92233720368), want: New(92233720368, 0)},
+		{name: "new", got: New(1, 11), want: Max},
+		{name: "new big exponent", got: New(-1, 30), want: Min},
+		{name: "add", got: Max.Add(One), want: Max},
+		{name: "sub", got: Min.Sub(One), want: Min},
+		{name: "mul", got: FromInt(1000000).Mul(FromInt(1000000)), want: Max},
+		{name: "mul negative", got: FromInt(-1000000).Mul(FromInt(1000000)), want: Min},
+		{name: "quo", got: FromInt(1000000).Quo(New(1, -8)), want: Max},
+		{name: "round step", got: Max.RoundStep(FromInt(10)), want: Max},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	var (
+		a = New(10005, -1) // 1000.5
+		b = New(3, 0)
+	)
+	require.Equal(t, "1003.5", a.Add(b).String())
+	require.Equal(t, "997.5", a.Sub(b).String())
+	require.Equal(t, "3001.5", a.Mul(b).String())
+	require.Equal(t, "333.5", a.Quo(b).String())
+	require.Equal(t, "0.33333333", One.Quo(b).String())
+	require.Equal(t, "-0.66666667", FromInt(-2).Quo(b).String())
+	require.Equal(t, "0.00000001", New(1, -8).Mul(New(5, -1)).String())
+	require.Equal(t, -1, a.Neg().Sign())
+	require.Equal(t, a, a.Neg().Abs())
+	require.Equal(t, 1, a.Cmp(b))
+	require.Equal(t, 0, b.Cmp(FromInt(3)))
+	require.Equal(t, int64(1000), a.Int64())
+	require.Equal(t, 1000.5, a.Float64())
+	require.Panics(t, func() { a.Quo(Zero) })
+}
+
+func TestDecimal_Step(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     Decimal
+		step      Decimal
+		wantRound string
+		wantTrunc string
+	}{
+		{name: "tick 0.5", value: FromFloat(9123.74), step: New(5, -1), wantRound: "9123.5", wantTrunc: "9123.5"},
+		{name: "tick half", value: FromFloat(9123.25), step: New(5, -1), wantRound: "9123.5", wantTrunc: "9123"},
+		{name: "negative half", value: FromFloat(-2.5), step: One, wantRound: "-3", wantTrunc: "-2"},
+		{name: "lot 100", value: FromInt(249), step: FromInt(100), wantRound: "200", wantTrunc: "200"},
+		{name: "small tick", value: FromFloat(0.0312346), step: New(1, -5), wantRound: "0.03123", wantTrunc: "0.03123"},
+		{name: "empty step", value: FromFloat(1.25), wantRound: "1.25", wantTrunc: "1.25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantRound, tt.value.RoundStep(tt.step).String())
+			require.Equal(t, tt.wantTrunc, tt.value.TruncStep(tt.step).String())
+		})
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var got struct {
+		Price Decimal `json:"price"`
+		Qty   Decimal `json:"qty"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"price": 9123.5, "qty": "0.1"}`), &got))
+	require.Equal(t, FromFloat(9123.5), got.Price)
+	require.Equal(t, New(1, -1), got.Qty)
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"price": 9123.5, "qty": 0.1}`, string(data))
+}
+
+func TestDecimal_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    Decimal
+		wantErr bool
+	}{
+		{name: "nil"},
+		{name: "float", value: float64(float32(0.1)), want: New(1, -1)},
+		{name: "int", value: int64(5), want: FromInt(5)},
+		{name: "text", value: []byte("0.00012345"), want: New(12345, -8)},
+		{name: "string", value: "-1.5", want: New(-15, -1)},
+		{name: "invalid text", value: "abc", wantErr: true},
+		{name: "invalid type", value: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Decimal
+			err := got.Scan(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSatoshi(t *testing.T) {
+	require.Equal(t, Satoshi(12345), FromBTCFloat(0.00012345))
+	require.Equal(t, Satoshi(-1), FromBTCFloat(-0.000000005))
+	require.Equal(t, "0.00012345", Satoshi(12345).String())
+	require.Equal(t, 0.00012345, Satoshi(12345).Float64())
+	require.Equal(t, Satoshi(5), Satoshi(-5).Abs())
+	require.Equal(t, Satoshi(150000000), FromBTC(FromFloat(1.5)))
+	require.Equal(t, Satoshi(2100000000000000), FromBTC(FromInt(21000000)), "more than decimal range of satoshis")
+}
@@ -0,0 +1,39 @@
+package money
+
+const SatoshisPerBTC = 100000000
+
+// Satoshi bitcoin amount in satoshis (XBt), bitmex balances and pnl are returned in satoshis
+type Satoshi int64
+
+// FromBTCFloat returns satoshis of the bitcoin amount rounded half away from zero
+func FromBTCFloat(btc float64) Satoshi {
+	return FromBTC(FromFloat(btc))
+}
+
+// FromBTC returns satoshis of the bitcoin amount, they are exactly units of the decimal with 8 decimal places,
+// so the amount is not limited by the decimal range of satoshis
+func FromBTC(btc Decimal) Satoshi {
+	return Satoshi(btc.Units())
+}
+
+// BTC returns the amount in bitcoins
+func (s Satoshi) BTC() Decimal {
+	return New(int64(s), -8)
+}
+
+// Float64 returns the amount in bitcoins as float
+func (s Satoshi) Float64() float64 {
+	return s.BTC().Float64()
+}
+
+// String returns the amount in bitcoins, for example 0.00012345
+func (s Satoshi) String() string {
+	return s.BTC().String()
+}
+
+func (s Satoshi) Abs() Satoshi {
+	if s < 0 {
+		return -s
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package stream
+
+import (
+	"fmt"
+	"math"
+)
+
+// ATRState state of the atr
+type ATRState struct {
+	Period    int     `json:"period"`
+	Count     int     `json:"count"`
+	PrevClose float64 `json:"prev_close"`
+	Sum       float64 `json:"sum"`
+	Value     float64 `json:"value"`
+}
+
+// ATR average true range with Wilder smoothing as talib.Atr
+type ATR struct {
+	state ATRState
+}
+
+// NewATR returns atr, not positive period gives zero values as talib does
+func NewATR(period int) *ATR {
+	return &ATR{state: ATRState{Period: period}}
+}
+
+// Update adds candle prices and returns atr, zero until period true ranges are added
+func (a *ATR) Update(high, low, closePrice float64) float64 {
+	s := &a.state
+	s.Count++
+	prevClose := s.PrevClose
+	s.PrevClose = closePrice
+	if s.Count == 1 || s.Period < 1 {
+		return s.Value
+	}
+	trueRange := math.Max(high-low, math.Max(math.Abs(prevClose-high), math.Abs(prevClose-low)))
+
+	switch {
+	case s.Period == 1:
+		s.Value = trueRange
+	case s.Count <= s.Period:
+		s.Sum += trueRange
+	case s.Count == s.Period+1:
+		s.Sum += trueRange
+		s.Value = s.Sum / float64(s.Period)
+	default:
+		s.Value *= float64(s.Period) - 1.0
+		s.Value += trueRange
+		s.Value /= float64(s.Period)
+	}
+	return s.Value
+}
+
+// Value returns the last atr
+func (a *ATR) Value() float64 {
+	return a.state.Value
+}
+
+// Ready returns true if period true ranges are added
+func (a *ATR) Ready() bool {
+	return a.state.Period >= 1 && a.state.Count > a.state.Period
+}
+
+func (a *ATR) Snapshot() ATRState {
+	return a.state
+}
+
+// Restore loads state with the same period
+func (a *ATR) Restore(state ATRState) error {
+	if state.Period != a.state.Period {
+		return fmt.Errorf("%w: atr(%d)", ErrStateMismatch, a.state.Period)
+	}
+	a.state = state
+	return nil
+}
@@ -0,0 +1,94 @@
+package stream
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+)
+
+// BBState state of the bollinger band
+type BBState struct {
+	Period    int          `json:"period"`
+	Deviation float64      `json:"deviation"`
+	MA        MAState      `json:"ma"`
+	Window    []float64    `json:"window,omitempty"`
+	Sum       float64      `json:"sum"`
+	SquareSum float64      `json:"square_sum"`
+	Value     trademath.BB `json:"value"`
+}
+
+// BB rolling bollinger band as talib.BBands with the same up and down deviations
+type BB struct {
+	ma    *MA
+	state BBState
+}
+
+func NewBB(period int, deviation float64, maType talib.MaType) (*BB, error) {
+	ma, err := NewMA(maType, period)
+	if err != nil {
+		return nil, err
+	}
+	return &BB{
+		ma:    ma,
+		state: BBState{Period: period, Deviation: deviation},
+	}, nil
+}
+
+// Update adds value and returns bollinger band, zero until period values are added
+func (b *BB) Update(value float64) trademath.BB {
+	s := &b.state
+	middle := b.ma.Update(value)
+
+	s.Window = push(s.Window, value, s.Period)
+	s.Sum += value
+	s.SquareSum += value * value
+	if len(s.Window) < s.Period {
+		return s.Value
+	}
+	mean := s.Sum / float64(s.Period)
+	squareMean := s.SquareSum / float64(s.Period)
+	s.Sum -= s.Window[0]
+	s.SquareSum -= s.Window[0] * s.Window[0]
+
+	var stdDev float64
+	if variance := squareMean - mean*mean; !(variance < 0.00000000000001) {
+		stdDev = math.Sqrt(variance)
+	}
+	offset := stdDev * s.Deviation
+	s.Value = trademath.BB{TL: middle + offset, ML: middle, BL: middle - offset}
+	return s.Value
+}
+
+// Value returns the last bollinger band
+func (b *BB) Value() trademath.BB {
+	return b.state.Value
+}
+
+// Ready returns true if period values are added
+func (b *BB) Ready() bool {
+	return b.ma.Ready()
+}
+
+func (b *BB) Snapshot() BBState {
+	state := b.state
+	state.MA = b.ma.Snapshot()
+	state.Window = append([]float64(nil), b.state.Window...)
+	return state
+}
+
+// Restore loads state with the same parameters
+func (b *BB) Restore(state BBState) error {
+	if state.Period != b.state.Period || state.Deviation != b.state.Deviation || len(state.Window) > state.Period {
+		return fmt.Errorf("%w: bb(%d, %v)", ErrStateMismatch, b.state.Period, b.state.Deviation)
+	}
+	err := b.ma.Restore(state.MA)
+	if err != nil {
+		return err
+	}
+	b.state = state
+	b.state.Window = append([]float64(nil), state.Window...)
+	return nil
+}
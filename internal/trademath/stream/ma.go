@@ -0,0 +1,141 @@
+// Package stream contains stateful indicators updated in O(1) per new value.
+// Indicators give the same results as the talib batch functions used by trademath.Calc
+// and their state can be saved by Snapshot and loaded by Restore.
+// Indicators are not safe for concurrent use
+package stream
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/markcheno/go-talib"
+)
+
+// ErrStateMismatch returned on restore of the state with other indicator parameters
+var ErrStateMismatch = errors.New("indicator state does not match parameters")
+
+// MAState state of the moving average
+type MAState struct {
+	Type     talib.MaType `json:"type"`
+	Period   int          `json:"period"`
+	Count    int          `json:"count"`
+	Window   []float64    `json:"window,omitempty"`
+	Sum      float64      `json:"sum"`
+	Weighted float64      `json:"weighted"`
+	Trailing float64      `json:"trailing"`
+	Value    float64      `json:"value"`
+}
+
+// MA moving average as talib.Ma, only SMA, EMA and WMA are supported.
+// EMA is seeded by SMA of the first period values
+type MA struct {
+	state MAState
+}
+
+// Supported returns true if moving average type is supported by streaming indicators
+func Supported(maType talib.MaType) bool {
+	return maType == talib.SMA || maType == talib.EMA || maType == talib.WMA
+}
+
+func NewMA(maType talib.MaType, period int) (*MA, error) {
+	if !Supported(maType) {
+		return nil, fmt.Errorf("not supported streaming moving average type: %v", maType)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("moving average period must be positive: %d", period)
+	}
+	return &MA{state: MAState{Type: maType, Period: period}}, nil
+}
+
+// NewEMA returns exponential moving average, period must be positive
+func NewEMA(period int) (*MA, error) {
+	return NewMA(talib.EMA, period)
+}
+
+// Update adds value and returns moving average, zero until period values are added
+func (m *MA) Update(value float64) float64 {
+	s := &m.state
+	s.Count++
+	if s.Period == 1 {
+		s.Value = value
+		return s.Value
+	}
+	if s.Type != talib.EMA {
+		s.Window = push(s.Window, value, s.Period)
+	}
+
+	switch s.Type {
+	case talib.SMA:
+		s.Sum += value
+		if s.Count >= s.Period {
+			s.Value = s.Sum / float64(s.Period)
+			s.Sum -= s.Window[0]
+		}
+	case talib.EMA:
+		switch {
+		case s.Count < s.Period:
+			s.Sum += value
+		case s.Count == s.Period:
+			s.Sum += value
+			s.Value = s.Sum / float64(s.Period)
+		default:
+			s.Value = ((value - s.Value) * (2.0 / float64(s.Period+1))) + s.Value
+		}
+	case talib.WMA:
+		if s.Count < s.Period {
+			s.Sum += value
+			s.Weighted += value * float64(s.Count)
+			break
+		}
+		s.Sum += value
+		s.Sum -= s.Trailing
+		s.Weighted += value * float64(s.Period)
+		s.Trailing = s.Window[0]
+		s.Value = s.Weighted / float64((s.Period*(s.Period+1))>>1)
+		s.Weighted -= s.Sum
+	}
+	return s.Value
+}
+
+// Value returns the last moving average
+func (m *MA) Value() float64 {
+	return m.state.Value
+}
+
+// Ready returns true if period values are added
+func (m *MA) Ready() bool {
+	return m.state.Count >= m.state.Period
+}
+
+func (m *MA) Snapshot() MAState {
+	state := m.state
+	state.Window = append([]float64(nil), m.state.Window...)
+	return state
+}
+
+// Restore loads state with the same type and period
+func (m *MA) Restore(state MAState) error {
+	err := m.check(state)
+	if err != nil {
+		return err
+	}
+	m.state = state
+	m.state.Window = append([]float64(nil), state.Window...)
+	return nil
+}
+
+func (m *MA) check(state MAState) error {
+	if state.Type != m.state.Type || state.Period != m.state.Period || len(state.Window) > state.Period {
+		return fmt.Errorf("%w: ma %v(%d)", ErrStateMismatch, m.state.Type, m.state.Period)
+	}
+	return nil
+}
+
+// push appends value to the window of the last size values
+func push(window []float64, value float64, size int) []float64 {
+	window = append(window, value)
+	if len(window) > size {
+		window = window[1:]
+	}
+	return window
+}
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+)
+
+// MACDState state of the macd
+type MACDState struct {
+	Count  int            `json:"count"`
+	Fast   MAState        `json:"fast"`
+	Slow   MAState        `json:"slow"`
+	Signal MAState        `json:"signal"`
+	Value  trademath.MACD `json:"value"`
+}
+
+// MACD moving average convergence divergence as talib.MacdExt.
+// As in talib, signal moving average is fed from the first value and
+// fast and slow moving averages are zero until they are ready
+type MACD struct {
+	fast, slow, signal *MA
+	lookback           int
+	state              MACDState
+}
+
+func NewMACD(
+	fastPeriod int, fastMAType talib.MaType,
+	slowPeriod int, slowMAType talib.MaType,
+	signalPeriod int, signalMAType talib.MaType,
+) (*MACD, error) {
+	fast, err := NewMA(fastMAType, fastPeriod)
+	if err != nil {
+		return nil, err
+	}
+	slow, err := NewMA(slowMAType, slowPeriod)
+	if err != nil {
+		return nil, err
+	}
+	signal, err := NewMA(signalMAType, signalPeriod)
+	if err != nil {
+		return nil, err
+	}
+	largest := slowPeriod
+	if fastPeriod > slowPeriod {
+		largest = fastPeriod
+	}
+	return &MACD{
+		fast:     fast,
+		slow:     slow,
+		signal:   signal,
+		lookback: signalPeriod - 1 + largest - 1,
+	}, nil
+}
+
+// Update adds value and returns macd, zero until all moving averages are ready
+func (m *MACD) Update(value float64) trademath.MACD {
+	m.state.Count++
+	macd := m.fast.Update(value) - m.slow.Update(value)
+	signal := m.signal.Update(macd)
+	if m.Ready() {
+		m.state.Value = trademath.MACD{
+			HistogramValue: macd - signal,
+			Value:          macd,
+			Sig:            signal,
+		}
+	}
+	return m.state.Value
+}
+
+// Value returns the last macd
+func (m *MACD) Value() trademath.MACD {
+	return m.state.Value
+}
+
+// Ready returns true if all moving averages are ready
+func (m *MACD) Ready() bool {
+	return m.state.Count > m.lookback
+}
+
+func (m *MACD) Snapshot() MACDState {
+	state := m.state
+	state.Fast = m.fast.Snapshot()
+	state.Slow = m.slow.Snapshot()
+	state.Signal = m.signal.Snapshot()
+	return state
+}
+
+// Restore loads state with the same moving averages
+func (m *MACD) Restore(state MACDState) error {
+	var mas = []struct {
+		ma    *MA
+		state MAState
+	}{{m.fast, state.Fast}, {m.slow, state.Slow}, {m.signal, state.Signal}}
+	for _, ma := range mas {
+		err := ma.ma.check(ma.state)
+		if err != nil {
+			return err
+		}
+	}
+	for _, ma := range mas {
+		_ = ma.ma.Restore(ma.state)
+	}
+	m.state = state
+	return nil
+}
@@ -0,0 +1,83 @@
+package stream
+
+import "fmt"
+
+// RSIState state of the rsi
+type RSIState struct {
+	Period int     `json:"period"`
+	Count  int     `json:"count"`
+	Prev   float64 `json:"prev"`
+	Gain   float64 `json:"gain"`
+	Loss   float64 `json:"loss"`
+	Value  float64 `json:"value"`
+}
+
+// RSI relative strength index with Wilder smoothing as talib.Rsi
+type RSI struct {
+	state RSIState
+}
+
+// NewRSI returns rsi, period less than 2 gives zero values as talib does
+func NewRSI(period int) *RSI {
+	return &RSI{state: RSIState{Period: period}}
+}
+
+// Update adds value and returns rsi, zero until period changes of values are added
+func (r *RSI) Update(value float64) float64 {
+	s := &r.state
+	s.Count++
+	if s.Count == 1 || s.Period < 2 {
+		s.Prev = value
+		return s.Value
+	}
+	change := value - s.Prev
+	s.Prev = value
+	period := float64(s.Period)
+
+	seed := s.Count <= s.Period+1
+	if !seed {
+		s.Loss *= period - 1
+		s.Gain *= period - 1
+	}
+	if change < 0 {
+		s.Loss -= change
+	} else {
+		s.Gain += change
+	}
+	if seed && s.Count < s.Period+1 {
+		return s.Value
+	}
+	s.Loss /= period
+	s.Gain /= period
+
+	total := s.Gain + s.Loss
+	if !((-0.00000000000001 < total) && (total < 0.00000000000001)) {
+		s.Value = 100.0 * (s.Gain / total)
+	} else {
+		s.Value = 0.0
+	}
+	return s.Value
+}
+
+// Value returns the last rsi
+func (r *RSI) Value() float64 {
+	return r.state.Value
+}
+
+// Ready returns true if period changes of values are added
+func (r *RSI) Ready() bool {
+	return r.state.Period >= 2 && r.state.Count > r.state.Period
+}
+
+func (r *RSI) Snapshot() RSIState {
+	return r.state
+}
+
+// Restore loads state with the same period
+func (r *RSI) Restore(state RSIState) error {
+	if state.Period != r.state.Period {
+		return fmt.Errorf("%w: rsi(%d)", ErrStateMismatch, r.state.Period)
+	}
+	r.state = state
+	return nil
+}
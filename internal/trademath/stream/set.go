@@ -0,0 +1,243 @@
+package stream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// Params periods of the set indicators, indicator with zero period is not calculated
+type Params struct {
+	EMAPeriod        int
+	RSIPeriod        int
+	BBPeriod         int
+	BBDeviation      float64
+	BBMAType         talib.MaType
+	MACDFastPeriod   int
+	MACDFastMAType   talib.MaType
+	MACDSlowPeriod   int
+	MACDSlowMAType   talib.MaType
+	MACDSignalPeriod int
+	MACDSignalMAType talib.MaType
+	ATRPeriod        int
+	// History count of the last values kept by the set
+	History int
+}
+
+// Values values of the set indicators after the candle, Count is count of candles added to the set,
+// value of the indicator which is not ready or not calculated is zero
+type Values struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Count     int            `json:"count"`
+	Close     float64        `json:"close"`
+	EMA       float64        `json:"ema"`
+	RSI       float64        `json:"rsi"`
+	BB        trademath.BB   `json:"bb"`
+	MACD      trademath.MACD `json:"macd"`
+	ATR       float64        `json:"atr"`
+}
+
+// SetState state of the set indicators, nil for not calculated indicator
+type SetState struct {
+	Timestamp time.Time  `json:"timestamp"`
+	EMA       *MAState   `json:"ema,omitempty"`
+	RSI       *RSIState  `json:"rsi,omitempty"`
+	BB        *BBState   `json:"bb,omitempty"`
+	MACD      *MACDState `json:"macd,omitempty"`
+	ATR       *ATRState  `json:"atr,omitempty"`
+	History   []Values   `json:"history,omitempty"`
+}
+
+// Set indicators of one symbol and bin size updated by closed candles, EMA, RSI, BB and MACD use closes
+type Set struct {
+	EMA  *MA
+	RSI  *RSI
+	BB   *BB
+	MACD *MACD
+	ATR  *ATR
+
+	params    Params
+	timestamp time.Time
+	history   []Values
+}
+
+func NewSet(params Params) (*Set, error) {
+	var (
+		set = &Set{params: params}
+		err error
+	)
+	if params.EMAPeriod != 0 {
+		set.EMA, err = NewEMA(params.EMAPeriod)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.RSIPeriod != 0 {
+		set.RSI = NewRSI(params.RSIPeriod)
+	}
+	if params.BBPeriod != 0 {
+		set.BB, err = NewBB(params.BBPeriod, params.BBDeviation, params.BBMAType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.MACDFastPeriod != 0 && params.MACDSlowPeriod != 0 && params.MACDSignalPeriod != 0 {
+		set.MACD, err = NewMACD(
+			params.MACDFastPeriod, params.MACDFastMAType,
+			params.MACDSlowPeriod, params.MACDSlowMAType,
+			params.MACDSignalPeriod, params.MACDSignalMAType,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.ATRPeriod != 0 {
+		set.ATR = NewATR(params.ATRPeriod)
+	}
+	return set, nil
+}
+
+// Update adds the closed candle to the indicators, false if the candle is not newer than the last added one
+func (s *Set) Update(candle bitmex.TradeBuck) (bool, error) {
+	ts, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candle.Timestamp)
+	if err != nil {
+		return false, err
+	}
+	if !ts.After(s.timestamp) {
+		return false, nil
+	}
+	s.timestamp = ts
+
+	var values = Values{Timestamp: ts, Count: 1, Close: candle.Close}
+	if len(s.history) != 0 {
+		values.Count = s.history[len(s.history)-1].Count + 1
+	}
+	if s.EMA != nil {
+		values.EMA = s.EMA.Update(candle.Close)
+	}
+	if s.RSI != nil {
+		values.RSI = s.RSI.Update(candle.Close)
+	}
+	if s.BB != nil {
+		values.BB = s.BB.Update(candle.Close)
+	}
+	if s.MACD != nil {
+		values.MACD = s.MACD.Update(candle.Close)
+	}
+	if s.ATR != nil {
+		values.ATR = s.ATR.Update(candle.High, candle.Low, candle.Close)
+	}
+	s.history = pushValues(s.history, values, s.historySize())
+	return true, nil
+}
+
+// Timestamp returns timestamp of the last added candle
+func (s *Set) Timestamp() time.Time {
+	return s.timestamp
+}
+
+// Params returns parameters of the set indicators
+func (s *Set) Params() Params {
+	return s.params
+}
+
+// History returns values of the last candles from old to new, not more than History of the params
+func (s *Set) History() []Values {
+	return append([]Values{}, s.history...)
+}
+
+// historySize returns count of the kept values, the last values are always kept
+func (s *Set) historySize() int {
+	if s.params.History < 1 {
+		return 1
+	}
+	return s.params.History
+}
+
+func (s *Set) Snapshot() SetState {
+	var state = SetState{Timestamp: s.timestamp, History: s.History()}
+	if s.EMA != nil {
+		ema := s.EMA.Snapshot()
+		state.EMA = &ema
+	}
+	if s.RSI != nil {
+		rsi := s.RSI.Snapshot()
+		state.RSI = &rsi
+	}
+	if s.BB != nil {
+		bb := s.BB.Snapshot()
+		state.BB = &bb
+	}
+	if s.MACD != nil {
+		macd := s.MACD.Snapshot()
+		state.MACD = &macd
+	}
+	if s.ATR != nil {
+		atr := s.ATR.Snapshot()
+		state.ATR = &atr
+	}
+	return state
+}
+
+// Restore loads state of the same indicators, the set is not changed on error
+func (s *Set) Restore(state SetState) error {
+	if (s.EMA == nil) != (state.EMA == nil) || (s.RSI == nil) != (state.RSI == nil) ||
+		(s.BB == nil) != (state.BB == nil) || (s.MACD == nil) != (state.MACD == nil) ||
+		(s.ATR == nil) != (state.ATR == nil) {
+		return fmt.Errorf("%w: set indicators", ErrStateMismatch)
+	}
+	restored, err := NewSet(s.params)
+	if err != nil {
+		return err
+	}
+	restored.timestamp = state.Timestamp
+	for _, values := range state.History {
+		restored.history = pushValues(restored.history, values, restored.historySize())
+	}
+	if restored.EMA != nil {
+		err = restored.EMA.Restore(*state.EMA)
+		if err != nil {
+			return err
+		}
+	}
+	if restored.RSI != nil {
+		err = restored.RSI.Restore(*state.RSI)
+		if err != nil {
+			return err
+		}
+	}
+	if restored.BB != nil {
+		err = restored.BB.Restore(*state.BB)
+		if err != nil {
+			return err
+		}
+	}
+	if restored.MACD != nil {
+		err = restored.MACD.Restore(*state.MACD)
+		if err != nil {
+			return err
+		}
+	}
+	if restored.ATR != nil {
+		err = restored.ATR.Restore(*state.ATR)
+		if err != nil {
+			return err
+		}
+	}
+	*s = *restored
+	return nil
+}
+
+// pushValues appends values to the history of the last size values
+func pushValues(history []Values, values Values, size int) []Values {
+	history = append(history, values)
+	if len(history) > size {
+		history = history[len(history)-size:]
+	}
+	return history
+}
@@ -0,0 +1,223 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/markcheno/go-talib"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/trademath"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// testCandles returns n candles of a noisy sine wave with 5m bins
+func testCandles(n int) []bitmex.TradeBuck {
+	var (
+		r       = rand.New(rand.NewSource(1))
+		start   = time.Date(2020, 5, 16, 10, 0, 0, 0, time.UTC)
+		candles = make([]bitmex.TradeBuck, 0, n)
+	)
+	for i := 0; i < n; i++ {
+		closePrice := 9000 + 300*math.Sin(float64(i)/15) + r.Float64()*50
+		candles = append(candles, bitmex.TradeBuck{
+			Symbol:    "XBTUSD",
+			Timestamp: start.Add(time.Duration(i) * 5 * time.Minute).Format(tradeapi.TradeBucketedTimestampLayout),
+			High:      closePrice + r.Float64()*20,
+			Low:       closePrice - r.Float64()*20,
+			Close:     closePrice,
+		})
+	}
+	return candles
+}
+
+func closes(candles []bitmex.TradeBuck) []float64 {
+	var result = make([]float64, 0, len(candles))
+	for _, candle := range candles {
+		result = append(result, candle.Close)
+	}
+	return result
+}
+
+func TestMA(t *testing.T) {
+	values := closes(testCandles(200))
+	tests := []struct {
+		maType talib.MaType
+		period int
+	}{
+		{talib.SMA, 1}, {talib.SMA, 20},
+		{talib.EMA, 1}, {talib.EMA, 12},
+		{talib.WMA, 1}, {talib.WMA, 9},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%d(%d)", tt.maType, tt.period), func(t *testing.T) {
+			ma, err := NewMA(tt.maType, tt.period)
+			require.NoError(t, err)
+			want := talib.Ma(values, tt.period, tt.maType)
+			for i, value := range values {
+				require.Equal(t, want[i], ma.Update(value), "value %d", i)
+				require.Equal(t, i >= tt.period-1, ma.Ready())
+			}
+		})
+	}
+
+	_, err := NewMA(talib.KAMA, 10)
+	require.Error(t, err)
+}
+
+func TestRSI(t *testing.T) {
+	values := closes(testCandles(200))
+	for _, period := range []int{1, 2, 14} {
+		rsi := NewRSI(period)
+		want := talib.Rsi(values, period)
+		for i, value := range values {
+			require.Equal(t, want[i], rsi.Update(value), "period %d value %d", period, i)
+		}
+	}
+}
+
+func TestBB(t *testing.T) {
+	values := closes(testCandles(200))
+	for _, maType := range []talib.MaType{talib.SMA, talib.EMA} {
+		bb, err := NewBB(20, 2, maType)
+		require.NoError(t, err)
+		tl, ml, bl := talib.BBands(values, 20, 2, 2, maType)
+		for i, value := range values {
+			require.Equal(t, trademath.BB{TL: tl[i], ML: ml[i], BL: bl[i]}, bb.Update(value), "%v value %d", maType, i)
+		}
+	}
+}
+
+func TestMACD(t *testing.T) {
+	values := closes(testCandles(200))
+	for _, signalMAType := range []talib.MaType{talib.WMA, talib.EMA} {
+		macd, err := NewMACD(12, talib.EMA, 26, talib.EMA, 9, signalMAType)
+		require.NoError(t, err)
+		value, signal, hist := talib.MacdExt(values, 12, talib.EMA, 26, talib.EMA, 9, signalMAType)
+		for i, v := range values {
+			require.Equal(t, trademath.MACD{HistogramValue: hist[i], Value: value[i], Sig: signal[i]}, macd.Update(v),
+				"%v value %d", signalMAType, i)
+		}
+	}
+}
+
+func TestATR(t *testing.T) {
+	candles := testCandles(200)
+	o := trademath.NewOHLCV(candles)
+	for _, period := range []int{1, 14} {
+		atr := NewATR(period)
+		want := talib.Atr(o.High, o.Low, o.Close, period)
+		for i, candle := range candles {
+			require.Equal(t, want[i], atr.Update(candle.High, candle.Low, candle.Close), "period %d value %d", period, i)
+		}
+	}
+}
+
+func testParams() Params {
+	return Params{
+		EMAPeriod:        20,
+		RSIPeriod:        14,
+		BBPeriod:         20,
+		BBDeviation:      2,
+		BBMAType:         talib.EMA,
+		MACDFastPeriod:   12,
+		MACDFastMAType:   talib.EMA,
+		MACDSlowPeriod:   26,
+		MACDSlowMAType:   talib.EMA,
+		MACDSignalPeriod: 9,
+		MACDSignalMAType: talib.WMA,
+		ATRPeriod:        14,
+	}
+}
+
+func TestSet_SnapshotRestore(t *testing.T) {
+	candles := testCandles(200)
+	set, err := NewSet(testParams())
+	require.NoError(t, err)
+	for _, candle := range candles[:120] {
+		updated, err := set.Update(candle)
+		require.NoError(t, err)
+		require.True(t, updated)
+	}
+	updated, err := set.Update(candles[100])
+	require.NoError(t, err)
+	require.False(t, updated, "old candle skipped")
+
+	data, err := json.Marshal(set.Snapshot())
+	require.NoError(t, err)
+	var state SetState
+	require.NoError(t, json.Unmarshal(data, &state))
+
+	restored, err := NewSet(testParams())
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(state))
+	require.Equal(t, set.Timestamp(), restored.Timestamp())
+
+	for _, candle := range candles[120:] {
+		_, err = set.Update(candle)
+		require.NoError(t, err)
+		_, err = restored.Update(candle)
+		require.NoError(t, err)
+	}
+	require.Equal(t, set.Snapshot(), restored.Snapshot())
+	require.Equal(t, talib.Rsi(closes(candles), 14)[199], restored.RSI.Value())
+}
+
+func TestSet_RestoreMismatch(t *testing.T) {
+	set, err := NewSet(testParams())
+	require.NoError(t, err)
+	state := set.Snapshot()
+
+	tests := []struct {
+		name   string
+		params func(params *Params)
+	}{
+		{name: "other period", params: func(params *Params) { params.RSIPeriod = 7 }},
+		{name: "other ma type", params: func(params *Params) { params.MACDSignalMAType = talib.EMA }},
+		{name: "not calculated indicator", params: func(params *Params) { params.ATRPeriod = 0 }},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams()
+			tt.params(&params)
+			other, err := NewSet(params)
+			require.NoError(t, err)
+			err = other.Restore(state)
+			require.True(t, errors.Is(err, ErrStateMismatch), err)
+		})
+	}
+}
+
+func TestSet_History(t *testing.T) {
+	candles := testCandles(60)
+	params := testParams()
+	params.History = 3
+	set, err := NewSet(params)
+	require.NoError(t, err)
+	for _, candle := range candles {
+		_, err = set.Update(candle)
+		require.NoError(t, err)
+	}
+
+	history := set.History()
+	require.Len(t, history, 3)
+	rsi := talib.Rsi(closes(candles), 14)
+	for i, values := range history {
+		require.Equal(t, 58+i, values.Count)
+		require.Equal(t, candles[57+i].Close, values.Close)
+		require.Equal(t, rsi[57+i], values.RSI)
+	}
+
+	restored, err := NewSet(params)
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(set.Snapshot()))
+	require.Equal(t, history, restored.History())
+}
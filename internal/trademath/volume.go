@@ -0,0 +1,167 @@
+package trademath
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/markcheno/go-talib"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+// sessionDuration vwap session, sessions start at 00:00 UTC
+const sessionDuration = 24 * time.Hour
+
+type VolumeProfile struct {
+	POC float64 // point of control, middle of the price row with the max volume
+	VAH float64 // value area high
+	VAL float64 // value area low
+}
+
+// CalcVWAP volume weighted average price of the candles, candle price is its vwap or typical price
+// (high + low + close) / 3 if vwap is empty
+func (c *Calc) CalcVWAP(candles []bitmex.TradeBuck) float64 {
+	var notional, volume float64
+	for _, candle := range candles {
+		price := candle.Vwap
+		if price == 0 {
+			price = (candle.High + candle.Low + candle.Close) / 3
+		}
+		notional += price * float64(candle.Volume)
+		volume += float64(candle.Volume)
+	}
+	if volume == 0 {
+		return 0
+	}
+	return RoundFloat(notional/volume, 4)
+}
+
+// CalcAnchoredVWAP vwap of the candles opened not before the anchor, candles timestamp is the close time
+func (c *Calc) CalcAnchoredVWAP(candles []bitmex.TradeBuck, anchor time.Time) (float64, error) {
+	for i, candle := range candles {
+		ts, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candle.Timestamp)
+		if err != nil {
+			return 0, err
+		}
+		if ts.After(anchor) {
+			return c.CalcVWAP(candles[i:]), nil
+		}
+	}
+	return 0, nil
+}
+
+// CalcSessionVWAP vwap of the day session of the last candle, candles must be ordered from old to new
+func (c *Calc) CalcSessionVWAP(candles []bitmex.TradeBuck) (float64, error) {
+	if len(candles) == 0 {
+		return 0, nil
+	}
+	last, err := time.Parse(tradeapi.TradeBucketedTimestampLayout, candles[len(candles)-1].Timestamp)
+	if err != nil {
+		return 0, err
+	}
+	// candle closed at 00:00 belongs to the previous session
+	return c.CalcAnchoredVWAP(candles, last.Add(-time.Nanosecond).Truncate(sessionDuration))
+}
+
+// CalcOBV on balance volume, the first candle volume is the initial value
+func (c *Calc) CalcOBV(o OHLCV) float64 {
+	if o.Len() == 0 {
+		return 0
+	}
+	return lastRounded(talib.Obv(o.Close, o.Volume))
+}
+
+// CalcRelativeVolume volume of the last candle relative to the average volume of period candles before it,
+// recommendation: period = 20
+func (c *Calc) CalcRelativeVolume(o OHLCV, period int) float64 {
+	if period <= 0 || o.Len() <= period {
+		return 0
+	}
+	var sum float64
+	for _, volume := range o.Volume[o.Len()-period-1 : o.Len()-1] {
+		sum += volume
+	}
+	if sum == 0 {
+		return 0
+	}
+	return RoundFloat(o.Volume[o.Len()-1]*float64(period)/sum, 4)
+}
+
+// CalcVolumeProfile volume profile by price rows of rowSize, volume of candle is distributed over its price range.
+// Value area is built from point of control by adding the row with greater volume above or below
+// until valueArea part of the volume is included, recommendation: value area = 0.7
+func (c *Calc) CalcVolumeProfile(o OHLCV, rowSize, valueArea float64) (VolumeProfile, error) {
+	if rowSize <= 0 || valueArea <= 0 || valueArea > 1 {
+		return VolumeProfile{}, errors.New("row size must be positive and value area in (0, 1]")
+	}
+	if o.Len() == 0 {
+		return VolumeProfile{}, nil
+	}
+	low, high := o.Low[0], o.High[0]
+	for i := range o.Close {
+		low, high = math.Min(low, o.Low[i]), math.Max(high, o.High[i])
+	}
+	base := math.Floor(low/rowSize) * rowSize
+	rows := volumeRows(o, base, rowSize, int(math.Floor((high-base)/rowSize))+1)
+
+	var total float64
+	poc := 0
+	for i, volume := range rows {
+		total += volume
+		if volume > rows[poc] {
+			poc = i
+		}
+	}
+	if total == 0 {
+		return VolumeProfile{}, nil
+	}
+
+	first, last, volume := poc, poc, rows[poc]
+	for volume < total*valueArea && (first > 0 || last < len(rows)-1) {
+		var below, above = -1.0, -1.0
+		if first > 0 {
+			below = rows[first-1]
+		}
+		if last < len(rows)-1 {
+			above = rows[last+1]
+		}
+		if above >= below {
+			last++
+			volume += above
+			continue
+		}
+		first--
+		volume += below
+	}
+	return VolumeProfile{
+		POC: RoundFloat(base+(float64(poc)+0.5)*rowSize, 4),
+		VAH: RoundFloat(base+float64(last+1)*rowSize, 4),
+		VAL: RoundFloat(base+float64(first)*rowSize, 4),
+	}, nil
+}
+
+// volumeRows returns volumes of count price rows starting from base price,
+// candle volume is distributed proportionally to overlap of the row and the candle range
+func volumeRows(o OHLCV, base, rowSize float64, count int) []float64 {
+	var rows = make([]float64, count)
+	row := func(price float64) int {
+		return int(math.Min(math.Floor((price-base)/rowSize), float64(count-1)))
+	}
+	for i := range o.Close {
+		low, high := o.Low[i], o.High[i]
+		if high == low {
+			rows[row(low)] += o.Volume[i]
+			continue
+		}
+		for r := row(low); r <= row(high); r++ {
+			rowLow, rowHigh := base+float64(r)*rowSize, base+float64(r+1)*rowSize
+			overlap := math.Min(high, rowHigh) - math.Max(low, rowLow)
+			if overlap > 0 {
+				rows[r] += o.Volume[i] * overlap / (high - low)
+			}
+		}
+	}
+	return rows
+}
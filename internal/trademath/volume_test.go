@@ -0,0 +1,138 @@
+package trademath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestCalc_CalcVWAP(t *testing.T) {
+	c := &Calc{}
+	require.Equal(t, float64(0), c.CalcVWAP(nil))
+	require.InDelta(t, 10.6, c.CalcVWAP([]bitmex.TradeBuck{
+		{Vwap: 10, Volume: 2},
+		{High: 12, Low: 9, Close: 12, Volume: 3},
+	}), delta)
+}
+
+func TestCalc_CalcSessionVWAP(t *testing.T) {
+	candles := []bitmex.TradeBuck{
+		{Timestamp: "2020-05-16T23:00:00.000Z", Vwap: 10, Volume: 1},
+		{Timestamp: "2020-05-17T00:00:00.000Z", Vwap: 20, Volume: 1},
+		{Timestamp: "2020-05-17T01:00:00.000Z", Vwap: 30, Volume: 3},
+	}
+	tests := []struct {
+		name    string
+		candles []bitmex.TradeBuck
+		want    float64
+	}{
+		{name: "empty"},
+		{name: "session started", candles: candles, want: 30},
+		{name: "candle closed at midnight", candles: candles[:2], want: 15},
+	}
+	c := &Calc{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CalcSessionVWAP(tt.candles)
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, got, delta)
+		})
+	}
+
+	got, err := c.CalcAnchoredVWAP(candles, time.Date(2020, 5, 16, 22, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.InDelta(t, 24, got, delta)
+
+	_, err = c.CalcSessionVWAP([]bitmex.TradeBuck{{Timestamp: "2020-05-17"}})
+	require.Error(t, err)
+}
+
+func TestCalc_CalcOBV(t *testing.T) {
+	c := &Calc{}
+	require.Equal(t, float64(0), c.CalcOBV(OHLCV{}))
+	require.InDelta(t, 50, c.CalcOBV(OHLCV{
+		Close:  []float64{1, 2, 1, 1, 3},
+		Volume: []float64{10, 20, 30, 40, 50},
+	}), delta)
+}
+
+func TestCalc_CalcRelativeVolume(t *testing.T) {
+	o := OHLCV{Volume: []float64{10, 20, 30, 40, 50}, Close: []float64{1, 1, 1, 1, 1}}
+	c := &Calc{}
+	require.InDelta(t, 1.6667, c.CalcRelativeVolume(o, 3), delta)
+	require.InDelta(t, 2, c.CalcRelativeVolume(o, 4), delta)
+	require.Equal(t, float64(0), c.CalcRelativeVolume(o, 5))
+	require.Equal(t, float64(0), c.CalcRelativeVolume(o, 0))
+}
+
+func TestCalc_CalcVolumeProfile(t *testing.T) {
+	o := OHLCV{
+		High:   []float64{12, 11, 14},
+		Low:    []float64{10, 11, 12},
+		Close:  []float64{11, 11, 13},
+		Volume: []float64{20, 30, 10},
+	}
+	tests := []struct {
+		name      string
+		o         OHLCV
+		rowSize   float64
+		valueArea float64
+		want      VolumeProfile
+		wantErr   bool
+	}{
+		{
+			name:      "value area below poc",
+			o:         o,
+			rowSize:   1,
+			valueArea: 0.7,
+			want:      VolumeProfile{POC: 11.5, VAH: 12, VAL: 10},
+		},
+		{
+			name:      "poc only",
+			o:         o,
+			rowSize:   1,
+			valueArea: 0.5,
+			want:      VolumeProfile{POC: 11.5, VAH: 12, VAL: 11},
+		},
+		{
+			name:      "wide rows",
+			o:         o,
+			rowSize:   2,
+			valueArea: 1,
+			want:      VolumeProfile{POC: 11, VAH: 14, VAL: 10},
+		},
+		{
+			name:      "empty",
+			rowSize:   1,
+			valueArea: 0.7,
+		},
+		{
+			name:      "invalid row size",
+			o:         o,
+			valueArea: 0.7,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid value area",
+			o:         o,
+			rowSize:   1,
+			valueArea: 1.5,
+			wantErr:   true,
+		},
+	}
+	c := &Calc{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CalcVolumeProfile(tt.o, tt.rowSize, tt.valueArea)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -39,6 +39,7 @@ const (
 	OrdFilled          OrdStatus = "Filled"
 	OrdPartiallyFilled OrdStatus = "PartiallyFilled"
 	OrdCanceled        OrdStatus = "Canceled"
+	OrdRejected        OrdStatus = "Rejected"
 )
 
 type PriceType string
@@ -58,15 +59,19 @@ const (
 type Theme string
 
 const (
-	Instrument Theme = "instrument"
-	Position   Theme = "position"
-	Trade      Theme = "trade"
-	Order      Theme = "order"
-	Margin     Theme = "margin"
-	TradeBin1m Theme = "tradeBin1m"
-	TradeBin5m Theme = "tradeBin5m"
-	TradeBin1h Theme = "tradeBin1h"
-	TradeBin1d Theme = "tradeBin1d"
+	Instrument  Theme = "instrument"
+	Position    Theme = "position"
+	Trade       Theme = "trade"
+	Order       Theme = "order"
+	Execution   Theme = "execution"
+	OrderBookL2 Theme = "orderBookL2"
+	Margin      Theme = "margin"
+	Wallet      Theme = "wallet"
+	TradeBin    Theme = "tradeBin" // prefix of trade bin themes, followed by bin size
+	TradeBin1m  Theme = "tradeBin1m"
+	TradeBin5m  Theme = "tradeBin5m"
+	TradeBin1h  Theme = "tradeBin1h"
+	TradeBin1d  Theme = "tradeBin1d"
 )
 
 type Operation string
@@ -18,12 +18,24 @@ func FromTime(now time.Time, binSize string, count int) (from time.Time, err err
 	case "5m":
 		duration = time.Duration(count) * (5 * time.Minute)
 		toTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+	case "15m":
+		duration = time.Duration(count) * (15 * time.Minute)
+		toTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+	case "30m":
+		duration = time.Duration(count) * (30 * time.Minute)
+		toTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
 	case "1h":
 		duration = time.Duration(count) * (1 * time.Hour)
 		toTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, time.UTC)
+	case "4h":
+		duration = time.Duration(count) * (4 * time.Hour)
+		toTime = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, time.UTC)
 	case "1d":
 		duration = time.Duration(count) * (24 * time.Hour)
 		toTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	case "1w":
+		duration = time.Duration(count) * (7 * 24 * time.Hour)
+		toTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 	default:
 		err = fmt.Errorf("unsupported bin size:%s", binSize)
 		return
@@ -72,6 +72,45 @@ func TestFromTime(t *testing.T) {
 			wantFrom: time.Date(2010, 10, 0, 0, 0, 0, 0, time.UTC),
 			wantErr:  false,
 		},
+		{
+			name: "15m",
+			args: args{
+				now:     time.Date(2010, 10, 10, 10, 0, 10, 10, time.UTC),
+				binSize: "15m",
+				count:   4,
+			},
+			wantFrom: time.Date(2010, 10, 10, 9, 0, 0, 0, time.UTC),
+			wantErr:  false,
+		},
+		{
+			name: "4h",
+			args: args{
+				now:     time.Date(2010, 10, 10, 10, 0, 10, 10, time.UTC),
+				binSize: "4h",
+				count:   3,
+			},
+			wantFrom: time.Date(2010, 10, 9, 22, 0, 0, 0, time.UTC),
+			wantErr:  false,
+		},
+		{
+			name: "1w",
+			args: args{
+				now:     time.Date(2010, 10, 10, 10, 0, 10, 10, time.UTC),
+				binSize: "1w",
+				count:   2,
+			},
+			wantFrom: time.Date(2010, 9, 26, 0, 0, 0, 0, time.UTC),
+			wantErr:  false,
+		},
+		{
+			name: "unknown",
+			args: args{
+				now:     time.Date(2010, 10, 10, 10, 0, 10, 10, time.UTC),
+				binSize: "2m",
+				count:   2,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -0,0 +1 @@
+DELETE FROM bin_size WHERE bin IN ('15m', '30m', '4h', '1w');
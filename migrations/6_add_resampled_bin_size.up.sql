@@ -0,0 +1 @@
+INSERT INTO bin_size(bin) VALUES ('15m'),('30m'),('4h'),('1w');
@@ -0,0 +1,20 @@
+CREATE TABLE candles(
+                        id              integer PRIMARY KEY AUTOINCREMENT,
+                        symbol          VARCHAR(80)  NOT NULL,
+                        bin             VARCHAR(100) NOT NULL DEFAULT ('') REFERENCES bin_size(bin),
+                        timestamp       timestamp    NOT NULL,
+                        open            float8,
+                        high            float8,
+                        low             float8,
+                        close           float8,
+                        trades          int,
+                        volume          bigint,
+                        vwap            float8,
+                        lastsize        int,
+                        turnover        bigint,
+                        homenotional    float8,
+                        foreignnotional float8,
+                        created_at      bigint NOT NULL,
+                        updated_at      bigint NOT NULL,
+                        UNIQUE (symbol, bin, timestamp)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE indicator_snapshots(
+                        id         integer PRIMARY KEY AUTOINCREMENT,
+                        symbol     VARCHAR(80)  NOT NULL,
+                        bin        VARCHAR(100) NOT NULL DEFAULT ('') REFERENCES bin_size(bin),
+                        timestamp  timestamp    NOT NULL,
+                        data       TEXT         NOT NULL,
+                        created_at bigint NOT NULL,
+                        updated_at bigint NOT NULL,
+                        UNIQUE (symbol, bin)
+);
@@ -0,0 +1,33 @@
+CREATE TABLE strategies_config_copy (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    bin        VARCHAR(100) NOT NULL DEFAULT ('') REFERENCES bin_size(bin),
+    enable_rsi_bb boolean not null,
+    retry_process_count integer not null,
+    get_candles_count integer not null,
+
+    trend_filter_enable boolean not null,
+    candles_filter_enable boolean not null,
+    max_filter_trend_count integer not null,
+    max_candles_filter_count integer not null,
+
+    bb_last_candles_count integer not null,
+
+    rsi_count integer not null,
+    rsi_min_border integer not null,
+    rsi_max_border integer not null,
+    rsi_trade_coef float4 not null,
+
+    macd_fast_count integer not null,
+    macd_slow_count integer not null,
+    macd_sig_count integer not null,
+
+    global_id INTEGER,
+    FOREIGN KEY(global_id) REFERENCES global_config(id)
+);
+INSERT INTO strategies_config_copy SELECT
+    id, bin, enable_rsi_bb, retry_process_count, get_candles_count, trend_filter_enable, candles_filter_enable,
+    max_filter_trend_count, max_candles_filter_count, bb_last_candles_count, rsi_count, rsi_min_border,
+    rsi_max_border, rsi_trade_coef, macd_fast_count, macd_slow_count, macd_sig_count, global_id
+FROM strategies_config;
+DROP TABLE strategies_config;
+ALTER TABLE strategies_config_copy RENAME TO strategies_config;
@@ -0,0 +1,6 @@
+ALTER TABLE strategies_config ADD COLUMN bb_period integer not null default 0;
+ALTER TABLE strategies_config ADD COLUMN bb_deviation float8 not null default 0;
+ALTER TABLE strategies_config ADD COLUMN bb_ma_type varchar(10) not null default '';
+ALTER TABLE strategies_config ADD COLUMN macd_fast_ma_type varchar(10) not null default '';
+ALTER TABLE strategies_config ADD COLUMN macd_slow_ma_type varchar(10) not null default '';
+ALTER TABLE strategies_config ADD COLUMN macd_sig_ma_type varchar(10) not null default '';
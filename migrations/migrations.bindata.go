@@ -1,6 +1,6 @@
 // Code generated by go-bindata. (@generated) DO NOT EDIT.
 
- //Package migrations generated by go-bindata.// sources:
+// Package migrations generated by go-bindata.// sources:
 // 1_create_bin_size.down.sql
 // 1_create_bin_size.up.sql
 // 2_create_signal_type.down.sql
@@ -11,6 +11,14 @@
 // 4_create_orders.up.sql
 // 5_create_config.down.sql
 // 5_create_config.up.sql
+// 6_add_resampled_bin_size.down.sql
+// 6_add_resampled_bin_size.up.sql
+// 7_create_candles.down.sql
+// 7_create_candles.up.sql
+// 8_create_indicator_snapshots.down.sql
+// 8_create_indicator_snapshots.up.sql
+// 9_add_strategies_config_ma_types.down.sql
+// 9_add_strategies_config_ma_types.up.sql
 package migrations
 
 import (
@@ -287,6 +295,166 @@ func _5_create_configUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __6_add_resampled_bin_sizeDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x71\xf5\x71\x0d\x71\x55\x70\x0b\xf2\xf7\x55\x48\xca\xcc\x8b\x2f\xce\xac\x4a\x55\x08\xf7\x70\x0d\x72\x05\x71\x15\x3c\xfd\x14\x34\xd4\x0d\x4d\x73\xd5\x75\x14\xd4\x8d\x0d\xc0\x94\x49\x06\x88\x34\x2c\x57\xd7\xb4\xe6\x02\x00\x6c\xa2\xbf\xdc\x3e\x00\x00\x00")
+
+func _6_add_resampled_bin_sizeDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__6_add_resampled_bin_sizeDownSql,
+		"6_add_resampled_bin_size.down.sql",
+	)
+}
+
+func _6_add_resampled_bin_sizeDownSql() (*asset, error) {
+	bytes, err := _6_add_resampled_bin_sizeDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "6_add_resampled_bin_size.down.sql", size: 62, mode: os.FileMode(436), modTime: time.Unix(1792400801, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __6_add_resampled_bin_sizeUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xf3\xf4\x0b\x76\x0d\x0a\x51\xf0\xf4\x0b\xf1\x57\x48\xca\xcc\x8b\x2f\xce\xac\x4a\xd5\x00\x32\x34\x15\xc2\x1c\x7d\x42\x5d\x83\x15\x34\xd4\x0d\x4d\x73\xd5\x35\x75\x34\xd4\x8d\x0d\x20\xb4\x49\x06\x98\x32\x2c\x57\xd7\xb4\xe6\x02\x00\x90\xc1\xbf\x81\x40\x00\x00\x00")
+
+func _6_add_resampled_bin_sizeUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__6_add_resampled_bin_sizeUpSql,
+		"6_add_resampled_bin_size.up.sql",
+	)
+}
+
+func _6_add_resampled_bin_sizeUpSql() (*asset, error) {
+	bytes, err := _6_add_resampled_bin_sizeUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "6_add_resampled_bin_size.up.sql", size: 64, mode: os.FileMode(436), modTime: time.Unix(1792400801, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __7_create_candlesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\x4e\xcc\x4b\xc9\x49\x2d\xb6\xe6\x02\x00\xaa\x2a\x0f\xee\x1e\x00\x00\x00")
+
+func _7_create_candlesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__7_create_candlesDownSql,
+		"7_create_candles.down.sql",
+	)
+}
+
+func _7_create_candlesDownSql() (*asset, error) {
+	bytes, err := _7_create_candlesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "7_create_candles.down.sql", size: 30, mode: os.FileMode(436), modTime: time.Unix(1792401316, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __7_create_candlesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9d\x93\xc1\x6f\x82\x30\x18\xc5\xef\xfe\x15\xdf\x4d\x48\x38\xb8\x9b\xc9\x4e\x1d\xab\x19\x19\xe2\xd6\xc1\x12\x4f\xa6\xca\x27\x34\x29\x2d\xa1\x45\xb3\xfd\xf5\x83\x2c\x4e\x31\x63\xc2\x38\xf5\x25\xef\xd7\xbe\xf6\x7b\xf8\x8c\x92\x98\x42\x4c\x1e\x42\x0a\x3b\xae\x52\x89\xc6\x99\x40\xcf\x27\xd2\x2b\xad\x2c\x66\x58\xc1\x0b\x0b\x96\x84\xad\xe1\x99\xae\x81\x24\xf1\x2a\x88\x7c\x46\x97\x34\x8a\xbd\xde\xad\xcc\x47\xb1\xd5\xf2\xac\xdf\x09\xf3\x9f\x08\x73\xe6\x33\x17\x20\x5a\xc5\x10\x25\x61\xd8\x8f\x6f\x85\xea\xe8\x13\x7e\x37\x6b\xf8\x13\x0e\x8f\x74\x41\x92\x30\x06\x67\x3a\x75\x81\xd1\x05\x65\x34\xf2\xe9\x5b\x4b\x6f\x8c\xf8\x44\xa7\x59\xb8\xfd\x87\x58\x51\xa0\xb1\xbc\x28\x7f\xd3\xb7\x33\xea\x12\x3b\x21\xf7\x52\x73\x3b\xef\xf7\xe7\x22\xcb\xc7\xf8\xa5\x3e\xc2\x18\xff\x4e\x6a\x83\x23\xfc\xb6\xe2\x29\x9a\xce\xb4\xfb\xcd\x07\x2d\xeb\x02\x2f\x07\x94\xfd\xed\x3f\xf2\x72\xd4\x65\xb9\xb1\xed\xc8\x06\x85\xb1\x75\xa5\xf4\xa1\x29\xe6\xc0\x30\xb9\x2e\x50\x69\x2b\xb4\xe2\x72\x48\x98\xbd\xae\x50\x64\xea\x07\xb9\xf9\xf2\x15\x72\x8b\xe9\x86\xdb\xcb\x3c\x03\x2a\x54\x97\xe9\xff\xc0\x24\x0a\x5e\x13\x0a\xce\xf7\x6f\xe6\xb5\x8d\xf7\xce\xfd\x75\x27\xee\xfd\xe4\x0b\xd1\x3b\x94\x19\xfb\x03\x00\x00")
+
+func _7_create_candlesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__7_create_candlesUpSql,
+		"7_create_candles.up.sql",
+	)
+}
+
+func _7_create_candlesUpSql() (*asset, error) {
+	bytes, err := _7_create_candlesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "7_create_candles.up.sql", size: 1019, mode: os.FileMode(436), modTime: time.Unix(1792401316, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __8_create_indicator_snapshotsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xcc\x4b\xc9\x4c\x4e\x2c\xc9\x2f\x8a\x2f\xce\x4b\x2c\x28\xce\xc8\x2f\x29\xb6\xe6\x02\x00\xe1\xfa\xac\xa7\x2a\x00\x00\x00")
+
+func _8_create_indicator_snapshotsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__8_create_indicator_snapshotsDownSql,
+		"8_create_indicator_snapshots.down.sql",
+	)
+}
+
+func _8_create_indicator_snapshotsDownSql() (*asset, error) {
+	bytes, err := _8_create_indicator_snapshotsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "8_create_indicator_snapshots.down.sql", size: 42, mode: os.FileMode(436), modTime: time.Unix(1792402398, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __8_create_indicator_snapshotsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x90\x3b\x6b\xc3\x30\x14\x85\x77\xff\x8a\xbb\x59\x82\x0c\xee\x56\xe8\xa4\xba\xd7\xd4\xd4\x51\x5a\x45\x0a\xcd\x64\xe4\x48\xa4\x82\xfa\x81\xa5\x0c\xe9\xaf\xaf\xf3\x70\x43\x07\x93\xe4\x4e\x1f\x17\xbe\xc3\xe1\xa4\x02\x99\x44\x90\xec\xb9\x40\x70\x8d\x71\x1b\x1d\xda\xbe\xf4\x8d\xee\xfc\x57\x1b\x3c\x89\x60\xe2\x9c\xb9\x60\x13\xec\xd6\xf6\xf0\x2e\xf2\x39\x13\x6b\x78\xc3\x35\x30\x25\x17\x39\x4f\x05\xce\x91\xcb\xd9\x64\x8a\xdf\xd7\x55\xfb\x7d\xc4\x15\x13\xe9\x2b\x13\xe4\x31\xa1\x00\x7c\x21\x81\xab\xa2\x98\x36\x2b\xd7\x8c\x38\x9a\x0f\xc9\xa0\x8e\x26\xbc\x60\xc6\x54\x21\x81\xc4\x31\x05\x81\x19\x0a\xe4\x29\x2e\x0f\x62\xe9\xdd\x8f\x25\x03\xd0\xe9\xfc\xe0\x6a\xeb\x83\xae\xbb\x7f\x78\x4b\x33\xa3\x83\x3e\xa3\xc4\x4f\xf9\xf7\xbf\x6e\x6e\x7a\xab\x83\x35\xa5\x0e\x43\xcb\xed\x30\xeb\x0d\xce\xae\x33\x77\x3b\x8a\xe7\x1f\x0a\x81\x9c\xd6\x9f\x1d\x26\xa1\x11\x7d\x8a\x7e\x01\x76\xab\x11\x2a\x0e\x02\x00\x00")
+
+func _8_create_indicator_snapshotsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__8_create_indicator_snapshotsUpSql,
+		"8_create_indicator_snapshots.up.sql",
+	)
+}
+
+func _8_create_indicator_snapshotsUpSql() (*asset, error) {
+	bytes, err := _8_create_indicator_snapshotsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "8_create_indicator_snapshots.up.sql", size: 526, mode: os.FileMode(436), modTime: time.Unix(1792402398, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __9_add_strategies_config_ma_typesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x94\xcd\x6e\xa3\x30\x14\x85\xf7\x3c\xc5\xdd\x95\x48\x5e\x74\xa4\xee\xb2\xf2\xd0\x9b\x0e\x2a\x81\xca\x71\x46\xea\xca\xb2\x83\x13\x21\x39\x76\x65\x5c\xcd\x74\x9e\x7e\x4c\x68\x5a\x85\x02\x0d\x1b\x10\x3e\xf7\xc7\xf7\x7c\x76\xc6\x90\x72\x04\x4e\x7f\x16\x08\x6d\xf0\x32\xe8\x43\xa3\x5b\xb1\x73\x76\xdf\x1c\xe2\xeb\xe5\x0d\xd2\x04\xe2\xd3\xd4\x70\x7e\x1a\x1b\x55\xda\xc3\x13\xcb\xd7\x94\x3d\xc3\x23\x3e\x03\xdd\xf2\x2a\x2f\x33\x86\x6b\x2c\x39\x39\x45\xa8\xc6\x9e\x23\x7e\x53\x96\xfd\xa2\x2c\xfd\x71\x7b\xbb\x80\xb2\xe2\x50\x6e\x8b\x02\xee\x71\x45\xb7\x05\x87\xf4\xe6\x66\x01\x0c\x57\xc8\xb0\xcc\x70\xd3\x05\x8a\xb6\xf9\xa7\xd3\xf8\xb1\xe8\x73\x69\x2b\x95\xd1\xc2\xb7\x8d\x50\x0a\x94\x73\x46\x4b\x0b\xd6\x05\xb0\xaf\xc6\xf4\x1a\xaf\x83\x7f\x13\x2f\xde\xed\x74\xdb\xed\xe0\xd5\x86\x8f\x56\x2f\x95\x07\x1d\xc4\x4e\xda\xda\xe8\x69\xdd\x49\x18\xbc\xb6\xb5\xd8\x37\x26\x68\x2f\xfa\x1e\x26\x8a\x9f\xd3\x5d\xa3\x3d\xca\xbf\x67\x5d\x5f\x60\xae\xd7\x4e\x3c\x48\x3e\xdb\xb2\x52\xc2\xc8\xf6\xca\xfd\x75\xf3\x9c\x2b\xde\xad\x1f\xa3\x1b\xca\xf9\x3a\x2e\xce\x88\x62\x97\xdf\x8a\x22\x5e\xb5\x8e\xf5\xf4\x1e\xf6\xc6\xc9\x70\x37\x6c\xe7\x28\x77\x71\xda\xa7\xee\x67\x27\x12\x55\xad\x71\x7f\xae\x50\x9d\x18\x9e\x19\xc0\xc1\x38\x25\x8d\x88\x70\xe7\x25\xc7\x07\x64\x7d\xf0\xaa\x62\x98\x3f\x94\x1d\xd9\xe9\x87\xe4\x82\xd1\xf7\xbf\xfd\x39\x49\xe3\x62\xb2\x58\x26\x79\xb9\x41\xc6\xbb\x54\xd5\xd4\x61\xda\x60\x81\x19\x7f\x3f\x51\xa4\x43\x9d\x5c\xc2\x4d\xc6\x38\x26\x5f\x91\x25\x63\x70\x92\x71\x0e\xe7\xb0\x23\x93\x84\x91\x71\x96\xc8\x27\x35\x64\x00\xc8\x18\x0f\x64\x60\x3d\x19\xba\x4c\x86\x86\x92\x81\x77\xe4\xd3\xa5\x64\xc5\xaa\xf5\xd7\xd1\x2e\x93\x7b\x56\x3d\x4d\xdd\x62\xcb\x84\x16\x1c\xd9\xfc\x25\x17\x6d\xa5\xeb\x78\x11\x56\x63\xf1\xff\x01\x08\xb8\xe4\xee\x26\x05\x00\x00")
+
+func _9_add_strategies_config_ma_typesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__9_add_strategies_config_ma_typesDownSql,
+		"9_add_strategies_config_ma_types.down.sql",
+	)
+}
+
+func _9_add_strategies_config_ma_typesDownSql() (*asset, error) {
+	bytes, err := _9_add_strategies_config_ma_typesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "9_add_strategies_config_ma_types.down.sql", size: 1318, mode: os.FileMode(436), modTime: time.Unix(1792402566, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __9_add_strategies_config_ma_typesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb5\xcc\xb1\x0e\x82\x30\x10\x00\xd0\xdd\xaf\xb8\x0d\xdd\x70\x33\x71\xaa\xc2\x06\x9a\x18\x9c\x9b\x83\x5e\xf1\x92\xd2\x92\xf6\xc0\xf8\xf7\xba\xbb\x48\x0c\xfb\xcb\x53\x55\x53\xde\xa0\x51\xa7\xaa\x84\x24\x11\x85\x7a\xa6\xa4\xbb\xe0\x2d\xf7\xa0\x8a\x02\xce\xd7\xea\x5e\x5f\xa0\x6d\xf5\x48\x91\x83\x01\xf6\x1f\x44\x11\x7c\x10\xf0\x93\x73\x60\xc8\xe2\xe4\x04\xf2\xe3\x46\x2d\xe8\x0c\xcd\x8c\xc2\xc1\x83\x75\x01\xe5\xf0\x77\x38\xa0\x96\xd7\x48\x30\x63\xec\x1e\x18\xb7\xfb\x7c\xf7\x7d\x66\xd9\x82\x74\xc0\xce\x68\x8b\x49\xd6\xba\x93\x0b\xcf\xd5\x6e\xee\x7f\xaf\xdf\x90\x95\x8c\x23\x08\x02\x00\x00")
+
+func _9_add_strategies_config_ma_typesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__9_add_strategies_config_ma_typesUpSql,
+		"9_add_strategies_config_ma_types.up.sql",
+	)
+}
+
+func _9_add_strategies_config_ma_typesUpSql() (*asset, error) {
+	bytes, err := _9_add_strategies_config_ma_typesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "9_add_strategies_config_ma_types.up.sql", size: 520, mode: os.FileMode(436), modTime: time.Unix(1792402566, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -339,27 +507,37 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"1_create_bin_size.down.sql":    _1_create_bin_sizeDownSql,
-	"1_create_bin_size.up.sql":      _1_create_bin_sizeUpSql,
-	"2_create_signal_type.down.sql": _2_create_signal_typeDownSql,
-	"2_create_signal_type.up.sql":   _2_create_signal_typeUpSql,
-	"3_create_signals.down.sql":     _3_create_signalsDownSql,
-	"3_create_signals.up.sql":       _3_create_signalsUpSql,
-	"4_create_orders.down.sql":      _4_create_ordersDownSql,
-	"4_create_orders.up.sql":        _4_create_ordersUpSql,
-	"5_create_config.down.sql":      _5_create_configDownSql,
-	"5_create_config.up.sql":        _5_create_configUpSql,
+	"1_create_bin_size.down.sql":                _1_create_bin_sizeDownSql,
+	"1_create_bin_size.up.sql":                  _1_create_bin_sizeUpSql,
+	"2_create_signal_type.down.sql":             _2_create_signal_typeDownSql,
+	"2_create_signal_type.up.sql":               _2_create_signal_typeUpSql,
+	"3_create_signals.down.sql":                 _3_create_signalsDownSql,
+	"3_create_signals.up.sql":                   _3_create_signalsUpSql,
+	"4_create_orders.down.sql":                  _4_create_ordersDownSql,
+	"4_create_orders.up.sql":                    _4_create_ordersUpSql,
+	"5_create_config.down.sql":                  _5_create_configDownSql,
+	"5_create_config.up.sql":                    _5_create_configUpSql,
+	"6_add_resampled_bin_size.down.sql":         _6_add_resampled_bin_sizeDownSql,
+	"6_add_resampled_bin_size.up.sql":           _6_add_resampled_bin_sizeUpSql,
+	"7_create_candles.down.sql":                 _7_create_candlesDownSql,
+	"7_create_candles.up.sql":                   _7_create_candlesUpSql,
+	"8_create_indicator_snapshots.down.sql":     _8_create_indicator_snapshotsDownSql,
+	"8_create_indicator_snapshots.up.sql":       _8_create_indicator_snapshotsUpSql,
+	"9_add_strategies_config_ma_types.down.sql": _9_add_strategies_config_ma_typesDownSql,
+	"9_add_strategies_config_ma_types.up.sql":   _9_add_strategies_config_ma_typesUpSql,
 }
 
 // AssetDir returns the file names below a certain
 // directory embedded in the file by go-bindata.
 // For example if you run go-bindata on data/... and data contains the
 // following hierarchy:
-//     data/
-//       foo.txt
-//       img/
-//         a.png
-//         b.png
+//
+//	data/
+//	  foo.txt
+//	  img/
+//	    a.png
+//	    b.png
+//
 // then AssetDir("data") would return []string{"foo.txt", "img"}
 // AssetDir("data/img") would return []string{"a.png", "b.png"}
 // AssetDir("foo.txt") and AssetDir("notexist") would return an error
@@ -392,16 +570,24 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"1_create_bin_size.down.sql":    &bintree{_1_create_bin_sizeDownSql, map[string]*bintree{}},
-	"1_create_bin_size.up.sql":      &bintree{_1_create_bin_sizeUpSql, map[string]*bintree{}},
-	"2_create_signal_type.down.sql": &bintree{_2_create_signal_typeDownSql, map[string]*bintree{}},
-	"2_create_signal_type.up.sql":   &bintree{_2_create_signal_typeUpSql, map[string]*bintree{}},
-	"3_create_signals.down.sql":     &bintree{_3_create_signalsDownSql, map[string]*bintree{}},
-	"3_create_signals.up.sql":       &bintree{_3_create_signalsUpSql, map[string]*bintree{}},
-	"4_create_orders.down.sql":      &bintree{_4_create_ordersDownSql, map[string]*bintree{}},
-	"4_create_orders.up.sql":        &bintree{_4_create_ordersUpSql, map[string]*bintree{}},
-	"5_create_config.down.sql":      &bintree{_5_create_configDownSql, map[string]*bintree{}},
-	"5_create_config.up.sql":        &bintree{_5_create_configUpSql, map[string]*bintree{}},
+	"1_create_bin_size.down.sql":                &bintree{_1_create_bin_sizeDownSql, map[string]*bintree{}},
+	"1_create_bin_size.up.sql":                  &bintree{_1_create_bin_sizeUpSql, map[string]*bintree{}},
+	"2_create_signal_type.down.sql":             &bintree{_2_create_signal_typeDownSql, map[string]*bintree{}},
+	"2_create_signal_type.up.sql":               &bintree{_2_create_signal_typeUpSql, map[string]*bintree{}},
+	"3_create_signals.down.sql":                 &bintree{_3_create_signalsDownSql, map[string]*bintree{}},
+	"3_create_signals.up.sql":                   &bintree{_3_create_signalsUpSql, map[string]*bintree{}},
+	"4_create_orders.down.sql":                  &bintree{_4_create_ordersDownSql, map[string]*bintree{}},
+	"4_create_orders.up.sql":                    &bintree{_4_create_ordersUpSql, map[string]*bintree{}},
+	"5_create_config.down.sql":                  &bintree{_5_create_configDownSql, map[string]*bintree{}},
+	"5_create_config.up.sql":                    &bintree{_5_create_configUpSql, map[string]*bintree{}},
+	"6_add_resampled_bin_size.down.sql":         &bintree{_6_add_resampled_bin_sizeDownSql, map[string]*bintree{}},
+	"6_add_resampled_bin_size.up.sql":           &bintree{_6_add_resampled_bin_sizeUpSql, map[string]*bintree{}},
+	"7_create_candles.down.sql":                 &bintree{_7_create_candlesDownSql, map[string]*bintree{}},
+	"7_create_candles.up.sql":                   &bintree{_7_create_candlesUpSql, map[string]*bintree{}},
+	"8_create_indicator_snapshots.down.sql":     &bintree{_8_create_indicator_snapshotsDownSql, map[string]*bintree{}},
+	"8_create_indicator_snapshots.up.sql":       &bintree{_8_create_indicator_snapshotsUpSql, map[string]*bintree{}},
+	"9_add_strategies_config_ma_types.down.sql": &bintree{_9_add_strategies_config_ma_typesDownSql, map[string]*bintree{}},
+	"9_add_strategies_config_ma_types.up.sql":   &bintree{_9_add_strategies_config_ma_typesUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
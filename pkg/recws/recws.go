@@ -47,6 +47,7 @@ type RecConn struct {
 	NonVerbose bool
 
 	isConnected bool
+	isShutdown  bool
 	mu          sync.RWMutex
 	url         string
 	reqHeader   http.Header
@@ -60,9 +61,30 @@ type RecConn struct {
 // CloseAndReconnect will try to reconnect.
 func (rc *RecConn) closeAndReconnect() {
 	rc.Close()
+	if rc.IsShutdown() {
+		return
+	}
 	go rc.connect()
 }
 
+// Shutdown closes the underlying network connection,
+// the connection is not reconnected after shutdown
+func (rc *RecConn) Shutdown() {
+	rc.mu.Lock()
+	rc.isShutdown = true
+	rc.mu.Unlock()
+
+	rc.Close()
+}
+
+// IsShutdown returns true if Shutdown was called
+func (rc *RecConn) IsShutdown() bool {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	return rc.isShutdown
+}
+
 // setIsConnected sets state for isConnected
 func (rc *RecConn) setIsConnected(state bool) {
 	rc.mu.Lock()
@@ -411,6 +433,9 @@ func (rc *RecConn) keepAlive() {
 		for {
 			select { // nolint:gosimple
 			case <-tickerCheckConnected.C:
+				if rc.IsShutdown() {
+					return
+				}
 				if !rc.IsConnected() {
 					continue
 				}
@@ -433,10 +458,20 @@ func (rc *RecConn) connect() {
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	for {
+		if rc.IsShutdown() {
+			return
+		}
 		nextItvl := b.Duration()
 		wsConn, httpResp, err := rc.dialer.Dial(rc.url, rc.reqHeader) // nolint:bodyclose
 
 		rc.mu.Lock()
+		if rc.isShutdown {
+			rc.mu.Unlock()
+			if err == nil {
+				wsConn.Close()
+			}
+			return
+		}
 		rc.Conn = wsConn
 		rc.dialErr = err
 		rc.isConnected = err == nil
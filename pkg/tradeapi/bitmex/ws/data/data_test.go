@@ -0,0 +1,90 @@
+package data
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+)
+
+func TestBitmexData_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     string
+		want    Event
+		wantErr bool
+	}{
+		{
+			name: "trade bin",
+			msg: `{"table":"tradeBin5m","action":"insert","data":[{"timestamp":"2020-05-16T10:10:00.000Z",` +
+				`"symbol":"XBTUSD","open":9300,"high":9310.5,"low":9295,"close":9301,"trades":12,"volume":3400}]}`,
+			want: &TradeBinEvent{
+				Header: Header{Table: "tradeBin5m", Action: "insert"},
+				Data: []TradeBin{{
+					Symbol:    types.XBTUSD,
+					Timestamp: "2020-05-16T10:10:00.000Z",
+					Open:      9300,
+					High:      9310.5,
+					Low:       9295,
+					Close:     9301,
+					Trades:    12,
+					Volume:    3400,
+				}},
+			},
+		},
+		{
+			name: "position",
+			msg: `{"table":"position","action":"update","data":[{"account":1,"symbol":"XBTUSD",` +
+				`"currentQty":-100,"avgCostPrice":9300,"timestamp":"2020-05-16T10:10:01.123Z"}]}`,
+			want: &PositionEvent{
+				Header: Header{Table: "position", Action: "update"},
+				Data: []Position{{
+					Symbol:       types.XBTUSD,
+					Timestamp:    "2020-05-16T10:10:01.123Z",
+					Account:      1,
+					CurrentQty:   -100,
+					AvgCostPrice: 9300,
+				}},
+			},
+		},
+		{
+			name: "order book",
+			msg: `{"table":"orderBookL2","action":"update","data":[{"symbol":"XBTUSD","id":8799069850,` +
+				`"side":"Sell","size":2000,"price":9301.5}]}`,
+			want: &OrderBookL2Event{
+				Header: Header{Table: "orderBookL2", Action: "update"},
+				Data:   []OrderBookL2{{Symbol: types.XBTUSD, ID: 8799069850, Side: types.SideSell, Size: 2000, Price: 9301.5}},
+			},
+		},
+		{
+			name:    "unknown table",
+			msg:     `{"table":"liquidation","action":"insert","data":[{"symbol":"XBTUSD"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "bad data",
+			msg:     `{"table":"trade","action":"insert","data":{"symbol":"XBTUSD"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &BitmexData{}
+			require.NoError(t, jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(tt.msg), msg))
+			got, err := msg.Decode()
+			require.Equal(t, tt.wantErr, err != nil, "error", err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBitmexData_Validate(t *testing.T) {
+	require.NoError(t, (&BitmexData{Action: "insert", Data: []byte(`[{}]`)}).Validate())
+	require.Error(t, (&BitmexData{Action: "insert", Data: []byte(` [] `)}).Validate())
+	require.Error(t, (&BitmexData{Action: "insert"}).Validate())
+	require.NoError(t, (&BitmexData{Action: "partial", Data: []byte(`[]`)}).Validate())
+	require.Error(t, (&BitmexData{Action: "remove", Data: []byte(`[{}]`)}).Validate())
+}
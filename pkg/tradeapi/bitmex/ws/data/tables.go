@@ -0,0 +1,248 @@
+package data
+
+import (
+	"time"
+
+	"github.com/tagirmukail/tccbot-backend/internal/types"
+)
+
+// TradeBin tradeBin1m, tradeBin5m, tradeBin1h and tradeBin1d tables row
+type TradeBin struct {
+	Symbol          types.Symbol `json:"symbol"`
+	Timestamp       string       `json:"timestamp"`
+	Open            float64      `json:"open"`
+	High            float64      `json:"high"`
+	Low             float64      `json:"low"`
+	Close           float64      `json:"close"`
+	Trades          int          `json:"trades"`
+	Volume          int64        `json:"volume"`
+	LastSize        int          `json:"lastSize"`
+	Turnover        int64        `json:"turnover"`
+	Vwap            float64      `json:"vwap"`
+	HomeNotional    float64      `json:"homeNotional"`
+	ForeignNotional float64      `json:"foreignNotional"`
+}
+
+// Trade trade table row
+type Trade struct {
+	Symbol          types.Symbol `json:"symbol"`
+	Timestamp       string       `json:"timestamp"`
+	Side            types.Side   `json:"side"`
+	Size            int          `json:"size"`
+	Price           float64      `json:"price"`
+	TickDirection   string       `json:"tickDirection"`
+	TrdMatchID      string       `json:"trdMatchID"`
+	GrossValue      int64        `json:"grossValue"`
+	HomeNotional    float64      `json:"homeNotional"`
+	ForeignNotional float64      `json:"foreignNotional"`
+}
+
+// Position position table row
+type Position struct { // nolint:maligned
+	Symbol               types.Symbol `json:"symbol"`
+	Timestamp            string       `json:"timestamp"`
+	Account              int64        `json:"account"`
+	AvgCostPrice         float64      `json:"avgCostPrice"`
+	AvgEntryPrice        float64      `json:"avgEntryPrice"`
+	BankruptPrice        float64      `json:"bankruptPrice"`
+	BreakEvenPrice       float64      `json:"breakEvenPrice"`
+	Commission           float64      `json:"commission"`
+	CrossMargin          bool         `json:"crossMargin"`
+	Currency             string       `json:"currency"`
+	CurrentComm          int64        `json:"currentComm"`
+	CurrentCost          int64        `json:"currentCost"`
+	CurrentQty           int64        `json:"currentQty"`
+	CurrentTimestamp     time.Time    `json:"currentTimestamp"`
+	DeleveragePercentile float64      `json:"deleveragePercentile"`
+	ExecBuyCost          int64        `json:"execBuyCost"`
+	ExecBuyQty           int64        `json:"execBuyQty"`
+	ExecComm             int64        `json:"execComm"`
+	ExecCost             int64        `json:"execCost"`
+	ExecQty              int64        `json:"execQty"`
+	ExecSellCost         int64        `json:"execSellCost"`
+	ExecSellQty          int64        `json:"execSellQty"`
+	ForeignNotional      float64      `json:"foreignNotional"`
+	GrossExecCost        int64        `json:"grossExecCost"`
+	GrossOpenCost        int64        `json:"grossOpenCost"`
+	GrossOpenPremium     int64        `json:"grossOpenPremium"`
+	HomeNotional         float64      `json:"homeNotional"`
+	IndicativeTax        int64        `json:"indicativeTax"`
+	IndicativeTaxRate    float64      `json:"indicativeTaxRate"`
+	InitMargin           int64        `json:"initMargin"`
+	InitMarginReq        float64      `json:"initMarginReq"`
+	IsOpen               bool         `json:"isOpen"`
+	LastPrice            float64      `json:"lastPrice"`
+	LastValue            int64        `json:"lastValue"`
+	Leverage             float64      `json:"leverage"`
+	LiquidationPrice     float64      `json:"liquidationPrice"`
+	LongBankrupt         int64        `json:"longBankrupt"`
+	MaintMargin          int64        `json:"maintMargin"`
+	MaintMarginReq       float64      `json:"maintMarginReq"`
+	MarginCallPrice      float64      `json:"marginCallPrice"`
+	MarkPrice            float64      `json:"markPrice"`
+	MarkValue            int64        `json:"markValue"`
+	OpenOrderBuyCost     int64        `json:"openOrderBuyCost"`
+	OpenOrderBuyPremium  int64        `json:"openOrderBuyPremium"`
+	OpenOrderBuyQty      int64        `json:"openOrderBuyQty"`
+	OpenOrderSellCost    int64        `json:"openOrderSellCost"`
+	OpenOrderSellPremium int64        `json:"openOrderSellPremium"`
+	OpenOrderSellQty     int64        `json:"openOrderSellQty"`
+	OpeningComm          int64        `json:"openingComm"`
+	OpeningCost          int64        `json:"openingCost"`
+	OpeningQty           int64        `json:"openingQty"`
+	OpeningTimestamp     time.Time    `json:"openingTimestamp"`
+	PosAllowance         int64        `json:"posAllowance"`
+	PosComm              int64        `json:"posComm"`
+	PosCost              int64        `json:"posCost"`
+	PosCost2             int64        `json:"posCost2"`
+	PosCross             int64        `json:"posCross"`
+	PosInit              int64        `json:"posInit"`
+	PosLoss              int64        `json:"posLoss"`
+	PosMaint             int64        `json:"posMaint"`
+	PosMargin            int64        `json:"posMargin"`
+	PosState             string       `json:"posState"`
+	PrevClosePrice       float64      `json:"prevClosePrice"`
+	PrevRealisedPnl      int64        `json:"prevRealisedPnl"`
+	PrevUnrealisedPnl    int64        `json:"prevUnrealisedPnl"`
+	QuoteCurrency        string       `json:"quoteCurrency"`
+	RealisedCost         int64        `json:"realisedCost"`
+	RealisedGrossPnl     int64        `json:"realisedGrossPnl"`
+	RealisedPnl          int64        `json:"realisedPnl"`
+	RealisedTax          int64        `json:"realisedTax"`
+	RebalancedPnl        int64        `json:"rebalancedPnl"`
+	RiskLimit            int64        `json:"riskLimit"`
+	RiskValue            int64        `json:"riskValue"`
+	SessionMargin        int64        `json:"sessionMargin"`
+	ShortBankrupt        int64        `json:"shortBankrupt"`
+	SimpleCost           float64      `json:"simpleCost"`
+	SimplePnl            float64      `json:"simplePnl"`
+	SimplePnlPcnt        float64      `json:"simplePnlPcnt"`
+	SimpleQty            float64      `json:"simpleQty"`
+	SimpleValue          float64      `json:"simpleValue"`
+	TargetExcessMargin   int64        `json:"targetExcessMargin"`
+	TaxBase              int64        `json:"taxBase"`
+	TaxableMargin        int64        `json:"taxableMargin"`
+	Underlying           string       `json:"underlying"`
+	UnrealisedCost       int64        `json:"unrealisedCost"`
+	UnrealisedGrossPnl   int64        `json:"unrealisedGrossPnl"`
+	UnrealisedPnl        *int64       `json:"unrealisedPnl"`
+	UnrealisedPnlPcnt    float64      `json:"unrealisedPnlPcnt"`
+	UnrealisedRoePcnt    float64      `json:"unrealisedRoePcnt"`
+	UnrealisedTax        int64        `json:"unrealisedTax"`
+	VarMargin            int64        `json:"varMargin"`
+}
+
+// Order order table row, update rows contain only changed fields,
+// quantities, prices and working indicator are nil if they are not present in the row
+type Order struct {
+	OrderID          string       `json:"orderID"`
+	ClOrdID          string       `json:"clOrdID"`
+	Account          int64        `json:"account"`
+	Symbol           types.Symbol `json:"symbol"`
+	Side             string       `json:"side"`
+	OrderQty         *int64       `json:"orderQty"`
+	Price            *float64     `json:"price"`
+	StopPx           *float64     `json:"stopPx"`
+	OrdType          string       `json:"ordType"`
+	TimeInForce      string       `json:"timeInForce"`
+	ExecInst         string       `json:"execInst"`
+	OrdStatus        string       `json:"ordStatus"`
+	LeavesQty        *int64       `json:"leavesQty"`
+	CumQty           *int64       `json:"cumQty"`
+	AvgPx            *float64     `json:"avgPx"`
+	Text             string       `json:"text"`
+	WorkingIndicator *bool        `json:"workingIndicator"`
+	TransactTime     time.Time    `json:"transactTime"`
+	Timestamp        time.Time    `json:"timestamp"`
+}
+
+// Execution execution table row
+type Execution struct {
+	ExecID           string       `json:"execID"`
+	OrderID          string       `json:"orderID"`
+	ClOrdID          string       `json:"clOrdID"`
+	Account          int64        `json:"account"`
+	Symbol           types.Symbol `json:"symbol"`
+	Side             string       `json:"side"`
+	LastQty          int64        `json:"lastQty"`
+	LastPx           float64      `json:"lastPx"`
+	OrderQty         int64        `json:"orderQty"`
+	Price            float64      `json:"price"`
+	OrdType          string       `json:"ordType"`
+	ExecType         string       `json:"execType"`
+	OrdStatus        string       `json:"ordStatus"`
+	LeavesQty        int64        `json:"leavesQty"`
+	CumQty           int64        `json:"cumQty"`
+	AvgPx            float64      `json:"avgPx"`
+	Commission       float64      `json:"commission"`
+	ExecComm         int64        `json:"execComm"`
+	ExecCost         int64        `json:"execCost"`
+	LastLiquidityInd string       `json:"lastLiquidityInd"`
+	HomeNotional     float64      `json:"homeNotional"`
+	ForeignNotional  float64      `json:"foreignNotional"`
+	Text             string       `json:"text"`
+	TransactTime     time.Time    `json:"transactTime"`
+	Timestamp        time.Time    `json:"timestamp"`
+}
+
+// Margin margin table row, balances are nil in update rows if they are not changed
+type Margin struct {
+	Account            int64     `json:"account"`
+	Currency           string    `json:"currency"`
+	Amount             int64     `json:"amount"`
+	WalletBalance      *int64    `json:"walletBalance"`
+	MarginBalance      *int64    `json:"marginBalance"`
+	AvailableMargin    *int64    `json:"availableMargin"`
+	WithdrawableMargin *int64    `json:"withdrawableMargin"`
+	RealisedPnl        int64     `json:"realisedPnl"`
+	UnrealisedPnl      int64     `json:"unrealisedPnl"`
+	InitMargin         int64     `json:"initMargin"`
+	MaintMargin        int64     `json:"maintMargin"`
+	MarginUsedPcnt     float64   `json:"marginUsedPcnt"`
+	MarginLeverage     float64   `json:"marginLeverage"`
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// Wallet wallet table row, amount is nil in update rows if it is not changed
+type Wallet struct {
+	Account   int64     `json:"account"`
+	Currency  string    `json:"currency"`
+	Amount    *int64    `json:"amount"`
+	Deposited int64     `json:"deposited"`
+	Withdrawn int64     `json:"withdrawn"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Instrument instrument table row, update rows contain only changed fields
+type Instrument struct {
+	Symbol                types.Symbol `json:"symbol"`
+	State                 string       `json:"state"`
+	Typ                   string       `json:"typ"`
+	QuoteCurrency         string       `json:"quoteCurrency"`
+	SettlCurrency         string       `json:"settlCurrency"`
+	IsInverse             bool         `json:"isInverse"`
+	IsQuanto              bool         `json:"isQuanto"`
+	Multiplier            int64        `json:"multiplier"`
+	LotSize               int64        `json:"lotSize"`
+	TickSize              float64      `json:"tickSize"`
+	MakerFee              float64      `json:"makerFee"`
+	TakerFee              float64      `json:"takerFee"`
+	FundingRate           float64      `json:"fundingRate"`
+	LastPrice             float64      `json:"lastPrice"`
+	BidPrice              float64      `json:"bidPrice"`
+	AskPrice              float64      `json:"askPrice"`
+	MidPrice              float64      `json:"midPrice"`
+	MarkPrice             float64      `json:"markPrice"`
+	FairPrice             float64      `json:"fairPrice"`
+	IndicativeSettlePrice float64      `json:"indicativeSettlePrice"`
+	Timestamp             time.Time    `json:"timestamp"`
+}
+
+// OrderBookL2 orderBookL2 table row
+type OrderBookL2 struct {
+	Symbol types.Symbol `json:"symbol"`
+	ID     int64        `json:"id"`
+	Side   types.Side   `json:"side"`
+	Size   int64        `json:"size"`
+	Price  float64      `json:"price"`
+}
@@ -0,0 +1,126 @@
+// Package record provides recording of raw websocket frames to rotating gzip files
+// and replaying of recorded frames.
+package record
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	DefaultMaxFileSize = 100 << 20 // uncompressed bytes
+	DefaultRotateEvery = time.Hour
+
+	fileNameLayout = "20060102T150405.000000000"
+	fileExt        = ".jsonl.gz"
+)
+
+// Frame raw websocket frame with receive time
+type Frame struct {
+	ReceivedAt time.Time `json:"ts"`
+	Data       string    `json:"frame"`
+}
+
+// Recorder writes frames as json lines to gzip files,
+// file is rotated when it exceeds max size or rotation period
+type Recorder struct {
+	dir         string
+	prefix      string
+	maxFileSize int64
+	rotateEvery time.Duration
+
+	mx       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	written  int64
+	openedAt time.Time
+}
+
+func NewRecorder(dir, prefix string, maxFileSize int64, rotateEvery time.Duration) (*Recorder, error) {
+	if maxFileSize <= 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+	if rotateEvery <= 0 {
+		rotateEvery = DefaultRotateEvery
+	}
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		dir:         dir,
+		prefix:      prefix,
+		maxFileSize: maxFileSize,
+		rotateEvery: rotateEvery,
+	}, nil
+}
+
+// Record writes frame received at ts
+func (r *Recorder) Record(ts time.Time, frame []byte) error {
+	line, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(Frame{ReceivedAt: ts.UTC(), Data: string(frame)})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	isFull := r.written > 0 && r.written+int64(len(line)) > r.maxFileSize
+	if r.file == nil || isFull || ts.Sub(r.openedAt) >= r.rotateEvery {
+		err = r.rotate(ts)
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := r.gz.Write(line)
+	r.written += int64(n)
+	return err
+}
+
+// Close flushes and closes current file
+func (r *Recorder) Close() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.close()
+}
+
+func (r *Recorder) rotate(ts time.Time) error {
+	err := r.close()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(r.dir, fmt.Sprintf("%s-%s%s", r.prefix, ts.UTC().Format(fileNameLayout), fileExt))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	r.file = file
+	r.gz = gzip.NewWriter(file)
+	r.written = 0
+	r.openedAt = ts
+	return nil
+}
+
+func (r *Recorder) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.gz.Close()
+	if err != nil {
+		_ = r.file.Close()
+		return err
+	}
+	err = r.file.Close()
+	r.file, r.gz = nil, nil
+	return err
+}
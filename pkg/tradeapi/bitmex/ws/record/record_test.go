@@ -0,0 +1,64 @@
+package record
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rec, err := NewRecorder(dir, "bitmex", 200, time.Minute)
+	require.NoError(t, err)
+
+	start := time.Date(2020, 5, 16, 10, 10, 0, 0, time.UTC)
+	frames := []Frame{
+		{ReceivedAt: start, Data: `{"table":"tradeBin1m","action":"insert","data":[{"symbol":"XBTUSD"}]}`},
+		{ReceivedAt: start.Add(time.Second), Data: "pong"},
+		{ReceivedAt: start.Add(2 * time.Second), Data: `{"table":"position","action":"update","data":[]}`},
+		{ReceivedAt: start.Add(2 * time.Minute), Data: `{"table":"trade","action":"insert","data":[]}`},
+	}
+	for _, frame := range frames {
+		require.NoError(t, rec.Record(frame.ReceivedAt, []byte(frame.Data)))
+	}
+	require.NoError(t, rec.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "bitmex-*.jsonl.gz"))
+	require.NoError(t, err)
+	require.Len(t, files, 3, "rotated by size and by period")
+
+	replayer, err := NewReplayer(filepath.Join(dir, "bitmex-*"), 0)
+	require.NoError(t, err)
+
+	var got []Frame
+	err = replayer.Replay(make(chan struct{}), func(frame Frame) {
+		got = append(got, frame)
+	})
+	require.NoError(t, err)
+	require.Equal(t, frames, got)
+}
+
+func TestReplayer_wait(t *testing.T) {
+	r := &Replayer{speed: 1000}
+	prev := time.Date(2020, 5, 16, 10, 10, 0, 0, time.UTC)
+
+	begin := time.Now()
+	require.NoError(t, r.wait(nil, prev, prev.Add(10*time.Second)))
+	require.True(t, time.Since(begin) >= 10*time.Millisecond)
+
+	done := make(chan struct{})
+	close(done)
+	require.Equal(t, ErrReplayStopped, r.wait(done, prev, prev.Add(time.Hour)))
+}
+
+func TestNewReplayer(t *testing.T) {
+	_, err := NewReplayer(filepath.Join(os.TempDir(), "not-exist-*.jsonl.gz"), 1)
+	require.Error(t, err)
+}
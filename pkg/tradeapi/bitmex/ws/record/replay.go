@@ -0,0 +1,114 @@
+package record
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const maxLineSize = 16 << 20
+
+// ErrReplayStopped is returned when replay is stopped before all frames are replayed
+var ErrReplayStopped = errors.New("replay stopped")
+
+// Replayer reads recorded files in order of names and replays frames
+type Replayer struct {
+	files []string
+	speed float64
+}
+
+// NewReplayer creates replayer of files matched pattern.
+// speed 1 replays frames with original delays, 2 - twice as fast, 0 - without delays
+func NewReplayer(pattern string, speed float64) (*Replayer, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("recorded files not found by pattern: " + pattern)
+	}
+	if speed < 0 {
+		return nil, errors.New("replay speed must not be negative")
+	}
+	sort.Strings(files)
+	return &Replayer{
+		files: files,
+		speed: speed,
+	}, nil
+}
+
+// Replay calls handle for every recorded frame until all files are replayed or done is closed
+func (r *Replayer) Replay(done <-chan struct{}, handle func(frame Frame)) error {
+	var prev time.Time
+	for _, name := range r.files {
+		err := r.replayFile(name, done, &prev, handle)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Replayer) replayFile(name string, done <-chan struct{}, prev *time.Time, handle func(frame Frame)) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	j := jsoniter.ConfigCompatibleWithStandardLibrary
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		var frame Frame
+		err = j.Unmarshal(scanner.Bytes(), &frame)
+		if err != nil {
+			return err
+		}
+
+		err = r.wait(done, *prev, frame.ReceivedAt)
+		if err != nil {
+			return err
+		}
+		*prev = frame.ReceivedAt
+
+		handle(frame)
+	}
+	return scanner.Err()
+}
+
+func (r *Replayer) wait(done <-chan struct{}, prev, next time.Time) error {
+	var delay time.Duration
+	if r.speed > 0 && !prev.IsZero() && next.After(prev) {
+		delay = time.Duration(float64(next.Sub(prev)) / r.speed)
+	}
+	if delay == 0 {
+		select {
+		case <-done:
+			return ErrReplayStopped
+		default:
+			return nil
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return ErrReplayStopped
+	case <-timer.C:
+		return nil
+	}
+}
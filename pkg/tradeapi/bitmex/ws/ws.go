@@ -1,13 +1,11 @@
 package ws
 
 import (
+	"context"
 	"net/url"
-	"os"
-	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/crypto"
@@ -19,6 +17,7 @@ import (
 	"github.com/tagirmukail/tccbot-backend/internal/types"
 	"github.com/tagirmukail/tccbot-backend/pkg/recws"
 	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/data"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws/record"
 )
 
 const (
@@ -32,6 +31,12 @@ const (
 type Receiver interface {
 }
 
+// Recorder records raw frames received from websocket
+type Recorder interface {
+	Record(ts time.Time, frame []byte) error
+	Close() error
+}
+
 type WS struct {
 	log *logrus.Logger
 
@@ -47,6 +52,9 @@ type WS struct {
 
 	apiKey    string
 	apiSecret string
+
+	recorder Recorder
+	replayer *record.Replayer
 }
 
 func NewWS(
@@ -96,47 +104,76 @@ func (r *WS) GetMessages() chan *data.BitmexData {
 	return r.messages
 }
 
-// Start start reads bitmex messages
-func (r *WS) Start(wgForeign *sync.WaitGroup) {
-	defer wgForeign.Done()
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
+// SetRecorder installs recorder of every raw frame read from bitmex
+func (r *WS) SetRecorder(recorder Recorder) {
+	r.recorder = recorder
+}
+
+// SetReplayer installs replayer, Start replays recorded frames instead of connecting to bitmex
+func (r *WS) SetReplayer(replayer *record.Replayer) {
+	r.replayer = replayer
+}
+
+// Start start reads bitmex messages until ctx is done.
+// Messages channel is never closed, consumers must stop by own context
+func (r *WS) Start(ctx context.Context) {
+	if r.replayer != nil {
+		r.replay(ctx)
+		return
+	}
+	if r.recorder != nil {
+		defer func() {
+			err := r.recorder.Close()
+			if err != nil {
+				r.log.Errorf("close ws recorder failed: %v", err)
+			}
+		}()
+	}
 
 	r.ws.Dial(r.connURL, nil)
 	err := r.ws.GetDialError()
 	if err != nil {
 		r.log.Fatalf("bitmex not connected, error: %v", err)
 	}
-	defer r.ws.Close()
 
 	r.log.Infof("connected to %s", r.connURL)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
-	go r.read(wg)
+	go r.read(ctx, wg)
 	wg.Add(1)
-	go r.ping(wg)
+	go r.ping(ctx, wg)
+
+	<-ctx.Done()
+	r.log.Infof("closing bitmex ws connection")
+	err = r.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	if err != nil {
+		r.log.Warnf("write close ws failed: %v", err)
+	}
+	r.ws.Shutdown()
 	wg.Wait()
-	<-done
+	r.log.Infof("bitmex ws connection closed")
 }
 
 // read reads messages from ws connection to bitmex and sends this to messages chanel
-func (r *WS) read(wg *sync.WaitGroup) {
+func (r *WS) read(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
 
 	j := jsoniter.ConfigCompatibleWithStandardLibrary
 	r.log.Infof("WS.read read trades:%v from bitmex started", r.theme)
+	defer r.log.Infof("read messages from bitmex ws stopped")
 	for {
 		mType, msg, err := r.ws.ReadMessage()
 		if err != nil {
-			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-				r.log.Infof("read messages from bitmex ws stopped")
+			if ctx.Err() != nil || r.ws.IsShutdown() {
 				return
 			}
 			r.log.Warnf("bitmex WS.read() read message from websocket error: %v", err)
-			time.Sleep(timeReadSleep)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(timeReadSleep):
+			}
 			continue
 		}
 
@@ -151,29 +188,20 @@ func (r *WS) read(wg *sync.WaitGroup) {
 			continue
 		}
 
-		if string(msg) == "pong" {
-			r.log.Infoln("bitmex pong message received")
-			continue
-		}
-
-		resp := &data.BitmexData{}
-		err = j.Unmarshal(msg, resp)
-		if err != nil {
-			r.log.Warnf("bitmex WS.read() unmarshal websocket message error: %v", err)
-			r.log.Warnf("bitmex WS.read() unmarshal websocket message error - data: %v", string(msg))
-			continue
+		if r.recorder != nil {
+			err = r.recorder.Record(time.Now(), msg)
+			if err != nil {
+				r.log.Warnf("bitmex WS.read() record websocket message error: %v", err)
+			}
 		}
 
-		err = resp.Validate()
-		if err != nil {
-			r.log.Warnf("bitmex WS.read() validate websocket message error: %v", err)
+		resp, ok := r.parse(j, msg)
+		if !ok {
 			continue
 		}
 
 		select {
-		case <-done:
-			r.log.Infof("Stopping processing messages from bitmex")
-			close(r.messages)
+		case <-ctx.Done():
 			return
 		case r.messages <- resp:
 			//r.log.Debugf("bitmex sends message data: %s", string(data))
@@ -182,6 +210,52 @@ func (r *WS) read(wg *sync.WaitGroup) {
 
 }
 
+// parse parses text frame to message, ok is false for pong and invalid frames
+func (r *WS) parse(j jsoniter.API, msg []byte) (*data.BitmexData, bool) {
+	if string(msg) == "pong" {
+		r.log.Infoln("bitmex pong message received")
+		return nil, false
+	}
+
+	resp := &data.BitmexData{}
+	err := j.Unmarshal(msg, resp)
+	if err != nil {
+		r.log.Warnf("bitmex WS.read() unmarshal websocket message error: %v", err)
+		r.log.Warnf("bitmex WS.read() unmarshal websocket message error - data: %v", string(msg))
+		return nil, false
+	}
+
+	err = resp.Validate()
+	if err != nil {
+		r.log.Warnf("bitmex WS.read() validate websocket message error: %v", err)
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// replay sends recorded frames to messages chanel the same way as read does
+func (r *WS) replay(ctx context.Context) {
+	r.log.Infof("WS.replay replay recorded bitmex messages started")
+
+	j := jsoniter.ConfigCompatibleWithStandardLibrary
+	err := r.replayer.Replay(ctx.Done(), func(frame record.Frame) {
+		resp, ok := r.parse(j, []byte(frame.Data))
+		if !ok {
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case r.messages <- resp:
+		}
+	})
+	if err != nil && err != record.ErrReplayStopped {
+		r.log.Errorf("WS.replay replay recorded bitmex messages failed: %v", err)
+	}
+	r.log.Infof("WS.replay replay recorded bitmex messages finished")
+	<-ctx.Done()
+}
+
 func (r *WS) subscribeAuthHandler() error {
 	j := jsoniter.ConfigCompatibleWithStandardLibrary
 
@@ -213,7 +287,7 @@ func (r *WS) subscribe() error {
 
 	var themes = make([]types.Theme, 0)
 	for _, theme := range r.theme {
-		if strings.Contains(string(theme), string(types.Trade)) {
+		if strings.Contains(string(theme), string(types.Trade)) || theme == types.Instrument {
 			theme = types.NewTemeWithPair(theme, r.symbol)
 		}
 		themes = append(themes, theme)
@@ -241,21 +315,15 @@ func (r *WS) subscribe() error {
 	return nil
 }
 
-func (r *WS) ping(wg *sync.WaitGroup) {
+func (r *WS) ping(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT)
 
 	tick := time.NewTicker(time.Duration(r.pingInterval) * time.Second)
 	defer tick.Stop()
 
 	for {
 		select {
-		case <-done:
-			err := r.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-			if err != nil {
-				r.log.Errorf("write close ws failed:%v", err)
-			}
+		case <-ctx.Done():
 			return
 		case <-tick.C:
 			r.log.Debug("send ping message bitmex ws")
@@ -0,0 +1,279 @@
+package tradeapi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex/ws"
+)
+
+const (
+	offlineOrdStatusNew      = "New"
+	offlineOrdStatusCanceled = "Canceled"
+)
+
+var errOffline = errors.New("request is not supported in offline mode")
+
+// Fixtures responses of the offline bitmex api, trade buckets are grouped by bin size
+type Fixtures struct {
+	Instruments  []bitmex.Instrument           `json:"instruments"`
+	Margins      []bitmex.UserMargin           `json:"margins"`
+	Positions    []bitmex.Position             `json:"positions"`
+	TradeBuckets map[string][]bitmex.TradeBuck `json:"trade_buckets"`
+}
+
+// LoadFixtures reads fixtures from json file
+func LoadFixtures(path string) (Fixtures, error) {
+	var fixtures Fixtures
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fixtures, err
+	}
+	err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, &fixtures)
+	if err != nil {
+		return fixtures, fmt.Errorf("decode fixtures %s failed: %v", path, err)
+	}
+	return fixtures, nil
+}
+
+// OfflineBitmex bitmex api without network access: instrument, balance, positions and candles are served
+// from fixtures, orders are recorded locally and are never filled
+type OfflineBitmex struct {
+	ws       *ws.WS
+	fixtures Fixtures
+	log      *logrus.Logger
+
+	mx     sync.Mutex
+	seq    int64
+	orders []bitmex.OrderCopied
+}
+
+// NewOfflineTradeAPI creates trade api with offline bitmex api, it is used for the replay of recorded websocket data
+func NewOfflineTradeAPI(fixtures Fixtures, log *logrus.Logger, ws *ws.WS) *TradeAPI {
+	return &TradeAPI{
+		bitmex: NewOfflineBitmex(fixtures, log, ws),
+	}
+}
+
+func NewOfflineBitmex(fixtures Fixtures, log *logrus.Logger, ws *ws.WS) *OfflineBitmex {
+	return &OfflineBitmex{
+		ws:       ws,
+		fixtures: fixtures,
+		log:      log,
+	}
+}
+
+// Orders returns all recorded orders in order of placing
+func (b *OfflineBitmex) Orders() []bitmex.OrderCopied {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return append([]bitmex.OrderCopied{}, b.orders...)
+}
+
+func (b *OfflineBitmex) EnableTestNet() {}
+
+func (b *OfflineBitmex) GetWS() *ws.WS {
+	return b.ws
+}
+
+func (b *OfflineBitmex) SetDefaultUserAgent(agent string) {}
+
+func (b *OfflineBitmex) SendRequest(path string, params url.Values, response interface{}) error {
+	return errOffline
+}
+
+func (b *OfflineBitmex) SendAuthenticatedRequest(verb, path string, params, response interface{}) error {
+	return errOffline
+}
+
+func (b *OfflineBitmex) GetUserMargin(currency string) (bitmex.UserMargin, error) {
+	for _, margin := range b.fixtures.Margins {
+		if margin.Currency == currency {
+			return margin, nil
+		}
+	}
+	return bitmex.UserMargin{}, fmt.Errorf("margin of %s not exist in fixtures", currency)
+}
+
+func (b *OfflineBitmex) GetAllUserMargin() ([]bitmex.UserMargin, error) {
+	return append([]bitmex.UserMargin{}, b.fixtures.Margins...), nil
+}
+
+func (b *OfflineBitmex) GetUserWalletInfo(currency string) (bitmex.WalletInfo, error) {
+	return bitmex.WalletInfo{}, errOffline
+}
+
+// GetOrders returns recorded orders of the symbol, only active orders are returned for any filter
+func (b *OfflineBitmex) GetOrders(params *bitmex.OrdersRequest) ([]bitmex.OrderCopied, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	var result []bitmex.OrderCopied
+	for _, ord := range b.orders {
+		if (params.Symbol == "" || ord.Symbol == params.Symbol) && ord.OrdStatus == offlineOrdStatusNew {
+			result = append(result, ord)
+		}
+	}
+	return result, nil
+}
+
+func (b *OfflineBitmex) CreateOrder(params *bitmex.OrderNewParams) (bitmex.OrderCopied, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.seq++
+	now := time.Now().UTC()
+	ord := bitmex.OrderCopied{
+		OrderID:      "offline-" + strconv.FormatInt(b.seq, 10),
+		ClOrdID:      params.ClientOrderID,
+		Symbol:       params.Symbol,
+		Side:         params.Side,
+		OrdType:      params.OrderType,
+		ExecInst:     params.ExecInst,
+		OrderQty:     int64(params.OrderQty),
+		LeavesQty:    int64(params.OrderQty),
+		Price:        params.Price,
+		StopPx:       params.StopPx,
+		OrdStatus:    offlineOrdStatusNew,
+		Text:         params.Text,
+		TransactTime: now.Format(time.RFC3339Nano),
+		Timestamp:    now,
+	}
+	b.orders = append(b.orders, ord)
+	b.log.Infof("offline order placed: %#v", ord)
+	return ord, nil
+}
+
+func (b *OfflineBitmex) AmendOrder(params *bitmex.OrderAmendParams) (bitmex.OrderCopied, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	i, err := b.activeOrder(params.OrderID, params.OrigClOrdID)
+	if err != nil {
+		return bitmex.OrderCopied{}, err
+	}
+	ord := &b.orders[i]
+	if params.Price != 0 {
+		ord.Price = params.Price
+	}
+	if params.OrderQty != 0 {
+		ord.OrderQty = int64(params.OrderQty)
+		ord.LeavesQty = int64(params.OrderQty) - ord.CumQty
+	}
+	if params.LeavesQuantity != 0 {
+		ord.LeavesQty = int64(params.LeavesQuantity)
+	}
+	if params.ClientOrderID != "" {
+		ord.ClOrdID = params.ClientOrderID
+	}
+	ord.Timestamp = time.Now().UTC()
+	b.log.Infof("offline order amended: %#v", *ord)
+	return *ord, nil
+}
+
+func (b *OfflineBitmex) CancelOrders(params *bitmex.OrderCancelParams) ([]bitmex.OrderCopied, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	i, err := b.activeOrder(params.OrderID, params.ClientOrderID)
+	if err != nil {
+		return nil, err
+	}
+	b.cancel(i, params.Text)
+	return []bitmex.OrderCopied{b.orders[i]}, nil
+}
+
+func (b *OfflineBitmex) CancelAllOrders(params *bitmex.OrderCancelAllParams) ([]bitmex.OrderCopied, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	var result []bitmex.OrderCopied
+	for i, ord := range b.orders {
+		if ord.OrdStatus != offlineOrdStatusNew || (params.Symbol != "" && ord.Symbol != params.Symbol) {
+			continue
+		}
+		b.cancel(i, params.Text)
+		result = append(result, b.orders[i])
+	}
+	return result, nil
+}
+
+// GetTradeBucketed returns candles of the bin size from fixtures not earlier than start time
+func (b *OfflineBitmex) GetTradeBucketed(params *bitmex.TradeGetBucketedParams) ([]bitmex.TradeBuck, error) {
+	var startTime time.Time
+	if params.StartTime != "" {
+		var err error
+		startTime, err = time.Parse(bitmex.TradeTimeFormat, params.StartTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var result []bitmex.TradeBuck
+	for _, candle := range b.fixtures.TradeBuckets[params.BinSize] {
+		if params.Symbol != "" && candle.Symbol != params.Symbol {
+			continue
+		}
+		ts, err := time.Parse(TradeBucketedTimestampLayout, candle.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		if ts.Before(startTime) {
+			continue
+		}
+		result = append(result, candle)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp < result[j].Timestamp
+	})
+	if params.Count > 0 && len(result) > int(params.Count) {
+		result = result[:params.Count]
+	}
+	return result, nil
+}
+
+func (b *OfflineBitmex) LeveragePosition(params *bitmex.PositionUpdateLeverageParams) (bitmex.Position, error) {
+	return bitmex.Position{}, errOffline
+}
+
+func (b *OfflineBitmex) GetPositions(params bitmex.PositionGetParams) ([]bitmex.Position, error) {
+	return append([]bitmex.Position{}, b.fixtures.Positions...), nil
+}
+
+func (b *OfflineBitmex) GetInstrument(params bitmex.InstrumentRequestParams) ([]bitmex.Instrument, error) {
+	var result []bitmex.Instrument
+	for _, inst := range b.fixtures.Instruments {
+		if params.Symbol == "" || inst.Symbol == params.Symbol {
+			result = append(result, inst)
+		}
+	}
+	return result, nil
+}
+
+// activeOrder returns index of the active order by order id or client order id, must be called under lock
+func (b *OfflineBitmex) activeOrder(orderID, clOrdID string) (int, error) {
+	for i, ord := range b.orders {
+		if ord.OrdStatus != offlineOrdStatusNew {
+			continue
+		}
+		if (orderID != "" && ord.OrderID == orderID) || (orderID == "" && clOrdID != "" && ord.ClOrdID == clOrdID) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("active order %s%s not exist", orderID, clOrdID)
+}
+
+// cancel cancels the order by index, must be called under lock
+func (b *OfflineBitmex) cancel(i int, text string) {
+	b.orders[i].OrdStatus = offlineOrdStatusCanceled
+	b.orders[i].LeavesQty = 0
+	if text != "" {
+		b.orders[i].Text = text
+	}
+	b.orders[i].Timestamp = time.Now().UTC()
+	b.log.Infof("offline order canceled: %s", b.orders[i].OrderID)
+}
@@ -0,0 +1,57 @@
+package tradeapi
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tagirmukail/tccbot-backend/pkg/tradeapi/bitmex"
+)
+
+func TestOfflineBitmex_orders(t *testing.T) {
+	b := NewOfflineBitmex(Fixtures{}, logrus.New(), nil)
+
+	buy, err := b.CreateOrder(&bitmex.OrderNewParams{Symbol: "XBTUSD", Side: "Buy", OrderQty: 100, Price: 9000})
+	require.NoError(t, err)
+	_, err = b.CreateOrder(&bitmex.OrderNewParams{Symbol: "XBTUSD", Side: "Sell", OrderQty: 200, Price: 9100})
+	require.NoError(t, err)
+
+	amended, err := b.AmendOrder(&bitmex.OrderAmendParams{OrderID: buy.OrderID, Price: 8990, LeavesQuantity: 50})
+	require.NoError(t, err)
+	require.Equal(t, 8990.0, amended.Price)
+	require.Equal(t, int64(50), amended.LeavesQty)
+
+	_, err = b.CancelOrders(&bitmex.OrderCancelParams{OrderID: buy.OrderID})
+	require.NoError(t, err)
+	_, err = b.AmendOrder(&bitmex.OrderAmendParams{OrderID: buy.OrderID, Price: 8980})
+	require.Error(t, err)
+
+	active, err := b.GetOrders(&bitmex.OrdersRequest{Symbol: "XBTUSD"})
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, "Sell", active[0].Side)
+
+	canceled, err := b.CancelAllOrders(&bitmex.OrderCancelAllParams{Symbol: "XBTUSD"})
+	require.NoError(t, err)
+	require.Len(t, canceled, 1)
+	require.Len(t, b.Orders(), 2)
+}
+
+func TestOfflineBitmex_GetTradeBucketed(t *testing.T) {
+	b := NewOfflineBitmex(Fixtures{
+		TradeBuckets: map[string][]bitmex.TradeBuck{
+			"5m": {
+				{Symbol: "XBTUSD", Timestamp: "2020-05-16T10:10:00.000Z", Close: 3},
+				{Symbol: "XBTUSD", Timestamp: "2020-05-16T10:00:00.000Z", Close: 1},
+				{Symbol: "XBTUSD", Timestamp: "2020-05-16T10:05:00.000Z", Close: 2},
+			},
+		},
+	}, logrus.New(), nil)
+
+	candles, err := b.GetTradeBucketed(&bitmex.TradeGetBucketedParams{
+		Symbol: "XBTUSD", BinSize: "5m", StartTime: "2020-05-16 10:05", Count: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []bitmex.TradeBuck{{Symbol: "XBTUSD", Timestamp: "2020-05-16T10:05:00.000Z", Close: 2}}, candles)
+}